@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/internal/openapi"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+)
+
+var (
+	handlersDir string
+	mainFile    string
+	output      string
+
+	Cmd = &cobra.Command{
+		Use:           "openapi",
+		Short:         "Generate the OpenAPI 3 specification from the handler annotations",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		// the specification is generated from the sources, the application isn't started
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return nil
+		},
+		PersistentPostRun: func(_ *cobra.Command, _ []string) {},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			doc, report, err := openapi.NewGenerator().Generate(handlersDir, mainFile)
+			if err != nil {
+				return err
+			}
+
+			if report.Failed() {
+				_, _ = fmt.Fprint(os.Stderr, report.String())
+				return fmt.Errorf("openapi specification isn't generated, %d routes without annotation, %d errors",
+					len(report.Missing), len(report.Errors))
+			}
+
+			if len(report.Unregistered) > 0 {
+				_, _ = fmt.Fprint(os.Stderr, report.String())
+			}
+
+			out, err := yaml.Marshal(doc)
+			if err != nil {
+				return err
+			}
+
+			return ioutil.WriteFile(output, out, 0644)
+		},
+	}
+)
+
+func init() {
+	Cmd.Flags().StringVarP(&handlersDir, "handlers", "e", "internal/handlers", "directory of the annotated handlers")
+	Cmd.Flags().StringVarP(&mainFile, "main", "m", "main.go", "file with the general API annotations")
+	Cmd.Flags().StringVarP(&output, "output", "o", "api/openapi.yaml", "output file")
+}
@@ -0,0 +1,243 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenPrefix marks the tokens issued by the registry, the token is TokenPrefix + id + "." + secret
+	TokenPrefix = "psk_"
+
+	// UserIdPrefix is used to build the synthetic user id of the requests authenticated with a key
+	UserIdPrefix = "apikey_"
+)
+
+var (
+	ErrKeyInvalid    = errors.New("api key is invalid")
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrScopesInvalid = errors.New("api key scopes are invalid")
+)
+
+// Key
+type Key struct {
+	Id         string     `json:"id"`
+	MerchantId string     `json:"merchant_id"`
+	UserId     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	SecretHash string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive
+func (k *Key) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// SyntheticUserId returns the user id put into the request context instead of the Auth1 user id
+func (k *Key) SyntheticUserId() string {
+	return UserIdPrefix + k.Id
+}
+
+// Store
+type Store interface {
+	Create(ctx context.Context, key *Key) error
+	// Get returns nil key if the id is unknown
+	Get(ctx context.Context, id string) (*Key, error)
+	List(ctx context.Context, merchantId string) ([]*Key, error)
+	// Update saves the name, the scopes and the expiration date of the key
+	Update(ctx context.Context, key *Key) error
+	// Revoke saves the revocation date of the key, the revocation can't be undone by the concurrent writes
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	// Touch saves the last used date of the key only
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// Config
+type Config struct {
+	// LastUsedInterval limits how often the last used timestamp of a key is written to the store
+	LastUsedInterval time.Duration `default:"1m"`
+}
+
+// NewStore returns the redis store, the keys must survive the restarts and be shared by the replicas
+func NewStore(client *redis.Client) (Store, error) {
+	if client == nil {
+		return nil, errors.New("api keys store requires redis settings")
+	}
+	return NewRedisStore(client), nil
+}
+
+// Registry issues, verifies and revokes merchant API keys
+type Registry struct {
+	store            Store
+	permissions      Permissions
+	lastUsedInterval time.Duration
+}
+
+// NewRegistry
+func NewRegistry(store Store, permissions Permissions, lastUsedInterval time.Duration) *Registry {
+	return &Registry{
+		store:            store,
+		permissions:      permissions,
+		lastUsedInterval: lastUsedInterval,
+	}
+}
+
+// Create returns the new key and its token, the token can't be restored later
+func (r *Registry) Create(ctx context.Context, key *Key) (*Key, string, error) {
+	if err := r.permissions.Validate(key.Scopes); err != nil {
+		return nil, "", err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key.Id = id
+	key.SecretHash = hashSecret(secret)
+	key.CreatedAt = time.Now().UTC()
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+
+	if err = r.store.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, TokenPrefix + id + "." + secret, nil
+}
+
+// List
+func (r *Registry) List(ctx context.Context, merchantId string) ([]*Key, error) {
+	return r.store.List(ctx, merchantId)
+}
+
+// Get returns ErrKeyNotFound if the key doesn't belong to the merchant
+func (r *Registry) Get(ctx context.Context, merchantId, id string) (*Key, error) {
+	key, err := r.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.MerchantId != merchantId {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// Update changes name, scopes and expiration date of the key
+func (r *Registry) Update(ctx context.Context, merchantId, id, name string, scopes []string, expiresAt *time.Time) (*Key, error) {
+	if err := r.permissions.Validate(scopes); err != nil {
+		return nil, err
+	}
+
+	key, err := r.Get(ctx, merchantId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Name = name
+	key.Scopes = scopes
+	key.ExpiresAt = expiresAt
+
+	if err = r.store.Update(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Revoke
+func (r *Registry) Revoke(ctx context.Context, merchantId, id string) (*Key, error) {
+	key, err := r.Get(ctx, merchantId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+
+		if err = r.store.Revoke(ctx, key.Id, now); err != nil {
+			return nil, err
+		}
+
+		key.RevokedAt = &now
+	}
+
+	return key, nil
+}
+
+// Authenticate returns ErrKeyInvalid for the unknown, revoked and expired keys
+func (r *Registry) Authenticate(ctx context.Context, token string) (*Key, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, ErrKeyInvalid
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(token, TokenPrefix), ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrKeyInvalid
+	}
+
+	key, err := r.store.Get(ctx, parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.SecretHash), []byte(hashSecret(parts[1]))) != 1 {
+		return nil, ErrKeyInvalid
+	}
+
+	now := time.Now().UTC()
+
+	if !key.IsActive(now) {
+		return nil, ErrKeyInvalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= r.lastUsedInterval {
+		if err = r.store.Touch(ctx, key.Id, now); err != nil {
+			return nil, err
+		}
+
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// Allowed reports whether one of the key scopes grants access to the request
+func (r *Registry) Allowed(key *Key, method, path string) bool {
+	return r.permissions.Allowed(key.Scopes, method, path)
+}
+
+// Scopes returns names of all permissions which may be granted to a key
+func (r *Registry) Scopes() []string {
+	return r.permissions.Names()
+}
+
+func hashSecret(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,131 @@
+package apikey
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+	"time"
+)
+
+// revokingStore revokes the key right after the registry has read it
+type revokingStore struct {
+	*MemoryStore
+	once sync.Once
+}
+
+func (s *revokingStore) Get(ctx context.Context, id string) (*Key, error) {
+	key, err := s.MemoryStore.Get(ctx, id)
+	s.once.Do(func() {
+		_ = s.MemoryStore.Revoke(ctx, id, time.Now().UTC())
+	})
+	return key, err
+}
+
+type RegistryTestSuite struct {
+	suite.Suite
+	permissions Permissions
+}
+
+func Test_Registry(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func (suite *RegistryTestSuite) SetupTest() {
+	suite.permissions = Permissions{}
+	suite.permissions.add("merchantListOrders", "/admin/api/v1/orders", "GET")
+}
+
+func (suite *RegistryTestSuite) TestRegistry_Authenticate_RevokedBetweenReadAndWrite() {
+	store := &revokingStore{MemoryStore: NewMemoryStore()}
+	registry := NewRegistry(store, suite.permissions, 0)
+	key, token := suite.create(registry, "ffffffffffffffffffffffff")
+
+	_, err := registry.Authenticate(context.Background(), token)
+	require.NoError(suite.T(), err)
+
+	stored, err := store.MemoryStore.Get(context.Background(), key.Id)
+	require.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), stored.RevokedAt)
+	assert.NotNil(suite.T(), stored.LastUsedAt)
+
+	_, err = registry.Authenticate(context.Background(), token)
+	assert.Equal(suite.T(), ErrKeyInvalid, err)
+}
+
+func (suite *RegistryTestSuite) TestRegistry_RevokeConcurrentlyWithAuthenticate() {
+	registry := NewRegistry(NewMemoryStore(), suite.permissions, 0)
+
+	for i := 0; i < 100; i++ {
+		key, token := suite.create(registry, "ffffffffffffffffffffffff")
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, _ = registry.Authenticate(context.Background(), token)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := registry.Revoke(context.Background(), key.MerchantId, key.Id)
+			assert.NoError(suite.T(), err)
+		}()
+		wg.Wait()
+
+		_, err := registry.Authenticate(context.Background(), token)
+		assert.Equal(suite.T(), ErrKeyInvalid, err)
+
+		stored, err := registry.Get(context.Background(), key.MerchantId, key.Id)
+		require.NoError(suite.T(), err)
+		assert.NotNil(suite.T(), stored.RevokedAt)
+	}
+}
+
+func (suite *RegistryTestSuite) TestRegistry_Update_KeepsRevocation() {
+	registry := NewRegistry(NewMemoryStore(), suite.permissions, 0)
+	key, token := suite.create(registry, "ffffffffffffffffffffffff")
+
+	stale, err := registry.Get(context.Background(), key.MerchantId, key.Id)
+	require.NoError(suite.T(), err)
+
+	_, err = registry.Revoke(context.Background(), key.MerchantId, key.Id)
+	require.NoError(suite.T(), err)
+
+	stale.Name = "renamed"
+	require.NoError(suite.T(), registry.store.Update(context.Background(), stale))
+
+	_, err = registry.Authenticate(context.Background(), token)
+	assert.Equal(suite.T(), ErrKeyInvalid, err)
+}
+
+func (suite *RegistryTestSuite) TestRegistry_Authenticate_LastUsedInterval() {
+	registry := NewRegistry(NewMemoryStore(), suite.permissions, time.Hour)
+	key, token := suite.create(registry, "ffffffffffffffffffffffff")
+
+	first, err := registry.Authenticate(context.Background(), token)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), first.LastUsedAt)
+
+	second, err := registry.Authenticate(context.Background(), token)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *first.LastUsedAt, *second.LastUsedAt)
+
+	stored, err := registry.Get(context.Background(), key.MerchantId, key.Id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *first.LastUsedAt, *stored.LastUsedAt)
+}
+
+func (suite *RegistryTestSuite) create(registry *Registry, merchantId string) (*Key, string) {
+	key, token, err := registry.Create(context.Background(), &Key{
+		MerchantId: merchantId,
+		UserId:     "ffffffffffffffffffffffff",
+		Name:       "test",
+		Scopes:     []string{"merchantListOrders"},
+	})
+	require.NoError(suite.T(), err)
+	return key, token
+}
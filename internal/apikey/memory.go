@@ -0,0 +1,104 @@
+package apikey
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the keys in the process memory, it's used by the tests
+type MemoryStore struct {
+	mx   sync.Mutex
+	keys map[string]Key
+}
+
+// NewMemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]Key)}
+}
+
+// Create
+func (s *MemoryStore) Create(_ context.Context, key *Key) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.keys[key.Id] = *key
+	return nil
+}
+
+// Get
+func (s *MemoryStore) Get(_ context.Context, id string) (*Key, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// List
+func (s *MemoryStore) List(_ context.Context, merchantId string) ([]*Key, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	keys := make([]*Key, 0)
+	for _, key := range s.keys {
+		if key.MerchantId == merchantId {
+			k := key
+			keys = append(keys, &k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Update
+func (s *MemoryStore) Update(_ context.Context, key *Key) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	stored, ok := s.keys[key.Id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	stored.Name = key.Name
+	stored.Scopes = key.Scopes
+	stored.ExpiresAt = key.ExpiresAt
+	s.keys[key.Id] = stored
+	return nil
+}
+
+// Revoke
+func (s *MemoryStore) Revoke(_ context.Context, id string, revokedAt time.Time) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	stored, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if stored.RevokedAt == nil {
+		stored.RevokedAt = &revokedAt
+		s.keys[id] = stored
+	}
+	return nil
+}
+
+// Touch
+func (s *MemoryStore) Touch(_ context.Context, id string, usedAt time.Time) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	stored, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	stored.LastUsedAt = &usedAt
+	s.keys[id] = stored
+	return nil
+}
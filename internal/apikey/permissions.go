@@ -0,0 +1,95 @@
+package apikey
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var pathParamRegex = regexp.MustCompile(`:[^/]+`)
+
+// Permission is a policy line of the casbin policy file, its name is used as the key scope
+type Permission struct {
+	Name   string
+	Path   string
+	Method string
+	re     *regexp.Regexp
+}
+
+// Permissions groups the permissions by name
+type Permissions map[string][]*Permission
+
+// LoadPermissions reads the "p,name,path,method" lines of the casbin policy file,
+// only the paths starting with the prefix and not starting with any of the excluded prefixes are kept
+func LoadPermissions(file, prefix string, excluded ...string) (Permissions, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	perms := Permissions{}
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		parts := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(parts) != 4 || parts[0] != "p" || !strings.HasPrefix(parts[2], prefix) || hasAnyPrefix(parts[2], excluded) {
+			continue
+		}
+		perms.add(parts[1], parts[2], parts[3])
+	}
+
+	return perms, scanner.Err()
+}
+
+// Validate
+func (p Permissions) Validate(scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrScopesInvalid
+	}
+	for _, scope := range scopes {
+		if _, ok := p[scope]; !ok {
+			return ErrScopesInvalid
+		}
+	}
+	return nil
+}
+
+// Allowed
+func (p Permissions) Allowed(scopes []string, method, path string) bool {
+	for _, scope := range scopes {
+		for _, perm := range p[scope] {
+			if perm.Method == method && perm.re.MatchString(path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Names
+func (p Permissions) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p Permissions) add(name, path, method string) {
+	// same semantics as casbin keyMatch2
+	re := regexp.MustCompile("^" + pathParamRegex.ReplaceAllString(regexp.QuoteMeta(path), "[^/]+") + "$")
+	p[name] = append(p[name], &Permission{Name: name, Path: path, Method: method, re: re})
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"sort"
+	"time"
+)
+
+const (
+	redisKeyPrefix      = "apikey:"
+	redisMerchantPrefix = "apikey:merchant:"
+
+	redisFieldKey        = "key"
+	redisFieldLastUsedAt = "last_used_at"
+	redisFieldRevokedAt  = "revoked_at"
+)
+
+type redisKey struct {
+	*Key
+	SecretHash string `json:"secret_hash"`
+}
+
+// RedisStore keeps every key as hash and the key ids of every merchant in a set, the key itself, its last used date
+// and its revocation date are separate fields of the hash, so the writes of one of them never overwrite the others
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Create
+func (s *RedisStore) Create(ctx context.Context, key *Key) error {
+	if err := s.save(ctx, key); err != nil {
+		return err
+	}
+	return s.client.SAdd(ctx, redisMerchantPrefix+key.MerchantId, key.Id)
+}
+
+// Get
+func (s *RedisStore) Get(ctx context.Context, id string) (*Key, error) {
+	fields, err := s.client.HGetAll(ctx, redisKeyPrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	if fields[redisFieldKey] == "" {
+		return nil, nil
+	}
+	return decode(fields)
+}
+
+// List
+func (s *RedisStore) List(ctx context.Context, merchantId string) ([]*Key, error) {
+	ids, err := s.client.SMembers(ctx, redisMerchantPrefix+merchantId)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(ids))
+	for _, id := range ids {
+		key, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Update
+func (s *RedisStore) Update(ctx context.Context, key *Key) error {
+	return s.save(ctx, key)
+}
+
+// Revoke keeps the first revocation date
+func (s *RedisStore) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	_, err := s.client.HSetNX(ctx, redisKeyPrefix+id, redisFieldRevokedAt, []byte(revokedAt.Format(time.RFC3339Nano)))
+	return err
+}
+
+// Touch
+func (s *RedisStore) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	return s.client.HSet(ctx, redisKeyPrefix+id, redisFieldLastUsedAt, []byte(usedAt.Format(time.RFC3339Nano)))
+}
+
+func (s *RedisStore) save(ctx context.Context, key *Key) error {
+	b, err := json.Marshal(&redisKey{Key: key, SecretHash: key.SecretHash})
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, redisKeyPrefix+key.Id, redisFieldKey, b)
+}
+
+// decode ignores the dates saved in the key field, the dates of the separate fields are used instead
+func decode(fields map[string]string) (*Key, error) {
+	rk := &redisKey{Key: &Key{}}
+	if err := json.Unmarshal([]byte(fields[redisFieldKey]), rk); err != nil {
+		return nil, err
+	}
+	rk.Key.SecretHash = rk.SecretHash
+
+	var err error
+	if rk.Key.LastUsedAt, err = decodeTime(fields[redisFieldLastUsedAt]); err != nil {
+		return nil, err
+	}
+	if rk.Key.RevokedAt, err = decodeTime(fields[redisFieldRevokedAt]); err != nil {
+		return nil, err
+	}
+	return rk.Key, nil
+}
+
+func decodeTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
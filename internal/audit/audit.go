@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"time"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+)
+
+// Record of the action made by the user on behalf of the merchant
+type Record struct {
+	Action     string            `json:"action"`
+	UserId     string            `json:"user_id"`
+	MerchantId string            `json:"merchant_id"`
+	ObjectId   string            `json:"object_id"`
+	Result     string            `json:"result"`
+	Details    map[string]string `json:"details,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// Store keeps the audit records of the merchants
+type Store interface {
+	Write(ctx context.Context, record *Record) error
+}
+
+// Config
+type Config struct {
+	// MaxRecords is the number of the latest records kept for each merchant
+	MaxRecords int64 `default:"10000"`
+	// Ttl is the time the records of the merchant are kept after the last record is written
+	Ttl time.Duration `default:"2160h"`
+}
+
+// NewStore returns the redis store, the records must survive the restarts and be shared by the replicas,
+// the records are written to the log if redis isn't configured
+func NewStore(cfg *Config, client *redis.Client, log logger.Logger) Store {
+	if client == nil {
+		log.Warning("audit records are written to the log, redis address isn't configured")
+		return NewLogStore(log)
+	}
+	return NewRedisStore(client, cfg)
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"context"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"time"
+)
+
+// LogStore writes the records to the structured log, it's used when redis isn't configured
+type LogStore struct {
+	log logger.Logger
+}
+
+// NewLogStore
+func NewLogStore(log logger.Logger) *LogStore {
+	return &LogStore{log: log}
+}
+
+// Write
+func (s *LogStore) Write(_ context.Context, record *Record) error {
+	s.log.Info("audit record", logger.PairArgs(
+		"action", record.Action,
+		"user_id", record.UserId,
+		"merchant_id", record.MerchantId,
+		"object_id", record.ObjectId,
+		"result", record.Result,
+		"details", record.Details,
+		"created_at", record.CreatedAt.Format(time.RFC3339Nano),
+	))
+	return nil
+}
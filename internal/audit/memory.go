@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps the records in the process memory, it's used by the tests
+type MemoryStore struct {
+	mx      sync.Mutex
+	records []Record
+}
+
+// NewMemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Write
+func (s *MemoryStore) Write(_ context.Context, record *Record) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.records = append(s.records, *record)
+	return nil
+}
+
+// Records returns the records of the merchant in the order they were written
+func (s *MemoryStore) Records(merchantId string) []Record {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var records []Record
+	for _, record := range s.records {
+		if record.MerchantId == merchantId {
+			records = append(records, record)
+		}
+	}
+	return records
+}
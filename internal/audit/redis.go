@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"time"
+)
+
+const redisKeyPrefix = "audit:"
+
+// redisWriteScript prepends the record to the list of the merchant, trims the list to the maximum number of the records
+// and prolongs its expiry
+const redisWriteScript = `
+redis.call('LPUSH', KEYS[1], ARGV[1])
+redis.call('LTRIM', KEYS[1], 0, tonumber(ARGV[2]) - 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`
+
+// RedisStore keeps the records of each merchant in the list, the latest record goes first
+type RedisStore struct {
+	client *redis.Client
+	cfg    Config
+}
+
+// NewRedisStore
+func NewRedisStore(client *redis.Client, cfg *Config) *RedisStore {
+	return &RedisStore{client: client, cfg: *cfg}
+}
+
+// Write
+func (s *RedisStore) Write(ctx context.Context, record *Record) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Eval(
+		ctx,
+		redisWriteScript,
+		[]string{redisKeyPrefix + record.MerchantId},
+		string(b),
+		s.cfg.MaxRecords,
+		int64(s.cfg.Ttl/time.Millisecond),
+	)
+	return err
+}
@@ -0,0 +1,121 @@
+package bodylimit
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrTooLarge is returned by the reader once the body exceeds the limit
+var ErrTooLarge = errors.New("request body too large")
+
+// Config limits the size of the request bodies in bytes, the route limit overrides the group limit and the group
+// limit overrides the default one, zero limit disables the check
+type Config struct {
+	Default int64 `default:"1048576"`
+	Groups  map[string]int64
+	Routes  []RouteLimit
+}
+
+// RouteLimit overrides the group limit for the route template, all methods are matched if Method is empty
+type RouteLimit struct {
+	Method string
+	Path   string
+	Limit  int64
+}
+
+// Limits resolves the limit of the request
+type Limits struct {
+	def    int64
+	groups map[string]int64
+	routes map[string]int64
+}
+
+// NewLimits creates the limits from the config, the config routes override the default routes
+func NewLimits(cfg *Config, defaults []RouteLimit) *Limits {
+	l := &Limits{
+		def:    cfg.Default,
+		groups: make(map[string]int64, len(cfg.Groups)),
+		routes: make(map[string]int64, len(defaults)+len(cfg.Routes)),
+	}
+
+	for name, limit := range cfg.Groups {
+		l.groups[strings.ToLower(name)] = limit
+	}
+
+	for _, route := range defaults {
+		l.routes[routeId(route.Method, route.Path)] = route.Limit
+	}
+
+	for _, route := range cfg.Routes {
+		l.routes[routeId(route.Method, route.Path)] = route.Limit
+	}
+
+	return l
+}
+
+// Limit returns the route limit if any, the group limit or the default one otherwise
+func (l *Limits) Limit(group, method, path string) int64 {
+	if limit, ok := l.routes[routeId(method, path)]; ok {
+		return limit
+	}
+	if limit, ok := l.routes[routeId("", path)]; ok {
+		return limit
+	}
+	if limit, ok := l.groups[group]; ok {
+		return limit
+	}
+	return l.def
+}
+
+// Reader reads the body up to the limit and remembers if the limit was exceeded, unlike http.MaxBytesReader
+// the caller can tell the exceeded limit from the other errors of the body reading
+type Reader struct {
+	rc        io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+// NewReader
+func NewReader(rc io.ReadCloser, limit int64) *Reader {
+	return &Reader{rc: rc, remaining: limit}
+}
+
+// Read
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, ErrTooLarge
+	}
+
+	// one more byte is read to tell the body of the limit size from the larger one
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.rc.Read(p)
+
+	if int64(n) <= r.remaining {
+		r.remaining -= int64(n)
+		return n, err
+	}
+
+	n = int(r.remaining)
+	r.remaining = 0
+	r.exceeded = true
+
+	return n, ErrTooLarge
+}
+
+// Close
+func (r *Reader) Close() error {
+	return r.rc.Close()
+}
+
+// Exceeded
+func (r *Reader) Exceeded() bool {
+	return r.exceeded
+}
+
+func routeId(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
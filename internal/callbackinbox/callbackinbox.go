@@ -0,0 +1,516 @@
+package callbackinbox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/globalsign/mgo/bson"
+	bolt "go.etcd.io/bbolt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusProcessed = "processed"
+	StatusRejected  = "rejected"
+	// StatusStuck is the callback which isn't processed after all attempts, it's replayed manually only
+	StatusStuck = "stuck"
+
+	OutcomeProcessed = "processed"
+	OutcomeRejected  = "rejected"
+	OutcomeRetry     = "retry"
+)
+
+var (
+	bucketCallbacks = []byte("callbacks")
+	// bucketStatuses indexes the callbacks by the status, the keys are the status and the callback id
+	bucketStatuses = []byte("statuses")
+	// bucketDue indexes the pending callbacks by the next attempt, the keys are the big-endian unix time in
+	// nanoseconds and the callback id
+	bucketDue = []byte("due")
+
+	// ErrBusy is returned if the callback is processed by the worker or the other replay
+	ErrBusy = errors.New("callback is being processed")
+
+	errEncryptionKeyEmpty = errors.New("callback inbox requires the encryption key")
+)
+
+// Config of the durable inbox of the payment system callbacks
+type Config struct {
+	// Enabled persists the callbacks before they are forwarded to the billing
+	Enabled bool `envconfig:"CALLBACK_INBOX_ENABLED"`
+	// Path of the inbox database file, the file must be on the persistent volume to keep the callbacks after
+	// the restarts
+	Path string `envconfig:"CALLBACK_INBOX_PATH" default:"callback_inbox.db"`
+	// EncryptionKey encrypts the body and the signature of the callbacks in the database file
+	EncryptionKey string `envconfig:"CALLBACK_INBOX_ENCRYPTION_KEY"`
+	// PollInterval of the worker looking for the callbacks to retry
+	PollInterval time.Duration `envconfig:"CALLBACK_INBOX_POLL_INTERVAL" default:"10s"`
+	// RetryDelay is the delay of the first retry, it's doubled on every attempt up to RetryDelayMax
+	RetryDelay    time.Duration `envconfig:"CALLBACK_INBOX_RETRY_DELAY" default:"30s"`
+	RetryDelayMax time.Duration `envconfig:"CALLBACK_INBOX_RETRY_DELAY_MAX" default:"1h"`
+	// MaxAttempts is the number of the attempts after which the callback is stuck
+	MaxAttempts int `envconfig:"CALLBACK_INBOX_MAX_ATTEMPTS" default:"20"`
+	// BatchSize is the maximum number of the callbacks retried on every poll
+	BatchSize int `envconfig:"CALLBACK_INBOX_BATCH_SIZE" default:"100"`
+	// Retention of the processed and the rejected callbacks
+	Retention time.Duration `envconfig:"CALLBACK_INBOX_RETENTION" default:"72h"`
+}
+
+// Callback received from the payment system
+type Callback struct {
+	// The unique identifier for the callback in the inbox.
+	Id string `json:"id"`
+	// The payment system of the callback.
+	Provider string `json:"provider"`
+	// The callback event of the payment system.
+	Event string `json:"event"`
+	// The billing method processing the callback. Available values: payment, refund.
+	Kind string `json:"kind"`
+	// The billing handler of the payment system.
+	Handler string `json:"handler"`
+	// The unique identifier for the order.
+	OrderId string `json:"order_id"`
+	// The unique identifier for the refund.
+	RefundId string `json:"refund_id,omitempty"`
+	// The callback signature. It's removed when the callback is processed.
+	Signature string `json:"signature"`
+	// The callback body. It's encrypted in the inbox, returned with the personal data redacted and removed when the
+	// callback is processed.
+	Body string `json:"body"`
+	// The callback status. Available values: pending, processed, rejected, stuck.
+	Status string `json:"status"`
+	// The number of the billing calls.
+	Attempts int `json:"attempts"`
+	// The status of the last billing response.
+	LastStatus int32 `json:"last_status"`
+	// The error of the last billing call.
+	LastError string `json:"last_error,omitempty"`
+	// Has a true value if the billing processed the recurring payment, its retries update the saved card and the
+	// subscription only.
+	Forwarded bool `json:"forwarded,omitempty"`
+	// The date of the callback receipt.
+	CreatedAt time.Time `json:"created_at"`
+	// The date of the last billing call.
+	UpdatedAt time.Time `json:"updated_at"`
+	// The date of the next retry of the pending callback.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// Inbox keeps the callbacks in the embedded database until the billing processes them, the database file is local
+// to the replica
+type Inbox struct {
+	cfg    *Config
+	db     *bolt.DB
+	sealer *sealer
+	mx     sync.Mutex
+	busy   map[string]bool
+	now    func() time.Time
+}
+
+// Open creates the database file if it doesn't exist
+func Open(cfg *Config) (*Inbox, error) {
+	if cfg.EncryptionKey == "" {
+		return nil, errEncryptionKeyEmpty
+	}
+
+	sealer, err := newSealer(cfg.EncryptionKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(cfg.Path, 0600, &bolt.Options{Timeout: time.Second})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(bucketCallbacks); err != nil {
+			return err
+		}
+
+		// the indexes are built for the database file created before them
+		if tx.Bucket(bucketStatuses) == nil || tx.Bucket(bucketDue) == nil {
+			return reindex(tx)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Inbox{cfg: cfg, db: db, sealer: sealer, busy: make(map[string]bool), now: time.Now}, nil
+}
+
+// Close
+func (i *Inbox) Close() error {
+	return i.db.Close()
+}
+
+// Add saves the new pending callback, it's retried after the first retry delay if the outcome of the first
+// attempt isn't recorded
+func (i *Inbox) Add(callback *Callback) error {
+	now := i.now()
+	callback.Id = bson.NewObjectId().Hex()
+	callback.Status = StatusPending
+	callback.CreatedAt = now
+	callback.UpdatedAt = now
+	callback.NextAttemptAt = now.Add(i.cfg.RetryDelay)
+
+	return i.save(callback)
+}
+
+// Get returns nil if the callback is unknown
+func (i *Inbox) Get(id string) (*Callback, error) {
+	var callback *Callback
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketCallbacks).Get([]byte(id))
+
+		if data == nil {
+			return nil
+		}
+
+		var err error
+		callback, err = i.decode(id, data)
+		return err
+	})
+
+	return callback, err
+}
+
+// List returns the page of the callbacks of the status starting from the latest one and the number of them,
+// the callbacks of all statuses are returned if the status is empty. Only the callbacks of the page are decoded
+func (i *Inbox) List(status string, offset, limit int) ([]*Callback, int, error) {
+	items, count := []*Callback{}, 0
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		callbacks := tx.Bucket(bucketCallbacks)
+		var ids [][]byte
+
+		// the ids are the object ids, so the order of the keys is the order of the receipt
+		if status == "" {
+			cursor := callbacks.Cursor()
+
+			for key, _ := cursor.Last(); key != nil; key, _ = cursor.Prev() {
+				if count >= offset && len(ids) < limit {
+					ids = append(ids, key)
+				}
+
+				count++
+			}
+		} else {
+			prefix := statusPrefix(status)
+			cursor := tx.Bucket(bucketStatuses).Cursor()
+			key, _ := cursor.Seek(append(statusPrefix(status), 0xff))
+
+			if key == nil {
+				key, _ = cursor.Last()
+			} else {
+				key, _ = cursor.Prev()
+			}
+
+			for ; key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Prev() {
+				if count >= offset && len(ids) < limit {
+					ids = append(ids, key[len(prefix):])
+				}
+
+				count++
+			}
+		}
+
+		for _, id := range ids {
+			callback, err := i.decode(string(id), callbacks.Get(id))
+
+			if err != nil {
+				return err
+			}
+
+			items = append(items, callback)
+		}
+
+		return nil
+	})
+
+	return items, count, err
+}
+
+// Due returns the pending callbacks to retry in the order of the next attempt
+func (i *Inbox) Due(limit int) ([]*Callback, error) {
+	var items []*Callback
+	now := dueKey(i.now(), "")
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		callbacks := tx.Bucket(bucketCallbacks)
+		cursor := tx.Bucket(bucketDue).Cursor()
+
+		for key, _ := cursor.First(); key != nil && len(items) < limit; key, _ = cursor.Next() {
+			if bytes.Compare(key[:len(now)], now) > 0 {
+				break
+			}
+
+			id := key[len(now):]
+			callback, err := i.decode(string(id), callbacks.Get(id))
+
+			if err != nil {
+				return err
+			}
+
+			items = append(items, callback)
+		}
+
+		return nil
+	})
+
+	return items, err
+}
+
+// Record saves the outcome of the billing call, the callback to retry is stuck after the last attempt, the body and
+// the signature of the processed callback aren't kept because it isn't replayed
+func (i *Inbox) Record(callback *Callback, outcome string, status int32, message string) error {
+	now := i.now()
+	callback.Attempts++
+	callback.LastStatus = status
+	callback.LastError = message
+	callback.UpdatedAt = now
+
+	switch outcome {
+	case OutcomeProcessed:
+		callback.Status = StatusProcessed
+		callback.Body = ""
+		callback.Signature = ""
+	case OutcomeRejected:
+		callback.Status = StatusRejected
+	default:
+		callback.Status = StatusPending
+		callback.NextAttemptAt = now.Add(i.delay(callback.Attempts))
+
+		if callback.Attempts >= i.cfg.MaxAttempts {
+			callback.Status = StatusStuck
+		}
+	}
+
+	return i.save(callback)
+}
+
+// Purge deletes the processed and the rejected callbacks older than the retention period
+func (i *Inbox) Purge() (int, error) {
+	count := 0
+	before := i.now().Add(-i.cfg.Retention)
+
+	err := i.db.Update(func(tx *bolt.Tx) error {
+		callbacks := tx.Bucket(bucketCallbacks)
+		statuses := tx.Bucket(bucketStatuses)
+
+		for _, status := range []string{StatusProcessed, StatusRejected} {
+			var keys [][]byte
+			prefix := statusPrefix(status)
+			cursor := statuses.Cursor()
+
+			for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+				callback := &Callback{}
+
+				if err := json.Unmarshal(callbacks.Get(key[len(prefix):]), callback); err != nil {
+					return err
+				}
+
+				if callback.UpdatedAt.Before(before) {
+					keys = append(keys, append([]byte(nil), key...))
+				}
+			}
+
+			// the bucket mustn't be changed while it's iterated
+			for _, key := range keys {
+				if err := statuses.Delete(key); err != nil {
+					return err
+				}
+
+				if err := callbacks.Delete(key[len(prefix):]); err != nil {
+					return err
+				}
+			}
+
+			count += len(keys)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Lock marks the callback as being processed, it returns false if it's already processed
+func (i *Inbox) Lock(id string) bool {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if i.busy[id] {
+		return false
+	}
+
+	i.busy[id] = true
+	return true
+}
+
+// Unlock
+func (i *Inbox) Unlock(id string) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	delete(i.busy, id)
+}
+
+// save encrypts the body and the signature of the callback, the callback itself keeps them in plain text
+func (i *Inbox) save(callback *Callback) error {
+	stored := *callback
+	var err error
+
+	if stored.Body, err = i.sealer.seal(callback.Body, callback.Id); err != nil {
+		return err
+	}
+
+	if stored.Signature, err = i.sealer.seal(callback.Signature, callback.Id); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(&stored)
+
+	if err != nil {
+		return err
+	}
+
+	return i.db.Update(func(tx *bolt.Tx) error {
+		callbacks := tx.Bucket(bucketCallbacks)
+		id := []byte(callback.Id)
+
+		// the index keys of the previous state are replaced
+		if old := callbacks.Get(id); old != nil {
+			previous := &Callback{}
+
+			if err := json.Unmarshal(old, previous); err != nil {
+				return err
+			}
+
+			if err := unindex(tx, previous); err != nil {
+				return err
+			}
+		}
+
+		if err := callbacks.Put(id, data); err != nil {
+			return err
+		}
+
+		return index(tx, callback)
+	})
+}
+
+// decode decrypts the body and the signature of the callback saved under the id, the ciphertext saved under the other
+// id isn't decrypted
+func (i *Inbox) decode(id string, data []byte) (*Callback, error) {
+	callback := &Callback{}
+
+	if err := json.Unmarshal(data, callback); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	if callback.Body, err = i.sealer.open(callback.Body, id); err != nil {
+		return nil, err
+	}
+
+	if callback.Signature, err = i.sealer.open(callback.Signature, id); err != nil {
+		return nil, err
+	}
+
+	return callback, nil
+}
+
+// delay of the retry after the attempt, the attempts are counted from 1
+func (i *Inbox) delay(attempts int) time.Duration {
+	delay := i.cfg.RetryDelay
+
+	for n := 1; n < attempts && delay < i.cfg.RetryDelayMax; n++ {
+		delay *= 2
+	}
+
+	if delay > i.cfg.RetryDelayMax {
+		delay = i.cfg.RetryDelayMax
+	}
+
+	return delay
+}
+
+func index(tx *bolt.Tx, callback *Callback) error {
+	if err := tx.Bucket(bucketStatuses).Put(statusKey(callback.Status, callback.Id), nil); err != nil {
+		return err
+	}
+
+	if callback.Status != StatusPending {
+		return nil
+	}
+
+	return tx.Bucket(bucketDue).Put(dueKey(callback.NextAttemptAt, callback.Id), nil)
+}
+
+func unindex(tx *bolt.Tx, callback *Callback) error {
+	if err := tx.Bucket(bucketStatuses).Delete(statusKey(callback.Status, callback.Id)); err != nil {
+		return err
+	}
+
+	return tx.Bucket(bucketDue).Delete(dueKey(callback.NextAttemptAt, callback.Id))
+}
+
+// reindex creates the indexes of the saved callbacks again
+func reindex(tx *bolt.Tx) error {
+	for _, name := range [][]byte{bucketStatuses, bucketDue} {
+		if tx.Bucket(name) != nil {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+
+	return tx.Bucket(bucketCallbacks).ForEach(func(key, data []byte) error {
+		callback := &Callback{}
+
+		if err := json.Unmarshal(data, callback); err != nil {
+			return err
+		}
+
+		callback.Id = string(key)
+
+		return index(tx, callback)
+	})
+}
+
+// statusPrefix is terminated by the zero byte, so the prefix of the status isn't the prefix of the other status
+func statusPrefix(status string) []byte {
+	return append([]byte(status), 0)
+}
+
+func statusKey(status, id string) []byte {
+	return append(statusPrefix(status), id...)
+}
+
+// dueKey keeps the order of the next attempts for the dates after the unix epoch
+func dueKey(at time.Time, id string) []byte {
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+	return append(key, id...)
+}
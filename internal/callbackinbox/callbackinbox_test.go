@@ -0,0 +1,314 @@
+package callbackinbox
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	bolt "go.etcd.io/bbolt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+const callbackBody = `{"payment_data":{"id":"1244117"},"card_account":{"masked_pan":"400000...0002","holder":"TEST HOLDER"},"customer":{"email":"customer@unit.test","ip":"127.0.0.1"}}`
+
+type InboxTestSuite struct {
+	suite.Suite
+	dir   string
+	cfg   *Config
+	inbox *Inbox
+}
+
+func Test_Inbox(t *testing.T) {
+	suite.Run(t, new(InboxTestSuite))
+}
+
+func (suite *InboxTestSuite) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "callback_inbox")
+	require.NoError(suite.T(), err)
+
+	suite.cfg = &Config{
+		Path:          suite.dir + "/inbox.db",
+		EncryptionKey: "secret",
+		RetryDelay:    time.Minute,
+		RetryDelayMax: time.Hour,
+		MaxAttempts:   3,
+		Retention:     time.Hour,
+	}
+	suite.inbox, err = Open(suite.cfg)
+	require.NoError(suite.T(), err)
+}
+
+func (suite *InboxTestSuite) TearDownTest() {
+	_ = suite.inbox.Close()
+	_ = os.RemoveAll(suite.dir)
+}
+
+func (suite *InboxTestSuite) TestOpen_Error_EncryptionKeyEmpty() {
+	_, err := Open(&Config{Path: suite.dir + "/other.db"})
+	assert.Equal(suite.T(), errEncryptionKeyEmpty, err)
+}
+
+func (suite *InboxTestSuite) TestAdd_BodyEncrypted() {
+	callback := suite.add()
+
+	data := suite.stored(callback.Id)
+	assert.NotContains(suite.T(), string(data), "customer@unit.test")
+	assert.NotContains(suite.T(), string(data), "TEST HOLDER")
+	assert.NotContains(suite.T(), string(data), "5f0e3c9b1a7d")
+
+	saved, err := suite.inbox.Get(callback.Id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), callbackBody, saved.Body)
+	assert.Equal(suite.T(), "5f0e3c9b1a7d", saved.Signature)
+
+	due, err := suite.inbox.Due(10)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), due)
+
+	items, count, err := suite.inbox.List("", 0, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+	assert.Equal(suite.T(), callbackBody, items[0].Body)
+}
+
+func (suite *InboxTestSuite) TestRecord_Processed_BodyRemoved() {
+	callback := suite.add()
+	require.NoError(suite.T(), suite.inbox.Record(callback, OutcomeProcessed, 200, ""))
+
+	saved, err := suite.inbox.Get(callback.Id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), StatusProcessed, saved.Status)
+	assert.Empty(suite.T(), saved.Body)
+	assert.Empty(suite.T(), saved.Signature)
+}
+
+func (suite *InboxTestSuite) TestGet_Error_OtherKey() {
+	callback := suite.add()
+	require.NoError(suite.T(), suite.inbox.Close())
+
+	suite.cfg.EncryptionKey = "other"
+	var err error
+	suite.inbox, err = Open(suite.cfg)
+	require.NoError(suite.T(), err)
+
+	_, err = suite.inbox.Get(callback.Id)
+	assert.Equal(suite.T(), errCiphertextInvalid, err)
+}
+
+func (suite *InboxTestSuite) TestGet_Error_CiphertextOfOtherCallback() {
+	first := suite.add()
+	second := suite.add()
+
+	// the body of the first callback is moved to the second one
+	err := suite.inbox.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketCallbacks).Put([]byte(second.Id), suite.stored(first.Id))
+	})
+	require.NoError(suite.T(), err)
+
+	_, err = suite.inbox.Get(second.Id)
+	assert.Equal(suite.T(), errCiphertextInvalid, err)
+}
+
+func (suite *InboxTestSuite) TestList_Status() {
+	now := time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+	suite.inbox.now = func() time.Time {
+		return now
+	}
+
+	var callbacks []*Callback
+
+	for n := 0; n < 5; n++ {
+		callbacks = append(callbacks, suite.add())
+	}
+
+	require.NoError(suite.T(), suite.inbox.Record(callbacks[1], OutcomeProcessed, 200, ""))
+	require.NoError(suite.T(), suite.inbox.Record(callbacks[3], OutcomeProcessed, 200, ""))
+	require.NoError(suite.T(), suite.inbox.Record(callbacks[4], OutcomeRejected, 400, "invalid signature"))
+
+	cases := []struct {
+		name     string
+		status   string
+		offset   int
+		limit    int
+		expected []*Callback
+		count    int
+	}{
+		{name: "all", limit: 10, expected: []*Callback{callbacks[4], callbacks[3], callbacks[2], callbacks[1], callbacks[0]}, count: 5},
+		{name: "all paged", offset: 1, limit: 2, expected: []*Callback{callbacks[3], callbacks[2]}, count: 5},
+		{name: "pending", status: StatusPending, limit: 10, expected: []*Callback{callbacks[2], callbacks[0]}, count: 2},
+		{name: "processed", status: StatusProcessed, limit: 10, expected: []*Callback{callbacks[3], callbacks[1]}, count: 2},
+		{name: "processed paged", status: StatusProcessed, offset: 1, limit: 1, expected: []*Callback{callbacks[1]}, count: 2},
+		{name: "rejected", status: StatusRejected, limit: 10, expected: []*Callback{callbacks[4]}, count: 1},
+		{name: "stuck", status: StatusStuck, limit: 10, expected: []*Callback{}, count: 0},
+		{name: "after the last", status: StatusPending, offset: 2, limit: 10, expected: []*Callback{}, count: 2},
+	}
+
+	for _, c := range cases {
+		items, count, err := suite.inbox.List(c.status, c.offset, c.limit)
+		require.NoError(suite.T(), err, c.name)
+		assert.Equal(suite.T(), c.count, count, c.name)
+		require.Len(suite.T(), items, len(c.expected), c.name)
+
+		for n, expected := range c.expected {
+			assert.Equal(suite.T(), expected.Id, items[n].Id, c.name)
+			assert.Equal(suite.T(), expected.Status, items[n].Status, c.name)
+		}
+	}
+}
+
+func (suite *InboxTestSuite) TestDue() {
+	now := time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+	suite.inbox.now = func() time.Time {
+		return now
+	}
+
+	start := now
+	first := suite.add()
+	second := suite.add()
+	processed := suite.add()
+	stuck := suite.add()
+	require.NoError(suite.T(), suite.inbox.Record(processed, OutcomeProcessed, 200, ""))
+
+	for n := 0; n < suite.cfg.MaxAttempts; n++ {
+		require.NoError(suite.T(), suite.inbox.Record(stuck, OutcomeRetry, 500, "internal error"))
+	}
+
+	now = start.Add(time.Second)
+	third := suite.add()
+
+	// the attempt delays the next one of the first callback after the others
+	now = start.Add(2 * time.Second)
+	require.NoError(suite.T(), suite.inbox.Record(first, OutcomeRetry, 500, "internal error"))
+
+	cases := []struct {
+		name     string
+		at       time.Duration
+		limit    int
+		expected []*Callback
+	}{
+		{name: "not due yet", at: time.Minute - time.Second, limit: 10},
+		{name: "due", at: time.Minute, limit: 10, expected: []*Callback{second}},
+		{name: "due later", at: time.Minute + time.Second, limit: 10, expected: []*Callback{second, third}},
+		{name: "due after the retry", at: time.Minute + 2*time.Second, limit: 10, expected: []*Callback{second, third, first}},
+		{name: "limited", at: time.Hour, limit: 2, expected: []*Callback{second, third}},
+	}
+
+	for _, c := range cases {
+		now = start.Add(c.at)
+		items, err := suite.inbox.Due(c.limit)
+		require.NoError(suite.T(), err, c.name)
+		require.Len(suite.T(), items, len(c.expected), c.name)
+
+		for n, expected := range c.expected {
+			assert.Equal(suite.T(), expected.Id, items[n].Id, c.name)
+			assert.Equal(suite.T(), callbackBody, items[n].Body, c.name)
+		}
+	}
+}
+
+func (suite *InboxTestSuite) TestPurge() {
+	now := time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+	suite.inbox.now = func() time.Time {
+		return now
+	}
+
+	pending := suite.add()
+	processed := suite.add()
+	rejected := suite.add()
+	require.NoError(suite.T(), suite.inbox.Record(processed, OutcomeProcessed, 200, ""))
+	require.NoError(suite.T(), suite.inbox.Record(rejected, OutcomeRejected, 400, ""))
+
+	now = now.Add(suite.cfg.Retention)
+	recent := suite.add()
+	require.NoError(suite.T(), suite.inbox.Record(recent, OutcomeProcessed, 200, ""))
+
+	now = now.Add(time.Second)
+	count, err := suite.inbox.Purge()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+
+	for _, callback := range []*Callback{processed, rejected} {
+		saved, err := suite.inbox.Get(callback.Id)
+		require.NoError(suite.T(), err)
+		assert.Nil(suite.T(), saved)
+	}
+
+	items, count, err := suite.inbox.List("", 0, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, count)
+	assert.Equal(suite.T(), recent.Id, items[0].Id)
+	assert.Equal(suite.T(), pending.Id, items[1].Id)
+
+	_, count, err = suite.inbox.List(StatusProcessed, 0, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+
+	_, count, err = suite.inbox.List(StatusRejected, 0, 10)
+	require.NoError(suite.T(), err)
+	assert.Zero(suite.T(), count)
+}
+
+func (suite *InboxTestSuite) TestOpen_Reindex() {
+	now := time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+	suite.inbox.now = func() time.Time {
+		return now
+	}
+
+	pending := suite.add()
+	processed := suite.add()
+	require.NoError(suite.T(), suite.inbox.Record(processed, OutcomeProcessed, 200, ""))
+
+	// the database file created before the indexes
+	err := suite.inbox.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketStatuses); err != nil {
+			return err
+		}
+		return tx.DeleteBucket(bucketDue)
+	})
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), suite.inbox.Close())
+
+	suite.inbox, err = Open(suite.cfg)
+	require.NoError(suite.T(), err)
+	suite.inbox.now = func() time.Time {
+		return now.Add(suite.cfg.RetryDelay)
+	}
+
+	items, count, err := suite.inbox.List(StatusProcessed, 0, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+	assert.Equal(suite.T(), processed.Id, items[0].Id)
+
+	due, err := suite.inbox.Due(10)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), due, 1)
+	assert.Equal(suite.T(), pending.Id, due[0].Id)
+}
+
+func (suite *InboxTestSuite) add() *Callback {
+	callback := &Callback{
+		Provider:  "cardpay",
+		Event:     "payment",
+		OrderId:   "1244117",
+		Signature: "5f0e3c9b1a7d",
+		Body:      callbackBody,
+	}
+	require.NoError(suite.T(), suite.inbox.Add(callback))
+	return callback
+}
+
+// stored returns the callback as it's saved in the database file
+func (suite *InboxTestSuite) stored(id string) []byte {
+	var data []byte
+
+	err := suite.inbox.db.View(func(tx *bolt.Tx) error {
+		data = append([]byte(nil), tx.Bucket(bucketCallbacks).Get([]byte(id))...)
+		return nil
+	})
+	require.NoError(suite.T(), err)
+
+	return data
+}
@@ -0,0 +1,74 @@
+package callbackinbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+var errCiphertextInvalid = errors.New("callback inbox ciphertext is invalid")
+
+// sealer encrypts the body and the signature of the callbacks with AES-GCM, the key is the SHA-256 of the
+// configured secret
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(secret string) (*sealer, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealer{aead: aead}, nil
+}
+
+// seal returns the base64 of the nonce followed by the ciphertext, the empty value isn't encrypted
+func (s *sealer) seal(plaintext, id string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	// the callback id is authenticated to prevent moving the ciphertext to the other callback
+	out := s.aead.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (s *sealer) open(ciphertext, id string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return "", errCiphertextInvalid
+	}
+
+	nonce, data := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, data, []byte(id))
+
+	if err != nil {
+		return "", errCiphertextInvalid
+	}
+
+	return string(plaintext), nil
+}
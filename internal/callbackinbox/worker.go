@@ -0,0 +1,79 @@
+package callbackinbox
+
+import (
+	"context"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"time"
+)
+
+// Processor forwards the callback to the billing and returns the outcome of the call, the billing response status
+// and the error message
+type Processor func(ctx context.Context, callback *Callback) (outcome string, status int32, message string)
+
+// Process forwards the saved callback and records the outcome, ErrBusy is returned if the callback is being
+// processed or it's changed since it was read
+func (i *Inbox) Process(ctx context.Context, callback *Callback, process Processor) error {
+	if !i.Lock(callback.Id) {
+		return ErrBusy
+	}
+	defer i.Unlock(callback.Id)
+
+	current, err := i.Get(callback.Id)
+
+	if err != nil {
+		return err
+	}
+
+	if current == nil || !current.UpdatedAt.Equal(callback.UpdatedAt) {
+		return ErrBusy
+	}
+
+	outcome, status, message := process(ctx, callback)
+
+	return i.Record(callback, outcome, status, message)
+}
+
+// Run retries the pending callbacks with the backoff and purges the old ones until the context is canceled
+func (i *Inbox) Run(ctx context.Context, process Processor, log logger.Logger) {
+	ticker := time.NewTicker(i.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		callbacks, err := i.Due(i.cfg.BatchSize)
+
+		if err != nil {
+			log.Error("callback inbox read failed", logger.PairArgs("err", err.Error()))
+			continue
+		}
+
+		for _, callback := range callbacks {
+			if ctx.Err() != nil {
+				return
+			}
+
+			err = i.Process(ctx, callback, process)
+
+			if err != nil && err != ErrBusy {
+				log.Error("callback inbox write failed", logger.PairArgs("err", err.Error(), "callback_id", callback.Id))
+				continue
+			}
+
+			if callback.Status == StatusStuck {
+				log.Error(
+					"callback is stuck",
+					logger.PairArgs("callback_id", callback.Id, "provider", callback.Provider, "order_id", callback.OrderId),
+				)
+			}
+		}
+
+		if _, err = i.Purge(); err != nil {
+			log.Error("callback inbox purge failed", logger.PairArgs("err", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package callbackverify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/internal/idempotency"
+	"hash"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// AlgorithmSha512 is the hex encoded sha512 of the body followed by the secret, it's used by CardPay
+	AlgorithmSha512     = "sha512"
+	AlgorithmHmacSha256 = "hmac-sha256"
+	AlgorithmHmacSha512 = "hmac-sha512"
+
+	ReasonIpNotAllowed     = "ip_not_allowed"
+	ReasonSignatureMissing = "signature_missing"
+	ReasonSignatureInvalid = "signature_invalid"
+	ReasonReplay           = "replay"
+	// ReasonInProgress is the duplicate of the callback which is still processed, it must be retried later
+	ReasonInProgress = "in_progress"
+
+	replayKeyPrefix = "callback:"
+)
+
+// Config of the verification of the payment system callbacks
+type Config struct {
+	Enabled bool
+	// TrustProxyHeaders allows the client IP of the X-Forwarded-For and X-Real-IP headers, it must be enabled only
+	// behind the proxy which overwrites them
+	TrustProxyHeaders bool
+	// ReplayWindow is the period the processed callbacks are remembered for, the replays aren't checked if it's zero
+	ReplayWindow time.Duration `default:"24h"`
+	// LockTtl is the period the callback is reserved for while it's processed, the duplicates received meanwhile
+	// are rejected to be retried, the reservation of the crashed process expires after it
+	LockTtl   time.Duration `default:"1m"`
+	Providers map[string]Provider
+}
+
+// Provider settings of the callbacks of the payment system keyed by the provider segment of the callback route,
+// the checks without the settings are skipped
+type Provider struct {
+	// AllowedIps are the addresses or the CIDR networks of the payment system
+	AllowedIps      []string
+	SignatureHeader string
+	Algorithm       string
+	// Secrets are the callback secrets keyed by the project, the callback is accepted if it's signed by any of them
+	// because the project of the callback is known to the billing only
+	Secrets map[string]string
+	// IdFields are the dot separated paths of the callback identifier in the JSON body, the first found one is used
+	IdFields []string
+}
+
+// Result of the verification
+type Result struct {
+	Provider string
+	// CallbackId is empty if the body has none of the identifier fields
+	CallbackId string
+	// Project is the key of the secret the callback is signed with
+	Project string
+	// Reason of the rejection, it's empty for the accepted callback
+	Reason string
+	// ReplayKey of the accepted callback must be completed when the callback is processed or released if it isn't
+	// to accept its retry
+	ReplayKey string
+}
+
+type provider struct {
+	Provider
+	name     string
+	networks []*net.IPNet
+}
+
+// Verifier checks the payment system callbacks
+type Verifier struct {
+	cfg       *Config
+	store     idempotency.Store
+	providers map[string]*provider
+}
+
+// New creates the verifier, the defaults are used for the settings missed in the config of the same provider
+func New(cfg *Config, store idempotency.Store, defaults map[string]Provider) (*Verifier, error) {
+	v := &Verifier{cfg: cfg, store: store, providers: make(map[string]*provider)}
+
+	if !cfg.Enabled {
+		return v, nil
+	}
+
+	providers := make(map[string]Provider, len(cfg.Providers)+len(defaults))
+
+	for name, settings := range defaults {
+		providers[name] = settings
+	}
+
+	for name, settings := range cfg.Providers {
+		providers[name] = merge(settings, defaults[name])
+	}
+
+	for name, settings := range providers {
+		p := &provider{Provider: settings, name: name}
+
+		switch p.Algorithm {
+		case "":
+			p.Algorithm = AlgorithmSha512
+		case AlgorithmSha512, AlgorithmHmacSha256, AlgorithmHmacSha512:
+		default:
+			return nil, fmt.Errorf("unknown signature algorithm %q of the %s callbacks", p.Algorithm, name)
+		}
+
+		for _, value := range p.AllowedIps {
+			network, err := parseNetwork(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed ip %q of the %s callbacks: %v", value, name, err)
+			}
+
+			p.networks = append(p.networks, network)
+		}
+
+		v.providers[name] = p
+	}
+
+	return v, nil
+}
+
+// Enabled
+func (v *Verifier) Enabled() bool {
+	return v.cfg.Enabled && len(v.providers) > 0
+}
+
+// TrustProxyHeaders
+func (v *Verifier) TrustProxyHeaders() bool {
+	return v.cfg.TrustProxyHeaders
+}
+
+// Verify checks the callback of the payment system, the result is nil if the provider has no settings
+func (v *Verifier) Verify(ctx context.Context, name, ip string, header http.Header, body []byte) (*Result, error) {
+	p, ok := v.providers[name]
+
+	if !ok || !v.cfg.Enabled {
+		return nil, nil
+	}
+
+	res := &Result{Provider: p.name, CallbackId: p.callbackId(body)}
+
+	if !p.allowed(ip) {
+		res.Reason = ReasonIpNotAllowed
+		return res, nil
+	}
+
+	if len(p.Secrets) > 0 {
+		signature := strings.ToLower(strings.TrimSpace(header.Get(p.SignatureHeader)))
+
+		if signature == "" {
+			res.Reason = ReasonSignatureMissing
+			return res, nil
+		}
+
+		if res.Project, ok = p.signedBy(signature, body); !ok {
+			res.Reason = ReasonSignatureInvalid
+			return res, nil
+		}
+	}
+
+	if v.cfg.ReplayWindow <= 0 {
+		return res, nil
+	}
+
+	bodyHash := sha256.Sum256(body)
+	key := replayKeyPrefix + p.name + ":" + res.CallbackId + ":" + hex.EncodeToString(bodyHash[:])
+	reserved, err := v.store.Reserve(ctx, key, &idempotency.Record{}, v.cfg.LockTtl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved {
+		res.ReplayKey = key
+		return res, nil
+	}
+
+	record, err := v.store.Get(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the reservation expired meanwhile is treated as in progress too, the retry reserves the callback again
+	if record != nil && record.Completed {
+		res.Reason = ReasonReplay
+	} else {
+		res.Reason = ReasonInProgress
+	}
+
+	return res, nil
+}
+
+// Complete remembers the processed callback for the replay window
+func (v *Verifier) Complete(ctx context.Context, key string) error {
+	return v.store.Save(ctx, key, &idempotency.Record{Completed: true}, v.cfg.ReplayWindow)
+}
+
+// Release forgets the accepted callback
+func (v *Verifier) Release(ctx context.Context, key string) error {
+	return v.store.Delete(ctx, key)
+}
+
+func (p *provider) allowed(ip string) bool {
+	if len(p.networks) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+
+	if addr == nil {
+		return false
+	}
+
+	for _, network := range p.networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signedBy returns the project of the secret matching the signature, all secrets are checked to keep the time constant
+func (p *provider) signedBy(signature string, body []byte) (string, bool) {
+	project, found := "", false
+
+	for key, secret := range p.Secrets {
+		var h hash.Hash
+
+		switch p.Algorithm {
+		case AlgorithmHmacSha256:
+			h = hmac.New(sha256.New, []byte(secret))
+		case AlgorithmHmacSha512:
+			h = hmac.New(sha512.New, []byte(secret))
+		default:
+			h = sha512.New()
+		}
+
+		h.Write(body)
+
+		if p.Algorithm == AlgorithmSha512 {
+			h.Write([]byte(secret))
+		}
+
+		expected := hex.EncodeToString(h.Sum(nil))
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) == 1 && !found {
+			project, found = key, true
+		}
+	}
+
+	return project, found
+}
+
+func (p *provider) callbackId(body []byte) string {
+	if len(p.IdFields) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&data); err != nil {
+		return ""
+	}
+
+	for _, field := range p.IdFields {
+		value := data
+
+		for _, name := range strings.Split(field, ".") {
+			object, ok := value.(map[string]interface{})
+
+			if !ok {
+				value = nil
+				break
+			}
+
+			value = object[name]
+		}
+
+		switch id := value.(type) {
+		case string:
+			if id != "" {
+				return id
+			}
+		case json.Number:
+			return id.String()
+		}
+	}
+
+	return ""
+}
+
+func merge(settings, defaults Provider) Provider {
+	if len(settings.AllowedIps) == 0 {
+		settings.AllowedIps = defaults.AllowedIps
+	}
+	if settings.SignatureHeader == "" {
+		settings.SignatureHeader = defaults.SignatureHeader
+	}
+	if settings.Algorithm == "" {
+		settings.Algorithm = defaults.Algorithm
+	}
+	if len(settings.Secrets) == 0 {
+		settings.Secrets = defaults.Secrets
+	}
+	if len(settings.IdFields) == 0 {
+		settings.IdFields = defaults.IdFields
+	}
+	return settings
+}
+
+func parseNetwork(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		return network, err
+	}
+
+	ip := net.ParseIP(value)
+
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address")
+	}
+
+	bits := 8 * net.IPv4len
+
+	if ip.To4() == nil {
+		bits = 8 * net.IPv6len
+	} else {
+		ip = ip.To4()
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -0,0 +1,342 @@
+package callbackverify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"github.com/paysuper/paysuper-management-api/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	callbackBody    = `{"payment_data":{"id":"3549175","status":"COMPLETED"},"merchant_order":{"id":"5e95b18d455b51545379c11a"}}`
+	signatureHeader = "Signature"
+)
+
+func sign(algorithm, secret string, body []byte) string {
+	switch algorithm {
+	case AlgorithmHmacSha256:
+		h := hmac.New(sha256.New, []byte(secret))
+		h.Write(body)
+		return hex.EncodeToString(h.Sum(nil))
+	case AlgorithmHmacSha512:
+		h := hmac.New(sha512.New, []byte(secret))
+		h.Write(body)
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	h := sha512.New()
+	h.Write(body)
+	h.Write([]byte(secret))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func Test_provider_signedBy(t *testing.T) {
+	body := []byte(callbackBody)
+	secrets := map[string]string{"project_1": "secret_1", "project_2": "secret_2"}
+
+	cases := []struct {
+		name      string
+		algorithm string
+		signature string
+		project   string
+		ok        bool
+	}{
+		{name: "sha512", algorithm: AlgorithmSha512, signature: sign(AlgorithmSha512, "secret_1", body), project: "project_1", ok: true},
+		{name: "hmac-sha256", algorithm: AlgorithmHmacSha256, signature: sign(AlgorithmHmacSha256, "secret_2", body), project: "project_2", ok: true},
+		{name: "hmac-sha512", algorithm: AlgorithmHmacSha512, signature: sign(AlgorithmHmacSha512, "secret_1", body), project: "project_1", ok: true},
+		{name: "second secret", algorithm: AlgorithmSha512, signature: sign(AlgorithmSha512, "secret_2", body), project: "project_2", ok: true},
+		{name: "unknown secret", algorithm: AlgorithmSha512, signature: sign(AlgorithmSha512, "secret_3", body)},
+		{name: "other algorithm", algorithm: AlgorithmHmacSha512, signature: sign(AlgorithmSha512, "secret_1", body)},
+		{name: "other body", algorithm: AlgorithmHmacSha256, signature: sign(AlgorithmHmacSha256, "secret_1", []byte("{}"))},
+		{name: "empty signature", algorithm: AlgorithmSha512},
+	}
+
+	for _, c := range cases {
+		p := &provider{Provider: Provider{Algorithm: c.algorithm, Secrets: secrets}}
+		project, ok := p.signedBy(c.signature, body)
+
+		assert.Equal(t, c.ok, ok, c.name)
+		assert.Equal(t, c.project, project, c.name)
+	}
+}
+
+func Test_provider_allowed(t *testing.T) {
+	cases := []struct {
+		name    string
+		allowed []string
+		ip      string
+		ok      bool
+	}{
+		{name: "no restriction", ip: "203.0.113.10", ok: true},
+		{name: "ipv4", allowed: []string{"203.0.113.10"}, ip: "203.0.113.10", ok: true},
+		{name: "other ipv4", allowed: []string{"203.0.113.10"}, ip: "203.0.113.11"},
+		{name: "ipv4 in cidr", allowed: []string{"198.51.100.0/24"}, ip: "198.51.100.200", ok: true},
+		{name: "ipv4 out of cidr", allowed: []string{"198.51.100.0/24"}, ip: "198.51.101.1"},
+		{name: "ipv4 mapped to ipv6", allowed: []string{"203.0.113.10"}, ip: "::ffff:203.0.113.10", ok: true},
+		{name: "ipv6", allowed: []string{"2001:db8::1"}, ip: "2001:db8::1", ok: true},
+		{name: "other ipv6", allowed: []string{"2001:db8::1"}, ip: "2001:db8::2"},
+		{name: "ipv6 in cidr", allowed: []string{"2001:db8::/32"}, ip: "2001:db8:ffff::1", ok: true},
+		{name: "ipv4 out of ipv6 cidr", allowed: []string{"2001:db8::/32"}, ip: "203.0.113.10"},
+		{name: "second network", allowed: []string{"203.0.113.10", "2001:db8::/32"}, ip: "2001:db8::5", ok: true},
+		{name: "invalid ip", allowed: []string{"203.0.113.10"}, ip: "unknown"},
+		{name: "empty ip", allowed: []string{"203.0.113.10"}, ip: ""},
+	}
+
+	for _, c := range cases {
+		p := &provider{}
+
+		for _, value := range c.allowed {
+			network, err := parseNetwork(value)
+			require.NoError(t, err, c.name)
+			p.networks = append(p.networks, network)
+		}
+
+		assert.Equal(t, c.ok, p.allowed(c.ip), c.name)
+	}
+}
+
+func Test_parseNetwork_Error(t *testing.T) {
+	for _, value := range []string{"", "unknown", "203.0.113.256", "203.0.113.0/33", "2001:db8::/129", "203.0.113.10/"} {
+		_, err := parseNetwork(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_provider_callbackId(t *testing.T) {
+	cases := []struct {
+		name     string
+		fields   []string
+		body     string
+		expected string
+	}{
+		{name: "no fields", body: callbackBody},
+		{name: "nested", fields: []string{"payment_data.id"}, body: callbackBody, expected: "3549175"},
+		{name: "first found", fields: []string{"refund_data.id", "payment_data.id"}, body: callbackBody, expected: "3549175"},
+		{name: "first of several", fields: []string{"merchant_order.id", "payment_data.id"}, body: callbackBody, expected: "5e95b18d455b51545379c11a"},
+		{name: "numeric", fields: []string{"payment_data.id"}, body: `{"payment_data":{"id":12345678901234567890}}`, expected: "12345678901234567890"},
+		{name: "top level", fields: []string{"id"}, body: `{"id":"1"}`, expected: "1"},
+		{name: "empty string skipped", fields: []string{"id", "payment_data.id"}, body: `{"id":"","payment_data":{"id":"2"}}`, expected: "2"},
+		{name: "object value", fields: []string{"payment_data"}, body: callbackBody},
+		{name: "path through string", fields: []string{"payment_data.id.value"}, body: callbackBody},
+		{name: "missing", fields: []string{"refund_data.id"}, body: callbackBody},
+		{name: "invalid json", fields: []string{"payment_data.id"}, body: `payment_data.id=1`},
+	}
+
+	for _, c := range cases {
+		p := &provider{Provider: Provider{IdFields: c.fields}}
+		assert.Equal(t, c.expected, p.callbackId([]byte(c.body)), c.name)
+	}
+}
+
+func Test_New_Error(t *testing.T) {
+	cases := map[string]Provider{
+		"unknown algorithm": {Algorithm: "md5"},
+		"invalid ip":        {AllowedIps: []string{"unknown"}},
+	}
+
+	for name, settings := range cases {
+		_, err := New(&Config{Enabled: true, Providers: map[string]Provider{"cardpay": settings}}, nil, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func Test_New_Defaults(t *testing.T) {
+	cfg := &Config{
+		Enabled:   true,
+		Providers: map[string]Provider{"cardpay": {Secrets: map[string]string{"project": "secret"}}},
+	}
+	defaults := map[string]Provider{
+		"cardpay": {AllowedIps: []string{"203.0.113.10"}, SignatureHeader: signatureHeader, IdFields: []string{"payment_data.id"}},
+		"other":   {SignatureHeader: "X-Signature"},
+	}
+
+	v, err := New(cfg, idempotency.NewMemoryStore(), defaults)
+	require.NoError(t, err)
+	assert.True(t, v.Enabled())
+	require.Contains(t, v.providers, "other")
+
+	p := v.providers["cardpay"]
+	require.NotNil(t, p)
+	assert.Equal(t, AlgorithmSha512, p.Algorithm)
+	assert.Equal(t, signatureHeader, p.SignatureHeader)
+	assert.Equal(t, map[string]string{"project": "secret"}, p.Secrets)
+	assert.Len(t, p.networks, 1)
+}
+
+func Test_Verifier_Verify(t *testing.T) {
+	body := []byte(callbackBody)
+	signed := http.Header{signatureHeader: {sign(AlgorithmHmacSha256, "secret", body)}}
+
+	cases := []struct {
+		name    string
+		ip      string
+		header  http.Header
+		body    []byte
+		reason  string
+		project string
+	}{
+		{name: "accepted", ip: "203.0.113.10", header: signed, body: body, project: "project"},
+		{name: "ip not allowed", ip: "203.0.113.11", header: signed, body: body, reason: ReasonIpNotAllowed},
+		{name: "signature missing", ip: "203.0.113.10", header: http.Header{}, body: body, reason: ReasonSignatureMissing},
+		{name: "signature invalid", ip: "203.0.113.10", header: signed, body: []byte(`{"payment_data":{"id":"3549175"}}`), reason: ReasonSignatureInvalid},
+		{
+			name:    "signature in upper case",
+			ip:      "203.0.113.10",
+			header:  http.Header{signatureHeader: {" " + strings.ToUpper(sign(AlgorithmHmacSha256, "secret", body)) + " "}},
+			body:    body,
+			project: "project",
+		},
+	}
+
+	for _, c := range cases {
+		v := newVerifier(t, idempotency.NewMemoryStore(), 0)
+		res, err := v.Verify(context.Background(), "cardpay", c.ip, c.header, c.body)
+
+		require.NoError(t, err, c.name)
+		require.NotNil(t, res, c.name)
+		assert.Equal(t, "cardpay", res.Provider, c.name)
+		assert.Equal(t, "3549175", res.CallbackId, c.name)
+		assert.Equal(t, c.reason, res.Reason, c.name)
+		assert.Equal(t, c.project, res.Project, c.name)
+		// the replays aren't checked without the replay window
+		assert.Empty(t, res.ReplayKey, c.name)
+	}
+}
+
+func Test_Verifier_Verify_NotConfigured(t *testing.T) {
+	v := newVerifier(t, idempotency.NewMemoryStore(), time.Hour)
+
+	res, err := v.Verify(context.Background(), "unknown", "203.0.113.10", http.Header{}, []byte(callbackBody))
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	v, err = New(&Config{}, idempotency.NewMemoryStore(), map[string]Provider{"cardpay": {}})
+	require.NoError(t, err)
+	assert.False(t, v.Enabled())
+
+	res, err = v.Verify(context.Background(), "cardpay", "203.0.113.10", http.Header{}, []byte(callbackBody))
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func Test_Verifier_Verify_Replay(t *testing.T) {
+	ctx := context.Background()
+	body := []byte(callbackBody)
+	header := http.Header{signatureHeader: {sign(AlgorithmHmacSha256, "secret", body)}}
+	v := newVerifier(t, idempotency.NewMemoryStore(), time.Hour)
+
+	verify := func(body []byte, header http.Header) *Result {
+		res, err := v.Verify(ctx, "cardpay", "203.0.113.10", header, body)
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		return res
+	}
+
+	// the callback is reserved while it's processed
+	first := verify(body, header)
+	assert.Empty(t, first.Reason)
+	require.NotEmpty(t, first.ReplayKey)
+
+	assert.Equal(t, ReasonInProgress, verify(body, header).Reason)
+
+	// the released callback is accepted again
+	require.NoError(t, v.Release(ctx, first.ReplayKey))
+	retry := verify(body, header)
+	assert.Empty(t, retry.Reason)
+	assert.Equal(t, first.ReplayKey, retry.ReplayKey)
+
+	// the completed callback is the replay
+	require.NoError(t, v.Complete(ctx, retry.ReplayKey))
+	replay := verify(body, header)
+	assert.Equal(t, ReasonReplay, replay.Reason)
+	assert.Empty(t, replay.ReplayKey)
+
+	// the other body of the same callback is accepted
+	other := []byte(`{"payment_data":{"id":"3549175","status":"DECLINED"}}`)
+	res := verify(other, http.Header{signatureHeader: {sign(AlgorithmHmacSha256, "secret", other)}})
+	assert.Empty(t, res.Reason)
+	assert.NotEqual(t, first.ReplayKey, res.ReplayKey)
+}
+
+func Test_Verifier_Verify_ReservationExpired(t *testing.T) {
+	body := []byte(callbackBody)
+	header := http.Header{signatureHeader: {sign(AlgorithmHmacSha256, "secret", body)}}
+
+	// the reservation of the duplicate expired before it's read
+	v := newVerifier(t, &store{}, time.Hour)
+	res, err := v.Verify(context.Background(), "cardpay", "203.0.113.10", header, body)
+
+	require.NoError(t, err)
+	assert.Equal(t, ReasonInProgress, res.Reason)
+}
+
+func Test_Verifier_Verify_StoreError(t *testing.T) {
+	body := []byte(callbackBody)
+	header := http.Header{signatureHeader: {sign(AlgorithmHmacSha256, "secret", body)}}
+
+	cases := map[string]*store{
+		"reserve": {reserveErr: errors.New("reserve failed")},
+		"get":     {getErr: errors.New("get failed")},
+	}
+
+	for name, s := range cases {
+		v := newVerifier(t, s, time.Hour)
+		res, err := v.Verify(context.Background(), "cardpay", "203.0.113.10", header, body)
+
+		assert.Error(t, err, name)
+		assert.Nil(t, res, name)
+	}
+}
+
+func newVerifier(t *testing.T, s idempotency.Store, replayWindow time.Duration) *Verifier {
+	cfg := &Config{
+		Enabled:      true,
+		ReplayWindow: replayWindow,
+		LockTtl:      time.Minute,
+		Providers: map[string]Provider{
+			"cardpay": {
+				AllowedIps:      []string{"203.0.113.10", "2001:db8::/32"},
+				SignatureHeader: signatureHeader,
+				Algorithm:       AlgorithmHmacSha256,
+				Secrets:         map[string]string{"project": "secret"},
+				IdFields:        []string{"payment_data.id"},
+			},
+		},
+	}
+
+	v, err := New(cfg, s, nil)
+	require.NoError(t, err)
+
+	return v
+}
+
+// store never reserves the callback and returns the configured errors
+type store struct {
+	reserveErr error
+	getErr     error
+}
+
+func (s *store) Get(_ context.Context, _ string) (*idempotency.Record, error) {
+	return nil, s.getErr
+}
+
+func (s *store) Reserve(_ context.Context, _ string, _ *idempotency.Record, _ time.Duration) (bool, error) {
+	return false, s.reserveErr
+}
+
+func (s *store) Save(_ context.Context, _ string, _ *idempotency.Record, _ time.Duration) error {
+	return nil
+}
+
+func (s *store) Delete(_ context.Context, _ string) error {
+	return nil
+}
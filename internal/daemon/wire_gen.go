@@ -143,7 +143,8 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	commonHandlers, cleanup13, err := handlers.ProviderHandlers(initial, services, validate, awareSet, commonConfig)
+	authCache := dispatcher.ProviderAuthCache(commonConfig)
+	dispatcherConfig, cleanup13, err := dispatcher.ProviderCfg(configurator)
 	if err != nil {
 		cleanup12()
 		cleanup11()
@@ -159,14 +160,66 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	jwtVerifier := dispatcher.ProviderJwtVerifier(commonConfig)
-	appSet := dispatcher.AppSet{
-		Handlers:    commonHandlers,
-		Services:    services,
-		JwtVerifier: jwtVerifier,
+	client, cleanup14, err := dispatcher.ProviderRedis(dispatcherConfig)
+	if err != nil {
+		cleanup13()
+		cleanup12()
+		cleanup11()
+		cleanup10()
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	registry, cleanup15, err := dispatcher.ProviderApiKeys(awareSet, dispatcherConfig, client)
+	if err != nil {
+		cleanup14()
+		cleanup13()
+		cleanup12()
+		cleanup11()
+		cleanup10()
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	store := dispatcher.ProviderAudit(awareSet, dispatcherConfig, client)
+	reportfileStore := dispatcher.ProviderReportFiles(awareSet, commonConfig, client)
+	redactor, err := dispatcher.ProviderRedactor(dispatcherConfig)
+	if err != nil {
+		cleanup15()
+		cleanup14()
+		cleanup13()
+		cleanup12()
+		cleanup11()
+		cleanup10()
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
-	dispatcherConfig, cleanup14, err := dispatcher.ProviderCfg(configurator)
+	commonHandlers, cleanup16, err := handlers.ProviderHandlers(initial, services, validate, awareSet, commonConfig, authCache, registry, store, reportfileStore, redactor)
 	if err != nil {
+		cleanup15()
+		cleanup14()
 		cleanup13()
 		cleanup12()
 		cleanup11()
@@ -182,8 +235,19 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	dispatcherDispatcher, cleanup15, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, commonConfig, microMicro)
+	jwtVerifier := dispatcher.ProviderJwtVerifier(commonConfig)
+	appSet := dispatcher.AppSet{
+		Handlers:    commonHandlers,
+		Services:    services,
+		JwtVerifier: jwtVerifier,
+		AuthCache:   authCache,
+		Redis:       client,
+		ApiKeys:     registry,
+	}
+	dispatcherDispatcher, cleanup17, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, commonConfig, microMicro)
 	if err != nil {
+		cleanup16()
+		cleanup15()
 		cleanup14()
 		cleanup13()
 		cleanup12()
@@ -200,8 +264,10 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	httpConfig, cleanup16, err := http.Cfg(configurator)
+	httpConfig, cleanup18, err := http.Cfg(configurator)
 	if err != nil {
+		cleanup17()
+		cleanup16()
 		cleanup15()
 		cleanup14()
 		cleanup13()
@@ -219,8 +285,10 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		cleanup()
 		return nil, nil, err
 	}
-	httpHTTP, cleanup17, err := http.Provider(ctx, awareSet, dispatcherDispatcher, httpConfig)
+	httpHTTP, cleanup19, err := http.Provider(ctx, awareSet, dispatcherDispatcher, httpConfig)
 	if err != nil {
+		cleanup18()
+		cleanup17()
 		cleanup16()
 		cleanup15()
 		cleanup14()
@@ -240,6 +308,8 @@ func BuildHTTP(ctx context.Context, initial config.Initial, observer invoker.Obs
 		return nil, nil, err
 	}
 	return httpHTTP, func() {
+		cleanup19()
+		cleanup18()
 		cleanup17()
 		cleanup16()
 		cleanup15()
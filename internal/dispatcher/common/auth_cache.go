@@ -0,0 +1,102 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	jwtverifier "github.com/ProtocolONE/authone-jwt-verifier-golang"
+	"github.com/paysuper/paysuper-management-api/pkg/metrics"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+)
+
+const (
+	authCacheNameTokens    = "tokens"
+	authCacheNameMerchants = "merchants"
+)
+
+// AuthCache keeps results of the remote calls made by the authentication middlewares.
+// Invalidation is local to the replica, the other replicas rely on the TTL.
+type AuthCache struct {
+	tokens    *Cache
+	merchants *Cache
+}
+
+// NewAuthCache returns nil if the cache is disabled, all methods are safe to call on nil
+func NewAuthCache(cfg *AuthCacheSettings) *AuthCache {
+	if cfg.Disabled {
+		return nil
+	}
+	return &AuthCache{
+		tokens:    NewCache(cfg.Size, cfg.TokenTtl),
+		merchants: NewCache(cfg.Size, cfg.MerchantTtl),
+	}
+}
+
+// GetUserInfo
+func (c *AuthCache) GetUserInfo(token string) (*jwtverifier.UserInfo, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.tokens.Get(tokenHash(token))
+	metrics.ObserveAuthCacheLookup(authCacheNameTokens, ok)
+	if !ok {
+		return nil, false
+	}
+	return v.(*jwtverifier.UserInfo), true
+}
+
+// SetUserInfo
+func (c *AuthCache) SetUserInfo(token string, ui *jwtverifier.UserInfo) {
+	if c == nil {
+		return
+	}
+	c.tokens.Set(tokenHash(token), ui)
+}
+
+// GetMerchants
+func (c *AuthCache) GetMerchants(userId string) (*billingpb.GetMerchantsForUserResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.merchants.Get(userId)
+	metrics.ObserveAuthCacheLookup(authCacheNameMerchants, ok)
+	if !ok {
+		return nil, false
+	}
+	return v.(*billingpb.GetMerchantsForUserResponse), true
+}
+
+// SetMerchants
+func (c *AuthCache) SetMerchants(userId string, rsp *billingpb.GetMerchantsForUserResponse) {
+	if c == nil {
+		return
+	}
+	c.merchants.Set(userId, rsp)
+}
+
+// InvalidateUser
+func (c *AuthCache) InvalidateUser(userId string) {
+	if c == nil {
+		return
+	}
+	c.merchants.Delete(userId)
+}
+
+// InvalidateMerchant drops the cached merchants list of every user who belongs to the merchant
+func (c *AuthCache) InvalidateMerchant(merchantId string) {
+	if c == nil {
+		return
+	}
+	c.merchants.DeleteFunc(func(_ string, value interface{}) bool {
+		for _, merchant := range value.(*billingpb.GetMerchantsForUserResponse).Merchants {
+			if merchant.Id == merchantId {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func tokenHash(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
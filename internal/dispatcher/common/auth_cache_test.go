@@ -0,0 +1,134 @@
+package common
+
+import (
+	jwtverifier "github.com/ProtocolONE/authone-jwt-verifier-golang"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type AuthCacheTestSuite struct {
+	suite.Suite
+	cache *AuthCache
+}
+
+func Test_AuthCache(t *testing.T) {
+	suite.Run(t, new(AuthCacheTestSuite))
+}
+
+func (suite *AuthCacheTestSuite) SetupTest() {
+	suite.cache = NewAuthCache(&AuthCacheSettings{Size: 10, TokenTtl: time.Minute, MerchantTtl: time.Minute})
+}
+
+func (suite *AuthCacheTestSuite) TestCache_Expiry() {
+	cache := NewCache(10, 50*time.Millisecond)
+	cache.Set("key", "value")
+
+	value, ok := cache.Get("key")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "value", value)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, ok = cache.Get("key")
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), 0, cache.Len())
+}
+
+func (suite *AuthCacheTestSuite) TestCache_Set_ProlongsExpiry() {
+	cache := NewCache(10, 100*time.Millisecond)
+	cache.Set("key", "value")
+	time.Sleep(60 * time.Millisecond)
+	cache.Set("key", "updated")
+	time.Sleep(60 * time.Millisecond)
+
+	value, ok := cache.Get("key")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "updated", value)
+}
+
+func (suite *AuthCacheTestSuite) TestCache_Eviction_LeastRecentlyUsed() {
+	cache := NewCache(2, time.Minute)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	_, ok := cache.Get("a")
+	assert.True(suite.T(), ok)
+
+	cache.Set("c", 3)
+	assert.Equal(suite.T(), 2, cache.Len())
+
+	_, ok = cache.Get("b")
+	assert.False(suite.T(), ok)
+	_, ok = cache.Get("a")
+	assert.True(suite.T(), ok)
+	_, ok = cache.Get("c")
+	assert.True(suite.T(), ok)
+}
+
+func (suite *AuthCacheTestSuite) TestAuthCache_UserInfo() {
+	ui := &jwtverifier.UserInfo{UserID: "ffffffffffffffffffffffff"}
+	suite.cache.SetUserInfo("token", ui)
+
+	cached, ok := suite.cache.GetUserInfo("token")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), ui, cached)
+
+	_, ok = suite.cache.GetUserInfo("other_token")
+	assert.False(suite.T(), ok)
+}
+
+func (suite *AuthCacheTestSuite) TestAuthCache_InvalidateMerchant() {
+	suite.cache.SetMerchants("user_1", suite.merchants("merchant_1", "merchant_2"))
+	suite.cache.SetMerchants("user_2", suite.merchants("merchant_2"))
+	suite.cache.SetMerchants("user_3", suite.merchants("merchant_3"))
+
+	suite.cache.InvalidateMerchant("merchant_2")
+
+	_, ok := suite.cache.GetMerchants("user_1")
+	assert.False(suite.T(), ok)
+	_, ok = suite.cache.GetMerchants("user_2")
+	assert.False(suite.T(), ok)
+	rsp, ok := suite.cache.GetMerchants("user_3")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "merchant_3", rsp.Merchants[0].Id)
+}
+
+func (suite *AuthCacheTestSuite) TestAuthCache_InvalidateUser() {
+	suite.cache.SetMerchants("user_1", suite.merchants("merchant_1"))
+	suite.cache.SetMerchants("user_2", suite.merchants("merchant_1"))
+
+	suite.cache.InvalidateUser("user_1")
+
+	_, ok := suite.cache.GetMerchants("user_1")
+	assert.False(suite.T(), ok)
+	_, ok = suite.cache.GetMerchants("user_2")
+	assert.True(suite.T(), ok)
+}
+
+func (suite *AuthCacheTestSuite) TestAuthCache_Disabled() {
+	cache := NewAuthCache(&AuthCacheSettings{Disabled: true})
+	assert.Nil(suite.T(), cache)
+
+	cache.SetUserInfo("token", &jwtverifier.UserInfo{})
+	cache.SetMerchants("user_1", suite.merchants("merchant_1"))
+	cache.InvalidateMerchant("merchant_1")
+	cache.InvalidateUser("user_1")
+
+	_, ok := cache.GetUserInfo("token")
+	assert.False(suite.T(), ok)
+	_, ok = cache.GetMerchants("user_1")
+	assert.False(suite.T(), ok)
+}
+
+func (suite *AuthCacheTestSuite) merchants(ids ...string) *billingpb.GetMerchantsForUserResponse {
+	rsp := &billingpb.GetMerchantsForUserResponse{Status: billingpb.ResponseStatusOk}
+
+	for _, id := range ids {
+		rsp.Merchants = append(rsp.Merchants, &billingpb.MerchantForUserInfo{Id: id})
+	}
+
+	return rsp
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type cacheItem struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// Cache is a size bounded LRU cache with per-item expiration
+type Cache struct {
+	mx    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewCache
+func NewCache(size int, ttl time.Duration) *Cache {
+	if size <= 0 {
+		size = 1
+	}
+	return &Cache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := el.Value.(*cacheItem)
+	if time.Now().After(item.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return item.value, true
+}
+
+// Set
+func (c *Cache) Set(key string, value interface{}) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+
+	if el, ok := c.items[key]; ok {
+		item := el.Value.(*cacheItem)
+		item.value = value
+		item.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&cacheItem{key: key, value: value, expiresAt: expiresAt})
+
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Delete
+func (c *Cache) Delete(key string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// DeleteFunc removes all items for which fn returns true
+func (c *Cache) DeleteFunc(fn func(key string, value interface{}) bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		item := el.Value.(*cacheItem)
+		if fn(item.key, item.value) {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+// Len
+func (c *Cache) Len() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.ll.Len()
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*cacheItem).key)
+}
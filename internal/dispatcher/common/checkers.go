@@ -23,7 +23,7 @@ func CheckProjectAuthRequestSignature(dispatch HandlerSet, ctx echo.Context, pro
 	rsp, err := dispatch.Services.Billing.CheckProjectRequestSignature(ctx.Request().Context(), req)
 	if err != nil {
 		dispatch.AwareSet.L().Error(InternalErrorTemplate, logger.Args("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, ErrorUnknown)
+		return SrvCallError(err, http.StatusInternalServerError, ErrorUnknown)
 	}
 
 	if rsp.Status != billingpb.ResponseStatusOk {
@@ -32,28 +32,94 @@ func CheckProjectAuthRequestSignature(dispatch HandlerSet, ctx echo.Context, pro
 	return nil
 }
 
-// GetValidationError
-func GetValidationError(err error) (rspErr *billingpb.ResponseErrorMessage) {
+// ValidationFieldError
+type ValidationFieldError struct {
+	Field     string `json:"field"`
+	Namespace string `json:"namespace"`
+	Tag       string `json:"tag"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   string `json:"details"`
+}
+
+// ValidationErrorsResponse is returned instead of the first failed field error if the request
+// has the X-Validation-Errors: all header, the first error is kept at the top level for the compatibility
+type ValidationErrorsResponse struct {
+	Code    string                  `json:"code"`
+	Message string                  `json:"message"`
+	Details string                  `json:"details"`
+	Errors  []*ValidationFieldError `json:"errors"`
+}
+
+// NewValidationErrorsResponse returns nil if err isn't the validator error
+func NewValidationErrorsResponse(err error) *ValidationErrorsResponse {
+	list := GetValidationErrors(err)
+
+	if len(list) == 0 {
+		return nil
+	}
 
-	vErr := err.(validator.ValidationErrors)[0] // TODO: possible out of range
-	val, ok := ValidationErrors[vErr.Field()]
+	return &ValidationErrorsResponse{
+		Code:    list[0].Code,
+		Message: list[0].Message,
+		Details: list[0].Details,
+		Errors:  list,
+	}
+}
+
+// GetValidationError returns the error of the first failed field, the error is the copy of the declared one
+// to not share the details between the requests
+func GetValidationError(err error) *billingpb.ResponseErrorMessage {
+	vErrs, ok := err.(validator.ValidationErrors)
 
-	if ok {
-		rspErr = val
-	} else {
-		val, ok := ValidationNamespaceErrors[vErr.StructNamespace()]
+	if !ok || len(vErrs) == 0 {
+		return NewValidationError("")
+	}
+
+	vErr := vErrs[0]
+	val := fieldValidationError(vErr)
+	return NewManagementApiResponseError(val.Code, val.Message, fmt.Sprintf(ErrorMessageMask, vErr.Field(), vErr.Tag()))
+}
+
+// GetValidationErrors returns the errors of all failed fields
+func GetValidationErrors(err error) []*ValidationFieldError {
+	vErrs, _ := err.(validator.ValidationErrors)
+	list := make([]*ValidationFieldError, 0, len(vErrs))
+
+	for _, vErr := range vErrs {
+		rspErr := fieldValidationError(vErr)
+		list = append(list, &ValidationFieldError{
+			Field:     vErr.Field(),
+			Namespace: vErr.Namespace(),
+			Tag:       vErr.Tag(),
+			Code:      rspErr.Code,
+			Message:   rspErr.Message,
+			Details:   fmt.Sprintf(ErrorMessageMask, vErr.Field(), vErr.Tag()),
+		})
+	}
+
+	return list
+}
+
+// NewValidationHTTPError keeps the validation error to list all failed fields if the client requested it
+func NewValidationHTTPError(err error) *echo.HTTPError {
+	httpErr := echo.NewHTTPError(http.StatusBadRequest, GetValidationError(err))
+	httpErr.Internal = err
+	return httpErr
+}
+
+func fieldValidationError(vErr validator.FieldError) *billingpb.ResponseErrorMessage {
+	if val, ok := ValidationErrors[vErr.Field()]; ok {
+		return val
+	}
+
+	if val, ok := ValidationNamespaceErrors[vErr.StructNamespace()]; ok {
+		return val
+	}
 
-		if ok {
-			rspErr = val
-		} else {
-			if vErr.Tag() == RequestParameterZipUsa {
-				rspErr = ErrorMessageIncorrectZip
-			} else {
-				rspErr = ErrorValidationFailed
-			}
-		}
+	if vErr.Tag() == RequestParameterZipUsa {
+		return ErrorMessageIncorrectZip
 	}
 
-	rspErr.Details = fmt.Sprintf(ErrorMessageMask, vErr.Field(), vErr.Tag())
-	return rspErr
+	return ErrorValidationFailed
 }
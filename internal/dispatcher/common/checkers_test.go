@@ -0,0 +1,65 @@
+package common
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/go-playground/validator.v9"
+	"sync"
+	"testing"
+)
+
+func Test_GetValidationError(t *testing.T) {
+	type request struct {
+		Name  string `validate:"required"`
+		Email string `validate:"required,email"`
+	}
+
+	cases := []struct {
+		name    string
+		req     *request
+		details string
+	}{
+		{name: "name", req: &request{Email: "customer@unit.test"}, details: "field validation for 'Name' failed on the 'required' tag"},
+		{name: "email", req: &request{Name: "name", Email: "email"}, details: "field validation for 'Email' failed on the 'email' tag"},
+	}
+
+	v := validator.New()
+
+	for _, c := range cases {
+		err := v.Struct(c.req)
+		require.Error(t, err, c.name)
+
+		rspErr := GetValidationError(err)
+		assert.Equal(t, ErrorValidationFailed.Code, rspErr.Code, c.name)
+		assert.Equal(t, c.details, rspErr.Details, c.name)
+		// the declared error isn't changed
+		assert.NotSame(t, ErrorValidationFailed, rspErr, c.name)
+		assert.Empty(t, ErrorValidationFailed.Details, c.name)
+	}
+}
+
+func Test_GetValidationError_Concurrent(t *testing.T) {
+	type request struct {
+		Name  string `validate:"required"`
+		Email string `validate:"required,email"`
+	}
+
+	v := validator.New()
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 50; i++ {
+		req, details := &request{Email: "customer@unit.test"}, "field validation for 'Name' failed on the 'required' tag"
+
+		if i%2 == 0 {
+			req, details = &request{Name: "name", Email: "email"}, "field validation for 'Email' failed on the 'email' tag"
+		}
+
+		wg.Add(1)
+		go func(req *request, details string) {
+			defer wg.Done()
+			assert.Equal(t, details, GetValidationError(v.Struct(req)).Details)
+		}(req, details)
+	}
+
+	wg.Wait()
+}
@@ -6,6 +6,11 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/labstack/echo/v4"
+	"github.com/micro/go-micro"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
 	"github.com/paysuper/paysuper-proto/go/recurringpb"
 	"github.com/paysuper/paysuper-proto/go/reporterpb"
@@ -23,6 +28,8 @@ const (
 	SystemUserGroupPath      = "/system/api/v1"
 	NoAuthGroupPath          = "/api/v1"
 	WebHookGroupPath         = "/webhook"
+	HealthPath               = "/health"
+	ReadinessPath            = "/ready"
 )
 
 // Cursor
@@ -63,6 +70,14 @@ func ExtractBinderContext(ctx echo.Context) echo.Binder {
 	return nil
 }
 
+// ExtractApiKeyContext returns nil if the request wasn't authenticated with an api key
+func ExtractApiKeyContext(ctx echo.Context) *apikey.Key {
+	if key, ok := ctx.Get("apiKey").(*apikey.Key); ok {
+		return key
+	}
+	return nil
+}
+
 // SetUserContext
 func SetUserContext(ctx echo.Context, user *AuthUser) {
 	ctx.Set("user", user)
@@ -78,6 +93,11 @@ func SetCursorContext(ctx echo.Context, cursor *Cursor) {
 	ctx.Set("cursor", cursor)
 }
 
+// SetApiKeyContext
+func SetApiKeyContext(ctx echo.Context, key *apikey.Key) {
+	ctx.Set("apiKey", key)
+}
+
 // SetBinder
 func SetBinder(ctx echo.Context, binder echo.Binder) {
 	ctx.Set("binder", binder)
@@ -110,6 +130,8 @@ type Services struct {
 	Billing    billingpb.BillingService
 	Tax        taxpb.TaxService
 	Reporter   reporterpb.ReporterService
+	// WebhookNotifier publishes the orders to the topic of the project notifications
+	WebhookNotifier micro.Publisher
 }
 
 // Handlers
@@ -117,9 +139,15 @@ type Handlers []Handler
 
 // HandlerSet
 type HandlerSet struct {
-	Services Services
-	Validate *validator.Validate
-	AwareSet provider.AwareSet
+	Services  Services
+	Validate  *validator.Validate
+	AwareSet  provider.AwareSet
+	AuthCache *AuthCache
+	ApiKeys   *apikey.Registry
+	Audit     audit.Store
+	// ReportFiles keeps the owners of the requested report files
+	ReportFiles reportfile.Store
+	Redactor    *redact.Redactor
 }
 
 // BindAndValidate
@@ -128,12 +156,12 @@ func (h HandlerSet) BindAndValidate(req interface{}, ctx echo.Context) *echo.HTT
 		return echo.NewHTTPError(http.StatusBadRequest, ErrorRequestParamsIncorrect)
 	}
 	if err := h.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, GetValidationError(err))
+		return NewValidationHTTPError(err)
 	}
 	return nil
 }
 
-// SrvCallHandler returns error if present, otherwise response as JSON with 200 OK
+// SrvCallHandler returns the http error of the failed service call
 func (h HandlerSet) SrvCallHandler(req interface{}, err error, name, method string) *echo.HTTPError {
 	h.AwareSet.L().Error(billingpb.ErrorGrpcServiceCallFailed,
 		logger.PairArgs(
@@ -142,7 +170,7 @@ func (h HandlerSet) SrvCallHandler(req interface{}, err error, name, method stri
 		),
 		logger.WithPrettyFields(logger.Fields{"err": err, ErrorFieldRequest: req}),
 	)
-	return echo.NewHTTPError(http.StatusInternalServerError, ErrorInternal)
+	return SrvCallError(err, http.StatusInternalServerError, ErrorInternal)
 }
 
 // AuthUser
@@ -169,7 +197,7 @@ func (h *HandlerSet) RequestReportFile(
 	req.SendNotification = true
 
 	if err = h.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, GetValidationError(err))
+		return NewValidationHTTPError(err)
 	}
 
 	res, err := h.Services.Reporter.CreateFile(ctx.Request().Context(), req)
@@ -182,5 +210,15 @@ func (h *HandlerSet) RequestReportFile(
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
+	owner := &reportfile.Owner{UserId: req.UserId, MerchantId: req.MerchantId}
+
+	if err = h.ReportFiles.Save(ctx.Request().Context(), res.FileId, owner); err != nil {
+		h.AwareSet.L().Error(
+			"unable to save the owner of the report file",
+			logger.PairArgs("err", err.Error(), "file_id", res.FileId),
+		)
+		return echo.NewHTTPError(http.StatusInternalServerError, ErrorInternal)
+	}
+
 	return ctx.JSON(http.StatusOK, res)
 }
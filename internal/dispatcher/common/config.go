@@ -1,5 +1,11 @@
 package common
 
+import (
+	"github.com/paysuper/paysuper-management-api/internal/callbackinbox"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
+	"time"
+)
+
 type Auth1 struct {
 	Issuer       string `envconfig:"AUTH1_ISSUER" default:"https://dev-auth1.tst.protocol.one"`
 	ClientId     string `envconfig:"AUTH1_CLIENTID" required:"true"`
@@ -7,13 +13,41 @@ type Auth1 struct {
 	RedirectUrl  string `envconfig:"AUTH1_REDIRECTURL" required:"true"`
 }
 
+// LogsSettings of the amazon cloudwatch order logs backend
 type LogsSettings struct {
-	AwsCloudWatchAccessKeyId             string `envconfig:"AWS_CLOUDWATCH_ACCESS_KEY_ID" required:"true"`
-	AwsCloudWatchSecretAccessKey         string `envconfig:"AWS_CLOUDWATCH_SECRET_ACCESS_KEY" required:"true"`
+	AwsCloudWatchAccessKeyId             string `envconfig:"AWS_CLOUDWATCH_ACCESS_KEY_ID"`
+	AwsCloudWatchSecretAccessKey         string `envconfig:"AWS_CLOUDWATCH_SECRET_ACCESS_KEY"`
 	AwsCloudWatchRegion                  string `envconfig:"AWS_CLOUDWATCH_REGION" default:"eu-west-1"`
-	AwsCloudWatchLogGroupBillingServer   string `envconfig:"AWS_CLOUDWATCH_LOG_GROUP_BILLING_SERVER" required:"true"`
-	AwsCloudWatchLogGroupManagementApi   string `envconfig:"AWS_CLOUDWATCH_LOG_GROUP_MANAGEMENT_API" required:"true"`
-	AwsCloudWatchLogGroupWebhookNotifier string `envconfig:"AWS_CLOUDWATCH_LOG_GROUP_WEBHOOK_NOTIFIER" required:"true"`
+	AwsCloudWatchLogGroupBillingServer   string `envconfig:"AWS_CLOUDWATCH_LOG_GROUP_BILLING_SERVER"`
+	AwsCloudWatchLogGroupManagementApi   string `envconfig:"AWS_CLOUDWATCH_LOG_GROUP_MANAGEMENT_API"`
+	AwsCloudWatchLogGroupWebhookNotifier string `envconfig:"AWS_CLOUDWATCH_LOG_GROUP_WEBHOOK_NOTIFIER"`
+}
+
+type AuthCacheSettings struct {
+	Disabled    bool          `envconfig:"AUTH_CACHE_DISABLED"`
+	Size        int           `envconfig:"AUTH_CACHE_SIZE" default:"10000"`
+	TokenTtl    time.Duration `envconfig:"AUTH_CACHE_TOKEN_TTL" default:"1m"`
+	MerchantTtl time.Duration `envconfig:"AUTH_CACHE_MERCHANT_TTL" default:"1m"`
+}
+
+// WebhookTestingSettings of the project's webhook test suite, Timeout is the maximum time to wait
+// for the delivery attempts of the test notifications
+type WebhookTestingSettings struct {
+	Timeout      time.Duration `envconfig:"WEBHOOK_TESTING_TIMEOUT" default:"30s"`
+	PollInterval time.Duration `envconfig:"WEBHOOK_TESTING_POLL_INTERVAL" default:"1s"`
+}
+
+// ReportFileSettings of the report file downloads, the files are streamed from the bucket unless Redirect is set,
+// then the client is redirected to the presigned url of the file valid for PresignTtl
+type ReportFileSettings struct {
+	Redirect   bool          `envconfig:"REPORT_FILE_DOWNLOAD_REDIRECT"`
+	PresignTtl time.Duration `envconfig:"REPORT_FILE_PRESIGN_TTL" default:"5m"`
+	// TokenSecret enables the signed download tokens, the file is downloaded with the token without the
+	// authorization until TokenTtl expires
+	TokenSecret string        `envconfig:"REPORT_FILE_TOKEN_SECRET"`
+	TokenTtl    time.Duration `envconfig:"REPORT_FILE_TOKEN_TTL" default:"5m"`
+	// OwnerTtl is how long the owners of the requested files are kept, the owner is read from the file metadata after it
+	OwnerTtl time.Duration `envconfig:"REPORT_FILE_OWNER_TTL" default:"720h"`
 }
 
 type Config struct {
@@ -30,6 +64,8 @@ type Config struct {
 	AwsRegionReporter          string `envconfig:"AWS_REGION_REPORTER" default:"eu-west-1"`
 	AwsBucketReporter          string `envconfig:"AWS_BUCKET_REPORTER" required:"true"`
 
+	ReportFiles ReportFileSettings
+
 	LimitDefault          int32 `default:"100"`
 	OffsetDefault         int32 `default:"0"`
 	LimitMax              int32 `default:"1000"`
@@ -37,6 +73,17 @@ type Config struct {
 
 	OrderInlineFormUrlMask string `envconfig:"ORDER_INLINE_FORM_URL_MASK" required:"true"`
 
+	AuthCache AuthCacheSettings
+
+	OrderLogs orderlog.Config
+
+	WebhookTesting WebhookTestingSettings
+
+	// WebhookProviders are the payment systems accepting the callbacks on the /webhook/:provider/:event routes
+	WebhookProviders []string `envconfig:"WEBHOOK_PROVIDERS"`
+
+	CallbackInbox callbackinbox.Config
+
 	AllowOrigin string `envconfig:"ALLOW_ORIGIN" default:"*"`
 	HttpScheme  string `envconfig:"HTTP_SCHEME" default:"https"`
 }
@@ -70,6 +70,8 @@ const (
 	RequestParameterUrlRefundPayment         = "url_refund_payment"
 	RequestParameterStatus                   = "status"
 	RequestAuthorizationTokenRegex           = "Bearer ([A-z0-9_.-]{10,})"
+	RequestAuthorizationApiKeyRegex          = "^ApiKey ([A-z0-9_.-]{10,})$"
+	RequestParameterApiKeyId                 = "key_id"
 	RequestParameterZipUsa                   = "zip_usa"
 	RequestParameterRateId                   = "rate_id"
 	RequestParameterReceiptId                = "receipt_id"
@@ -86,6 +88,10 @@ const (
 	RequestPayoutDocumentId                  = "payout_document_id"
 	RequestParameterRedirectSettings         = "redirect_settings"
 	RequestParameterWebhookMode              = "webhook_mode"
+	RequestParameterCustomerId               = "customer_id"
+	RequestParameterInstrumentId             = "instrument_id"
+	RequestParameterSubscriptionId           = "subscription_id"
+	RequestParameterToken                    = "token"
 
 	ImageCollectionImagesField  = "images"
 	ImageCollectionUseOneForAll = "use_one_for_all"
@@ -121,6 +127,8 @@ const (
 	QueryParameterNameOffset = "offset"
 	QueryParameterNameSort   = "sort[]"
 
+	QueryParameterNameMerchantId = "merchant_id"
+
 	QueryParameterNameUtmMedium   = "utm_medium"
 	QueryParameterNameUtmCampaign = "utm_campaign"
 	QueryParameterNameUtmSource   = "utm_source"
@@ -128,9 +136,22 @@ const (
 	ErrorMessageMask = "field validation for '%s' failed on the '%s' tag"
 
 	HeaderAcceptLanguage      = "Accept-Language"
+	HeaderContentLanguage     = "Content-Language"
 	HeaderUserAgent           = "User-Agent"
 	HeaderXApiSignatureHeader = "X-API-SIGNATURE"
 	HeaderReferer             = "referer"
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	HeaderXMerchantId         = "X-Merchant-Id"
+	HeaderXValidationErrors   = "X-Validation-Errors"
+	HeaderRetryAfter          = "Retry-After"
+	HeaderRateLimitLimit      = "RateLimit-Limit"
+	HeaderRateLimitRemaining  = "RateLimit-Remaining"
+	HeaderRateLimitReset      = "RateLimit-Reset"
+	HeaderRange               = "Range"
+	HeaderContentRange        = "Content-Range"
+	HeaderAcceptRanges        = "Accept-Ranges"
+	HeaderETag                = "ETag"
 
 	// EnvironmentProduction        = "prod"
 	CustomerTokenCookiesName = "_ps_ctkn"
@@ -140,6 +161,12 @@ const (
 
 	AgreementPageTemplateName = "agreement.html"
 
+	// ValidationErrorsAll is the value of X-Validation-Errors header to get the errors of all failed fields
+	ValidationErrorsAll = "all"
+
+	CasbinPolicyFile = "/assets/policy.conf"
+	ApiKeysRoutePath = "/api_keys"
+
 	UserProfilePositionCEO               = "CEO"
 	UserProfilePositionCTO               = "CTO"
 	UserProfilePositionCMO               = "CMO"
@@ -400,7 +427,8 @@ var (
 
 	TestStubImplementMe = "implement me!"
 
-	TokenRegex = regexp.MustCompile(RequestAuthorizationTokenRegex)
+	TokenRegex  = regexp.MustCompile(RequestAuthorizationTokenRegex)
+	ApiKeyRegex = regexp.MustCompile(RequestAuthorizationApiKeyRegex)
 )
 
 func LogSrvCallFailedGRPC(log logger.Logger, err error, name, method string, req interface{}) {
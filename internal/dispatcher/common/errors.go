@@ -12,7 +12,31 @@ func NewManagementApiResponseError(code, msg string, details ...string) *billing
 	} else {
 		det = ""
 	}
-	return &billingpb.ResponseErrorMessage{Code: code, Message: msg, Details: det}
+	rspErr := &billingpb.ResponseErrorMessage{Code: code, Message: msg, Details: det}
+
+	if !responseErrorsFrozen {
+		responseErrors = append(responseErrors, rspErr)
+	}
+
+	return rspErr
+}
+
+// responseErrors are the errors declared by the package variables, the list is frozen after the package
+// initialization to not grow with the errors created in runtime
+var (
+	responseErrors       []*billingpb.ResponseErrorMessage
+	responseErrorsFrozen bool
+)
+
+func init() {
+	responseErrorsFrozen = true
+}
+
+// ResponseErrors returns the errors declared by the package
+func ResponseErrors() []*billingpb.ResponseErrorMessage {
+	list := make([]*billingpb.ResponseErrorMessage, len(responseErrors))
+	copy(list, responseErrors)
+	return list
 }
 
 // NewValidationError
@@ -172,6 +196,41 @@ var (
 	ErrorMessageListOrdersRequestPmDateTo                    = NewManagementApiResponseError("ma000111", "date filter is incorrect")
 	ErrorMessageListOrdersRequestProjectDateFrom             = NewManagementApiResponseError("ma000111", "date filter is incorrect")
 	ErrorMessageListOrdersRequestProjectDateTo               = NewManagementApiResponseError("ma000111", "date filter is incorrect")
+	ErrorIdempotencyKeyIncorrect                             = NewManagementApiResponseError("ma000112", "idempotency key is incorrect")
+	ErrorIdempotencyKeyReused                                = NewManagementApiResponseError("ma000113", "idempotency key was already used with another request")
+	ErrorIdempotencyRequestInProgress                        = NewManagementApiResponseError("ma000114", "request with the same idempotency key is in progress")
+	ErrorMessageMerchantNotAllowed                           = NewManagementApiResponseError("ma000115", "merchant is not available for the user")
+	ErrorApiKeyInvalid                                       = NewManagementApiResponseError("ma000116", "api key is invalid, revoked or expired")
+	ErrorApiKeyNotFound                                      = NewManagementApiResponseError("ma000117", "api key not found")
+	ErrorApiKeyScopesIncorrect                               = NewManagementApiResponseError("ma000118", "api key scopes are incorrect")
+	ErrorApiKeyRouteNotAllowed                               = NewManagementApiResponseError("ma000119", "route is not allowed for the api key")
+	ErrorRateLimitExceeded                                   = NewManagementApiResponseError("ma000120", "too many requests")
+	ErrorServiceTimeout                                      = NewManagementApiResponseError("ma000121", "service did not respond in time. try request later")
+	ErrorServiceUnavailable                                  = NewManagementApiResponseError("ma000122", "service is temporarily unavailable. try request later")
+	ErrorRequestCancelled                                    = NewManagementApiResponseError("ma000123", "request was cancelled by the client")
+	ErrorServiceObjectNotFound                               = NewManagementApiResponseError("ma000124", "requested object not found")
+	ErrorRequestBodyTooLarge                                 = NewManagementApiResponseError("ma000125", "request body is too large")
+	ErrorOrderLogsWindowInvalid                              = NewManagementApiResponseError("ma000126", "order logs period is invalid or too long")
+	ErrorOrderLogsTokenInvalid                               = NewManagementApiResponseError("ma000127", "order logs page token is invalid")
+	ErrorWebhookResendOrderStatus                            = NewManagementApiResponseError("ma000128", "order in this status has no notification to resend")
+	ErrorWebhookResendTooManyOrders                          = NewManagementApiResponseError("ma000129", "too many orders to resend the notifications, narrow the filter")
+	ErrorWebhookTestSuiteEmpty                               = NewManagementApiResponseError("ma000130", "no sales type to test, pass the amount with the currency, the products or the key products")
+	ErrorWebhookTestSuiteSave                                = NewManagementApiResponseError("ma000131", "unable to save the webhook testing report")
+	ErrorCallbackIpNotAllowed                                = NewManagementApiResponseError("ma000132", "callbacks from this address aren't allowed")
+	ErrorCallbackSignatureInvalid                            = NewManagementApiResponseError("ma000133", "callback signature is missing or invalid")
+	ErrorCallbackProviderUnknown                             = NewManagementApiResponseError("ma000134", "unknown payment system or callback event")
+	ErrorCallbackInboxDisabled                               = NewManagementApiResponseError("ma000135", "callback inbox is disabled")
+	ErrorCallbackNotFound                                    = NewManagementApiResponseError("ma000136", "callback not found")
+	ErrorCallbackAlreadyProcessed                            = NewManagementApiResponseError("ma000137", "callback is already processed")
+	ErrorCallbackBusy                                        = NewManagementApiResponseError("ma000138", "callback is being processed, try again later")
+	ErrorCallbackInboxFailed                                 = NewManagementApiResponseError("ma000139", "callback inbox is unavailable")
+	ErrorSavedInstrumentNotFound                             = NewManagementApiResponseError("ma000140", "saved payment instrument not found")
+	ErrorRecurringSubscriptionNotFound                       = NewManagementApiResponseError("ma000141", "subscription not found")
+	ErrorReportFileNotFound                                  = NewManagementApiResponseError("ma000142", "report file not found")
+	ErrorReportFileRangeNotSatisfiable                       = NewManagementApiResponseError("ma000143", "requested range of the report file is not satisfiable")
+	ErrorReportFileTokensDisabled                            = NewManagementApiResponseError("ma000144", "report file download tokens are disabled")
+	ErrorReportFileTokenInvalid                              = NewManagementApiResponseError("ma000145", "report file download token is invalid or expired")
+	ErrorWebhookTestSuiteProjectNotFound                     = NewManagementApiResponseError("ma000146", "project not found")
 
 	ValidationErrors = map[string]*billingpb.ResponseErrorMessage{
 		UserProfileFieldNumberOfEmployees: ErrorMessageIncorrectNumberOfEmployees,
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/internal/i18n"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// LocalizeMessage returns the copy of the http error message translated to the locale, false is returned
+// if the message isn't the management api error or has no translation, the shared errors are never changed
+func LocalizeMessage(locale string, message interface{}, internal error) (interface{}, bool) {
+	switch msg := message.(type) {
+	case *billingpb.ResponseErrorMessage:
+		return LocalizeError(locale, msg, internal)
+	case *ValidationErrorsResponse:
+		return LocalizeValidationErrors(locale, msg)
+	}
+
+	return message, false
+}
+
+// LocalizeError rebuilds the details from the failed field if the error is caused by the validation
+func LocalizeError(locale string, rspErr *billingpb.ResponseErrorMessage, internal error) (*billingpb.ResponseErrorMessage, bool) {
+	if rspErr == nil {
+		return rspErr, false
+	}
+
+	msg, ok := i18n.Message(locale, rspErr.Code, rspErr.Message)
+	if !ok {
+		return rspErr, false
+	}
+
+	localized := &billingpb.ResponseErrorMessage{Code: rspErr.Code, Message: msg, Details: rspErr.Details}
+
+	if vErrs, ok := internal.(validator.ValidationErrors); ok && len(vErrs) > 0 {
+		localized.Details = localizeValidationDetails(locale, vErrs[0].Field(), vErrs[0].Tag())
+	}
+
+	return localized, true
+}
+
+// LocalizeValidationErrors
+func LocalizeValidationErrors(locale string, res *ValidationErrorsResponse) (*ValidationErrorsResponse, bool) {
+	if res == nil || len(res.Errors) == 0 {
+		return res, false
+	}
+
+	list := make([]*ValidationFieldError, 0, len(res.Errors))
+
+	for _, fErr := range res.Errors {
+		localized := *fErr
+		localized.Message, _ = i18n.Message(locale, fErr.Code, fErr.Message)
+		localized.Details = localizeValidationDetails(locale, fErr.Field, fErr.Tag)
+		list = append(list, &localized)
+	}
+
+	return &ValidationErrorsResponse{
+		Code:    list[0].Code,
+		Message: list[0].Message,
+		Details: list[0].Details,
+		Errors:  list,
+	}, true
+}
+
+func localizeValidationDetails(locale, field, tag string) string {
+	mask, ok := i18n.ValidationMask(locale)
+	if !ok {
+		mask = ErrorMessageMask
+	}
+	return fmt.Sprintf(mask, field, tag)
+}
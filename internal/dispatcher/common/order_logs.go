@@ -0,0 +1,55 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
+)
+
+// NewOrderLogSource creates the order logs source of the configured backend
+func NewOrderLogSource(cfg *Config) (orderlog.Source, error) {
+	if cfg.OrderLogs.IsCloudWatch() {
+		if cfg.LogsSettings == nil {
+			return nil, errors.New("amazon cloudwatch logs settings are required by the cloudwatch order logs backend")
+		}
+
+		client, err := NewCloudWatch(cfg.LogsSettings)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return orderlog.NewCloudWatch(client), nil
+	}
+
+	switch cfg.OrderLogs.Backend {
+	case orderlog.BackendElasticsearch:
+		return orderlog.NewElasticsearch(&cfg.OrderLogs.Elasticsearch, nil)
+	case orderlog.BackendFile:
+		return orderlog.NewFile(&cfg.OrderLogs.File), nil
+	}
+
+	return nil, fmt.Errorf("unknown order logs backend %q", cfg.OrderLogs.Backend)
+}
+
+// OrderLogStreams returns the streams of the order logs, the cloudwatch backend falls back
+// to the log groups of the logs settings
+func (c *Config) OrderLogStreams() orderlog.Streams {
+	streams := c.OrderLogs.Streams
+
+	if !c.OrderLogs.IsCloudWatch() || c.LogsSettings == nil {
+		return streams
+	}
+
+	if streams.BillingServer == "" {
+		streams.BillingServer = c.AwsCloudWatchLogGroupBillingServer
+	}
+	if streams.ManagementApi == "" {
+		streams.ManagementApi = c.AwsCloudWatchLogGroupManagementApi
+	}
+	if streams.WebhookNotifier == "" {
+		streams.WebhookNotifier = c.AwsCloudWatchLogGroupWebhookNotifier
+	}
+
+	return streams
+}
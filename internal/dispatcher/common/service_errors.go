@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/micro/go-micro/errors"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"net/http"
+	"strings"
+)
+
+const (
+	// StatusClientClosedRequest is the non-standard status of the request cancelled by the client
+	StatusClientClosedRequest = 499
+
+	microClientErrorId = "go.micro.client"
+)
+
+// SrvCallError returns the http error of the failed service call, the timeouts, unavailable services,
+// cancelled requests and not found objects get own status and error, other errors are returned
+// with the status and the message passed by the caller
+func SrvCallError(err error, status int, message interface{}) *echo.HTTPError {
+	if code, rspErr, ok := translateSrvCallError(err); ok {
+		status, message = code, rspErr
+	}
+
+	httpErr := echo.NewHTTPError(status, message)
+	httpErr.Internal = err
+	return httpErr
+}
+
+// SrvCallStatus returns the http status of the failed service call, the status passed by the caller is returned
+// if the error isn't recognized
+func SrvCallStatus(err error, status int) int {
+	if code, _, ok := translateSrvCallError(err); ok {
+		return code
+	}
+	return status
+}
+
+func translateSrvCallError(err error) (int, *billingpb.ResponseErrorMessage, bool) {
+	if err == nil {
+		return 0, nil, false
+	}
+
+	switch err {
+	case context.DeadlineExceeded:
+		return http.StatusGatewayTimeout, ErrorServiceTimeout, true
+	case context.Canceled:
+		return StatusClientClosedRequest, ErrorRequestCancelled, true
+	}
+
+	microErr, ok := err.(*errors.Error)
+	if !ok {
+		microErr = errors.Parse(err.Error())
+	}
+
+	switch {
+	case strings.Contains(microErr.Detail, context.Canceled.Error()):
+		return StatusClientClosedRequest, ErrorRequestCancelled, true
+	case microErr.Code == http.StatusRequestTimeout || microErr.Code == http.StatusGatewayTimeout ||
+		strings.Contains(microErr.Detail, context.DeadlineExceeded.Error()):
+		return http.StatusGatewayTimeout, ErrorServiceTimeout, true
+	case microErr.Code == http.StatusServiceUnavailable:
+		return http.StatusServiceUnavailable, ErrorServiceUnavailable, true
+	case microErr.Id == microClientErrorId &&
+		(microErr.Code == http.StatusInternalServerError || microErr.Code == http.StatusNotFound):
+		// the client failed to select the node of the service or to connect to it
+		return http.StatusServiceUnavailable, ErrorServiceUnavailable, true
+	case microErr.Code == http.StatusNotFound:
+		return http.StatusNotFound, ErrorServiceObjectNotFound, true
+	}
+
+	return 0, nil, false
+}
@@ -0,0 +1,62 @@
+package common
+
+import (
+	"github.com/opentracing/opentracing-go"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	HeaderTraceParent  = "traceparent"
+	HeaderJaegerTrace  = "uber-trace-id"
+	HeaderXTraceId     = "X-Trace-Id"
+	traceFlagsSampled  = "01"
+	traceParentVersion = "00"
+)
+
+var traceParentRegex = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// TraceCarrier returns the carrier to extract the incoming span context from, the W3C traceparent header
+// is translated to the jaeger format when the request doesn't contain the jaeger header
+func TraceCarrier(header http.Header) opentracing.HTTPHeadersCarrier {
+	carrier := opentracing.HTTPHeadersCarrier(header)
+
+	if header.Get(HeaderJaegerTrace) != "" {
+		return carrier
+	}
+
+	match := traceParentRegex.FindStringSubmatch(strings.ToLower(header.Get(HeaderTraceParent)))
+
+	if len(match) < 5 || match[1] == "ff" || strings.Trim(match[2], "0") == "" || strings.Trim(match[3], "0") == "" {
+		return carrier
+	}
+
+	flags := "0"
+	if match[4] == traceFlagsSampled {
+		flags = "1"
+	}
+
+	copied := header.Clone()
+	copied.Set(HeaderJaegerTrace, match[2]+":"+match[3]+":0:"+flags)
+	return opentracing.HTTPHeadersCarrier(copied)
+}
+
+// TraceId returns empty string if the tracer doesn't propagate the context in the jaeger or W3C format
+func TraceId(tracer opentracing.Tracer, ctx opentracing.SpanContext) string {
+	carrier := opentracing.TextMapCarrier{}
+
+	if err := tracer.Inject(ctx, opentracing.TextMap, carrier); err != nil {
+		return ""
+	}
+
+	if v, ok := carrier[HeaderJaegerTrace]; ok {
+		return strings.SplitN(v, ":", 2)[0]
+	}
+
+	if match := traceParentRegex.FindStringSubmatch(carrier[HeaderTraceParent]); len(match) == 5 && match[1] == traceParentVersion {
+		return match[2]
+	}
+
+	return ""
+}
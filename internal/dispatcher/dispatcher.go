@@ -2,6 +2,7 @@ package dispatcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	jwtverifier "github.com/ProtocolONE/authone-jwt-verifier-golang"
 	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
@@ -10,8 +11,18 @@ import (
 	"github.com/alexeyco/simpletable"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/opentracing/opentracing-go"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
+	"github.com/paysuper/paysuper-management-api/internal/bodylimit"
+	"github.com/paysuper/paysuper-management-api/internal/callbackverify"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/health"
+	"github.com/paysuper/paysuper-management-api/internal/idempotency"
+	"github.com/paysuper/paysuper-management-api/internal/ratelimit"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
 	"github.com/paysuper/paysuper-management-api/pkg/micro"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
 	"html/template"
 	"io/ioutil"
@@ -26,8 +37,14 @@ type Dispatcher struct {
 	cfg    Config
 	appSet AppSet
 	provider.LMT
-	globalCfg *common.Config
-	ms        *micro.Micro
+	globalCfg   *common.Config
+	ms          *micro.Micro
+	idempotency idempotency.Store
+	rateLimiter *ratelimit.Limiter
+	redactor    *redact.Redactor
+	callbacks   *callbackverify.Verifier
+	health      *health.Checker
+	tracer      opentracing.Tracer
 }
 
 // dispatch
@@ -36,6 +53,9 @@ func (d *Dispatcher) Dispatch(echoHttp *echo.Echo) error {
 	if e != nil {
 		return e
 	}
+	if e = d.initStores(); e != nil {
+		return e
+	}
 	echoHttp.Renderer = common.NewTemplate(t)
 	echoHttp.Binder = &common.Binder{
 		LimitDefault:  int64(d.globalCfg.LimitDefault),
@@ -43,25 +63,32 @@ func (d *Dispatcher) Dispatch(echoHttp *echo.Echo) error {
 		LimitMax:      int64(d.globalCfg.LimitMax),
 	}
 	// Called after routes
+	echoHttp.Use(d.MetricsMiddleware)            // 8
+	echoHttp.Use(d.TracingMiddleware)            // 7
+	echoHttp.Use(d.AccessLogRedactionMiddleware) // 6
 	echoHttp.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Output: logger.NewLevelWriter(d.L(), logger.LevelInfo),
-		Format: `{"id":"${id}","remote_ip":"${remote_ip}",` +
+		Format: `{"id":"${id}","trace_id":"${header:` + common.HeaderXTraceId + `}","remote_ip":"${remote_ip}",` +
 			`"host":"${host}","method":"${method}","uri":"${uri}","user_agent":"${user_agent}",` +
 			`"status":${status},"error":"${error}","latency":${latency},"latency_human":"${latency_human}"` +
 			`,"bytes_in":${bytes_in},"bytes_out":${bytes_out}}`,
-	})) // 3
+	})) // 5
 
 	allowOrigins := strings.Split(d.globalCfg.AllowOrigin, ",")
 
-	echoHttp.Use(d.RecoverMiddleware()) // 2
+	echoHttp.Use(d.RecoverMiddleware()) // 4
 	echoHttp.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     allowOrigins,
 		AllowCredentials: true,
-		AllowHeaders:     []string{"authorization", "content-type"},
-		ExposeHeaders:    []string{"authorization", "content-type", "set-cookie", "cookie"},
-	})) // 1
+		AllowHeaders: []string{"authorization", "content-type", "idempotency-key", "x-merchant-id", "traceparent",
+			"x-validation-errors"},
+		ExposeHeaders: []string{"authorization", "content-type", "set-cookie", "cookie", "idempotent-replayed",
+			"ratelimit-limit", "ratelimit-remaining", "ratelimit-reset", "retry-after"},
+	})) // 3
+	echoHttp.Use(d.LocalizationMiddleware)     // 2
+	echoHttp.Use(d.ValidationErrorsMiddleware) // 1
 	// Called before routes
-	echoHttp.Use(d.RawBodyPreMiddleware)         // 2
+	echoHttp.Use(d.BodyLimitMiddleware())        // 2
 	echoHttp.Use(d.LimitOffsetSortPreMiddleware) // 1
 	// init group routes
 	grp := &common.Groups{
@@ -75,6 +102,8 @@ func (d *Dispatcher) Dispatch(echoHttp *echo.Echo) error {
 	d.authUserGroup(grp.AuthUser)
 	d.systemUserGroup(grp.SystemUser)
 	d.webHookGroup(grp.WebHooks)
+	d.commonGroup(grp.Common)
+	d.healthRoutes(echoHttp)
 	// init routes
 	for _, handler := range d.appSet.Handlers {
 		handler.Route(grp)
@@ -85,6 +114,44 @@ func (d *Dispatcher) Dispatch(echoHttp *echo.Echo) error {
 	return nil
 }
 
+// initStores creates the shared stores and the log redactor once, Dispatch may be called several times
+// for the same dispatcher
+func (d *Dispatcher) initStores() (err error) {
+	if d.idempotency == nil {
+		if d.idempotency, err = idempotency.NewStore(&d.cfg.Idempotency, d.appSet.Redis); err != nil {
+			return err
+		}
+	}
+	if d.rateLimiter == nil {
+		store, err := ratelimit.NewStore(&d.cfg.RateLimit, d.appSet.Redis)
+		if err != nil {
+			return err
+		}
+		if d.rateLimiter, err = ratelimit.NewLimiter(&d.cfg.RateLimit, store, defaultRateLimits); err != nil {
+			return err
+		}
+	}
+	if d.redactor == nil {
+		if d.redactor, err = redact.New(&d.cfg.Redaction); err != nil {
+			return err
+		}
+	}
+	if d.callbacks == nil {
+		store := d.idempotency
+		// the processed callbacks must be known to all replicas and survive the restarts to reject the replays
+		if d.cfg.Callbacks.Enabled && d.cfg.Callbacks.ReplayWindow > 0 {
+			if d.appSet.Redis == nil {
+				return errors.New("callback replay window requires redis settings")
+			}
+			store = idempotency.NewRedisStore(d.appSet.Redis)
+		}
+		if d.callbacks, err = callbackverify.New(&d.cfg.Callbacks, store, defaultCallbackProviders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *Dispatcher) dumpRoutesToFile(echoHttp *echo.Echo) {
 
 	var list []string
@@ -144,7 +211,8 @@ func (d *Dispatcher) authProjectGroup(grp *echo.Group) {
 	if !d.globalCfg.DisableAuthMiddleware {
 		grp.Use(d.GetUserDetailsMiddleware) // 1
 	}
-	grp.Use(d.SystemBinderPreMiddleware) // 2
+	grp.Use(d.SystemBinderPreMiddleware)                       // 2
+	grp.Use(d.RateLimitMiddleware(ratelimit.GroupAuthProject)) // 3
 }
 
 func (d *Dispatcher) accessGroup(grp *echo.Group) {
@@ -155,14 +223,22 @@ func (d *Dispatcher) accessGroup(grp *echo.Group) {
 func (d *Dispatcher) authUserGroup(grp *echo.Group) {
 	// Called before routes
 	if !d.globalCfg.DisableAuthMiddleware {
-		grp.Use(d.GetUserDetailsMiddleware)       // 1
-		grp.Use(d.AuthOneMerchantPreMiddleware()) // 2
+		grp.Use(d.ApiKeyMiddleware)               // 1
+		grp.Use(d.GetUserDetailsMiddleware)       // 2
+		grp.Use(d.AuthOneMerchantPreMiddleware()) // 3
 		grp.Use(d.CasbinMiddleware(func(c echo.Context) string {
 			user := common.ExtractUserContext(c)
-			return fmt.Sprintf(billingpb.CasbinMerchantUserMask, user.MerchantId, user.Id)
-		})) // 3
+			userId := user.Id
+			// api key can't be used beyond permissions of the user who created it
+			if key := common.ExtractApiKeyContext(c); key != nil {
+				userId = key.UserId
+			}
+			return fmt.Sprintf(billingpb.CasbinMerchantUserMask, user.MerchantId, userId)
+		})) // 4
 	}
-	grp.Use(d.MerchantBinderPreMiddleware) // 3
+	grp.Use(d.MerchantBinderPreMiddleware)                  // 3
+	grp.Use(d.RateLimitMiddleware(ratelimit.GroupAuthUser)) // 4
+	grp.Use(d.IdempotencyMiddleware())                      // 5
 }
 
 func (d *Dispatcher) systemUserGroup(grp *echo.Group) {
@@ -174,12 +250,23 @@ func (d *Dispatcher) systemUserGroup(grp *echo.Group) {
 			return user.Id
 		})) // 2
 	}
-	grp.Use(d.SystemBinderPreMiddleware) // 3
+	grp.Use(d.SystemBinderPreMiddleware)                      // 3
+	grp.Use(d.RateLimitMiddleware(ratelimit.GroupSystemUser)) // 4
+	grp.Use(d.IdempotencyMiddleware())                        // 5
 }
 
 func (d *Dispatcher) webHookGroup(grp *echo.Group) {
 	// Called after routes
-	grp.Use(d.BodyDumpMiddleware()) // 1
+	grp.Use(d.BodyDumpMiddleware())                         // 1
+	grp.Use(d.RateLimitMiddleware(ratelimit.GroupWebHooks)) // 2
+	grp.Use(d.RawBodyMiddleware())                          // 3
+	grp.Use(d.CallbackVerifyMiddleware())                   // 4
+}
+
+func (d *Dispatcher) commonGroup(grp *echo.Group) {
+	// Called before routes
+	grp.Use(d.RateLimitMiddleware(ratelimit.GroupCommon)) // 1
+	grp.Use(d.RawBodyMiddleware(rawBodyRoutes...))        // 2
 }
 
 // Config
@@ -187,6 +274,15 @@ type Config struct {
 	Debug         bool `fallback:"shared.debug"`
 	WorkDir       string
 	PathRouteDump string
+	Redis         redis.Config
+	Idempotency   idempotency.Config
+	ApiKeys       apikey.Config
+	Audit         audit.Config
+	RateLimit     ratelimit.Config
+	BodyLimit     bodylimit.Config
+	Redaction     redact.Config
+	Callbacks     callbackverify.Config
+	Health        health.Config
 	invoker       *invoker.Invoker
 }
 
@@ -205,6 +301,9 @@ type AppSet struct {
 	Handlers    common.Handlers
 	Services    common.Services
 	JwtVerifier *jwtverifier.JwtVerifier
+	AuthCache   *common.AuthCache
+	Redis       *redis.Client
+	ApiKeys     *apikey.Registry
 }
 
 // New
@@ -217,5 +316,6 @@ func New(ctx context.Context, set provider.AwareSet, appSet AppSet, cfg *Config,
 		LMT:       &set,
 		globalCfg: globalCfg,
 		ms:        ms,
+		tracer:    set.Tracer,
 	}
 }
@@ -0,0 +1,118 @@
+package dispatcher
+
+import (
+	geoip "github.com/ProtocolONE/geoip-service/pkg"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/health"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/paysuper/paysuper-proto/go/recurringpb"
+	"github.com/paysuper/paysuper-proto/go/reporterpb"
+	"github.com/paysuper/paysuper-proto/go/taxpb"
+	"net/http"
+)
+
+const (
+	healthCheckBilling     = "billing"
+	healthCheckTax         = "tax"
+	healthCheckReporter    = "reporter"
+	healthCheckGeoIp       = "geoip"
+	healthCheckRecurring   = "recurring"
+	healthCheckS3Agreement = "s3_agreement"
+	healthCheckS3Reporter  = "s3_reporter"
+	healthCheckCloudWatch  = "cloudwatch"
+	healthCheckRedis       = "redis"
+)
+
+// newHealthChecker creates the checker of the dependencies required to serve the requests, the checks are
+// registered on the readiness request because the creation of the micro client parses the command line
+func (d *Dispatcher) newHealthChecker() *health.Checker {
+	cfg := d.cfg.Health
+
+	// the outage of the other dependencies breaks a few routes only, it mustn't take all replicas out of service
+	if len(cfg.Critical) == 0 {
+		cfg.Critical = []string{healthCheckBilling}
+	}
+
+	return health.NewChecker(&cfg).Lazy(d.registerHealthChecks)
+}
+
+func (d *Dispatcher) registerHealthChecks(checker *health.Checker) error {
+	cl := d.ms.Client("", "")
+
+	checker.
+		Add(healthCheckBilling, health.ServiceCheck(cl, billingpb.ServiceName)).
+		Add(healthCheckTax, health.ServiceCheck(cl, taxpb.ServiceName)).
+		Add(healthCheckReporter, health.ServiceCheck(cl, reporterpb.ServiceName)).
+		Add(healthCheckGeoIp, health.ServiceCheck(cl, geoip.ServiceName)).
+		Add(healthCheckRecurring, health.ServiceCheck(cl, recurringpb.PayOneRepositoryServiceName))
+
+	s3Agreement, err := health.S3Check(&health.AwsCredentials{
+		AccessKeyId:     d.globalCfg.AwsAccessKeyIdAgreement,
+		SecretAccessKey: d.globalCfg.AwsSecretAccessKeyAgreement,
+		Region:          d.globalCfg.AwsRegionAgreement,
+	}, d.globalCfg.AwsBucketAgreement)
+	if err != nil {
+		return err
+	}
+
+	s3Reporter, err := health.S3Check(&health.AwsCredentials{
+		AccessKeyId:     d.globalCfg.AwsAccessKeyIdReporter,
+		SecretAccessKey: d.globalCfg.AwsSecretAccessKeyReporter,
+		Region:          d.globalCfg.AwsRegionReporter,
+	}, d.globalCfg.AwsBucketReporter)
+	if err != nil {
+		return err
+	}
+
+	checker.
+		Add(healthCheckS3Agreement, s3Agreement).
+		Add(healthCheckS3Reporter, s3Reporter)
+
+	if logs := d.globalCfg.LogsSettings; logs != nil && d.globalCfg.OrderLogs.IsCloudWatch() {
+		cloudWatch, err := health.CloudWatchCheck(&health.AwsCredentials{
+			AccessKeyId:     logs.AwsCloudWatchAccessKeyId,
+			SecretAccessKey: logs.AwsCloudWatchSecretAccessKey,
+			Region:          logs.AwsCloudWatchRegion,
+		})
+		if err != nil {
+			return err
+		}
+		checker.Add(healthCheckCloudWatch, cloudWatch)
+	}
+
+	// the api keys, the audit records and the report file owners are stored in redis if it's configured
+	if d.appSet.Redis != nil {
+		checker.Add(healthCheckRedis, d.appSet.Redis.Ping)
+	}
+
+	return nil
+}
+
+// healthRoutes are registered out of the route groups to skip the authentication and the rate limits
+func (d *Dispatcher) healthRoutes(echoHttp *echo.Echo) {
+	if d.health == nil {
+		d.health = d.newHealthChecker()
+	}
+
+	echoHttp.GET(common.HealthPath, d.liveness)
+	echoHttp.GET(common.ReadinessPath, d.readiness)
+}
+
+// liveness reports the process is able to serve the requests, the dependencies aren't checked
+// to avoid the restarts caused by the outage of a downstream service
+func (d *Dispatcher) liveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{"status": health.StatusOk})
+}
+
+// readiness returns 503 if any critical dependency isn't reachable or the checks failed to be registered
+func (d *Dispatcher) readiness(ctx echo.Context) error {
+	report := d.health.Run()
+	status := http.StatusOK
+
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	return ctx.JSON(status, report)
+}
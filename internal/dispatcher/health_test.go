@@ -0,0 +1,82 @@
+package dispatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Readiness(t *testing.T) {
+	failed := func(context.Context) error {
+		return errors.New("connection refused")
+	}
+	ok := func(context.Context) error {
+		return nil
+	}
+
+	cases := []struct {
+		name     string
+		register func(checker *health.Checker) error
+		code     int
+		status   string
+	}{
+		{
+			name: "ready",
+			register: func(checker *health.Checker) error {
+				checker.Add(healthCheckBilling, ok).Add(healthCheckRedis, ok)
+				return nil
+			},
+			code:   http.StatusOK,
+			status: health.StatusOk,
+		},
+		{
+			name: "degraded",
+			register: func(checker *health.Checker) error {
+				checker.Add(healthCheckBilling, ok).Add(healthCheckRedis, failed)
+				return nil
+			},
+			code:   http.StatusOK,
+			status: health.StatusDegraded,
+		},
+		{
+			name: "billing failed",
+			register: func(checker *health.Checker) error {
+				checker.Add(healthCheckBilling, failed).Add(healthCheckRedis, ok)
+				return nil
+			},
+			code:   http.StatusServiceUnavailable,
+			status: health.StatusUnavailable,
+		},
+		{
+			name: "checks not registered",
+			register: func(*health.Checker) error {
+				return errors.New("invalid aws region")
+			},
+			code:   http.StatusServiceUnavailable,
+			status: health.StatusUnavailable,
+		},
+	}
+
+	for _, c := range cases {
+		d := &Dispatcher{cfg: Config{Health: health.Config{Timeout: time.Second}}}
+		d.health = d.newHealthChecker().Lazy(c.register)
+
+		rsp := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, common.ReadinessPath, nil), rsp)
+		require.NoError(t, d.readiness(ctx), c.name)
+
+		report := &health.Report{}
+		require.NoError(t, json.Unmarshal(rsp.Body.Bytes(), report), c.name)
+		assert.Equal(t, c.code, rsp.Code, c.name)
+		assert.Equal(t, c.status, report.Status, c.name)
+	}
+}
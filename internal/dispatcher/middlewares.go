@@ -1,19 +1,114 @@
 package dispatcher
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	jwtverifier "github.com/ProtocolONE/authone-jwt-verifier-golang"
 	jwtMiddleware "github.com/ProtocolONE/authone-jwt-verifier-golang/middleware/echo"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	casbinMiddleware "github.com/paysuper/echo-casbin-middleware"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/bodylimit"
+	"github.com/paysuper/paysuper-management-api/internal/callbackverify"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/i18n"
+	"github.com/paysuper/paysuper-management-api/internal/idempotency"
+	"github.com/paysuper/paysuper-management-api/internal/ratelimit"
+	"github.com/paysuper/paysuper-management-api/pkg/metrics"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	idempotencyKeyMaxLength = 255
+)
+
+var (
+	// Path prefixes of the groups used as the metric labels
+	routeGroups = []struct{ prefix, name string }{
+		{common.AuthProjectGroupPath, ratelimit.GroupAuthProject},
+		{common.AuthUserGroupPath, ratelimit.GroupAuthUser},
+		{common.SystemUserGroupPath, ratelimit.GroupSystemUser},
+		{common.WebHookGroupPath, ratelimit.GroupWebHooks},
+		{common.NoAuthGroupPath, ratelimit.GroupCommon},
+	}
+
+	// Route templates protected by the Idempotency-Key header when the config doesn't override them
+	defaultIdempotentRoutes = []string{
+		common.AuthUserGroupPath + "/order/:order_id/refunds",
+		common.SystemUserGroupPath + "/order/:order_id/refunds",
+		common.AuthUserGroupPath + "/payout_documents",
+		common.AuthUserGroupPath + "/paylinks",
+		common.AuthUserGroupPath + "/order/:order_id/webhook/resend",
+		common.AuthUserGroupPath + "/order/webhook/resend",
+	}
+
+	// Route templates reading the raw body besides the webhooks
+	rawBodyRoutes = []string{
+		common.NoAuthGroupPath + "/tokens",
+	}
+
+	// Body limits of the routes when the config doesn't override them
+	defaultBodyLimits = []bodylimit.RouteLimit{
+		{
+			Method: http.MethodPost,
+			Path:   common.AuthUserGroupPath + "/key-products/:key_product_id/platforms/:platform_id/file",
+			Limit:  32 << 20,
+		},
+	}
+
+	// Callback settings of the payment systems merged with the config ones, the secrets and the allowed addresses
+	// are set by the config only
+	defaultCallbackProviders = map[string]callbackverify.Provider{
+		"cardpay": {
+			SignatureHeader: common.CardPayPaymentResponseHeaderSignature,
+			Algorithm:       callbackverify.AlgorithmSha512,
+			IdFields:        []string{"payment_data.id", "refund_data.id", "recurring_data.id"},
+		},
+		"sample": {
+			SignatureHeader: "X-Signature",
+			Algorithm:       callbackverify.AlgorithmHmacSha256,
+			IdFields:        []string{"id"},
+		},
+	}
+
+	// Rate limits of the routes when the config doesn't override them
+	defaultRateLimits = []ratelimit.RouteRule{
+		{
+			Method:   http.MethodPost,
+			Path:     common.AuthUserGroupPath + "/order/:order_id/webhook/resend",
+			Requests: 10,
+			Period:   time.Minute,
+			Key:      ratelimit.KeyMerchant,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     common.AuthUserGroupPath + "/order/webhook/resend",
+			Requests: 5,
+			Period:   time.Hour,
+			Key:      ratelimit.KeyMerchant,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     common.AuthUserGroupPath + "/projects/:id/webhook/testing/suite",
+			Requests: 10,
+			Period:   time.Hour,
+			Key:      ratelimit.KeyMerchant,
+		},
+	}
 )
 
 // RecoverMiddleware
@@ -35,9 +130,133 @@ func (d *Dispatcher) RecoverMiddleware() echo.MiddlewareFunc {
 	}
 }
 
+// ValidationErrorsMiddleware replaces the first failed field error with the errors of all failed fields,
+// the format is opt-in to keep the response compatible with the existing clients
+func (d *Dispatcher) ValidationErrorsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+
+		if err == nil || !strings.EqualFold(c.Request().Header.Get(common.HeaderXValidationErrors), common.ValidationErrorsAll) {
+			return err
+		}
+
+		httpErr, ok := err.(*echo.HTTPError)
+		if !ok || httpErr.Internal == nil {
+			return err
+		}
+
+		res := common.NewValidationErrorsResponse(httpErr.Internal)
+		if res == nil {
+			return err
+		}
+
+		return &echo.HTTPError{Code: httpErr.Code, Message: res, Internal: httpErr.Internal}
+	}
+}
+
+// LocalizationMiddleware translates the management api error messages to the language requested by
+// Accept-Language header, the english messages are returned for the unsupported languages
+func (d *Dispatcher) LocalizationMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+
+		if err == nil {
+			return err
+		}
+
+		httpErr, ok := err.(*echo.HTTPError)
+		if !ok {
+			return err
+		}
+
+		c.Response().Header().Add(echo.HeaderVary, common.HeaderAcceptLanguage)
+		locale := i18n.ParseAcceptLanguage(c.Request().Header.Get(common.HeaderAcceptLanguage))
+
+		if locale == i18n.DefaultLocale {
+			return err
+		}
+
+		msg, ok := common.LocalizeMessage(locale, httpErr.Message, httpErr.Internal)
+		if !ok {
+			return err
+		}
+
+		c.Response().Header().Set(common.HeaderContentLanguage, locale)
+		return &echo.HTTPError{Code: httpErr.Code, Message: msg, Internal: httpErr.Internal}
+	}
+}
+
+// MetricsMiddleware records count, latency and errors of the requests by the route template
+func (d *Dispatcher) MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+		metrics.ObserveRequest(routeGroup(c.Path()), c.Request().Method, c.Path(), responseStatus(c, err), time.Since(start))
+		return err
+	}
+}
+
+// TracingMiddleware starts the server span of the request, continues the trace of the caller if there is one
+func (d *Dispatcher) TracingMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		opts := []opentracing.StartSpanOption{ext.SpanKindRPCServer}
+
+		if parent, err := d.tracer.Extract(opentracing.HTTPHeaders, common.TraceCarrier(req.Header)); err == nil {
+			opts = append(opts, opentracing.ChildOf(parent))
+		}
+
+		span := d.tracer.StartSpan("HTTP "+req.Method+" "+c.Path(), opts...)
+		defer span.Finish()
+
+		ext.HTTPMethod.Set(span, req.Method)
+		ext.HTTPUrl.Set(span, req.URL.Path)
+		span.SetTag("http.route", c.Path())
+
+		// the access log prints the header so the value sent by the client must be overwritten
+		req.Header.Set(common.HeaderXTraceId, common.TraceId(d.tracer, span.Context()))
+		c.SetRequest(req.WithContext(opentracing.ContextWithSpan(req.Context(), span)))
+
+		err := next(c)
+		status := responseStatus(c, err)
+		ext.HTTPStatusCode.Set(span, uint16(status))
+
+		if status >= http.StatusInternalServerError {
+			ext.Error.Set(span, true)
+		}
+
+		return err
+	}
+}
+
+// AccessLogRedactionMiddleware masks the credentials in the query string of the request uri written to the access log,
+// the handlers read the parsed url, so the request uri is used by the access log only
+func (d *Dispatcher) AccessLogRedactionMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		req.RequestURI = d.redactor.RequestUri(req.RequestURI)
+		return next(c)
+	}
+}
+
+// responseStatus returns the status of the error because the error isn't written to the response yet
+func responseStatus(c echo.Context, err error) int {
+	if err == nil {
+		return c.Response().Status
+	}
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
 // GetUserDetailsMiddleware
 func (d *Dispatcher) GetUserDetailsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
+		if common.ExtractApiKeyContext(ctx) != nil {
+			return next(ctx)
+		}
+
 		auth := ctx.Request().Header.Get(echo.HeaderAuthorization)
 
 		if auth == "" {
@@ -50,10 +269,17 @@ func (d *Dispatcher) GetUserDetailsMiddleware(next echo.HandlerFunc) echo.Handle
 			return echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMessageAuthorizationTokenNotFound.Message)
 		}
 
-		u, err := d.appSet.JwtVerifier.GetUserInfo(ctx.Request().Context(), match[1])
+		u, ok := d.appSet.AuthCache.GetUserInfo(match[1])
 
-		if err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMessageAuthorizedUserNotFound.Message)
+		if !ok {
+			var err error
+			u, err = d.appSet.JwtVerifier.GetUserInfo(ctx.Request().Context(), match[1])
+
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, common.ErrorMessageAuthorizedUserNotFound.Message)
+			}
+
+			d.appSet.AuthCache.SetUserInfo(match[1], u)
 		}
 
 		user := common.ExtractUserContext(ctx)
@@ -97,14 +323,57 @@ func (d *Dispatcher) LimitOffsetSortPreMiddleware(next echo.HandlerFunc) echo.Ha
 	}
 }
 
-// RawBodyPreMiddleware
-func (d *Dispatcher) RawBodyPreMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		buf, _ := ioutil.ReadAll(c.Request().Body)
-		rdr := ioutil.NopCloser(bytes.NewBuffer(buf))
-		c.Request().Body = rdr
-		common.SetRawBodyContext(c, buf)
-		return next(c)
+// BodyLimitMiddleware rejects the request with 413 if the body is larger than the limit of the route or the group,
+// the body is checked while it's read, so the handlers streaming the body aren't affected by the limit of other routes
+func (d *Dispatcher) BodyLimitMiddleware() echo.MiddlewareFunc {
+	limits := bodylimit.NewLimits(&d.cfg.BodyLimit, defaultBodyLimits)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			limit := limits.Limit(routeGroup(c.Path()), req.Method, c.Path())
+
+			if limit <= 0 || req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			if req.ContentLength > limit {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, common.ErrorRequestBodyTooLarge)
+			}
+
+			body := bodylimit.NewReader(req.Body, limit)
+			req.Body = body
+			err := next(c)
+
+			if body.Exceeded() && !c.Response().Committed {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, common.ErrorRequestBodyTooLarge)
+			}
+
+			return err
+		}
+	}
+}
+
+// RawBodyMiddleware keeps the raw body in the context for the listed route templates, e.g. to check the request
+// signature, the body of all routes of the group is kept if no routes are listed
+func (d *Dispatcher) RawBodyMiddleware(routes ...string) echo.MiddlewareFunc {
+	paths := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		paths[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(paths) > 0 && !paths[c.Path()] {
+				return next(c)
+			}
+
+			if _, err := captureRawBody(c); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestDataInvalid)
+			}
+
+			return next(c)
+		}
 	}
 }
 
@@ -119,14 +388,94 @@ func (d *Dispatcher) CasbinMiddleware(fn func(c echo.Context) string) echo.Middl
 	return casbinMiddleware.MiddlewareWithConfig(d.ms.Client("", ""), cfg)
 }
 
-// BodyDumpMiddleware
+// CallbackVerifyMiddleware rejects the payment system callbacks from the unknown addresses, with the invalid
+// signatures and the replays of the accepted ones before they reach the billing
+func (d *Dispatcher) CallbackVerifyMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !d.callbacks.Enabled() {
+				return next(c)
+			}
+
+			req := c.Request()
+			rawBody, err := captureRawBody(c)
+
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestDataInvalid)
+			}
+
+			ip := clientIp(c, d.callbacks.TrustProxyHeaders())
+			res, err := d.callbacks.Verify(req.Context(), c.Param("provider"), ip, req.Header, rawBody)
+
+			if err != nil {
+				d.L().Error("callback verification failed", logger.PairArgs("err", err.Error(), "path", c.Path()))
+				return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+			}
+
+			if res == nil {
+				return next(c)
+			}
+
+			if res.Reason != "" {
+				metrics.ObserveRejectedCallback(res.Provider, res.Reason)
+				d.L().Info(
+					"callback rejected",
+					logger.PairArgs(
+						"provider", res.Provider,
+						"reason", res.Reason,
+						"callback_id", res.CallbackId,
+						"path", c.Path(),
+						"remote_ip", ip,
+					),
+				)
+
+				switch res.Reason {
+				case callbackverify.ReasonIpNotAllowed:
+					return echo.NewHTTPError(http.StatusForbidden, common.ErrorCallbackIpNotAllowed)
+				case callbackverify.ReasonReplay:
+					// the payment system stops the retries of the callback on the successful response
+					return c.JSON(http.StatusOK, map[string]string{"message": "callback is already processed"})
+				case callbackverify.ReasonInProgress:
+					// the first callback may still fail, so the payment system must retry the duplicate
+					return echo.NewHTTPError(http.StatusConflict, common.ErrorCallbackBusy)
+				}
+
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorCallbackSignatureInvalid)
+			}
+
+			err = next(c)
+
+			if res.ReplayKey == "" {
+				return err
+			}
+
+			var e error
+
+			// the callback isn't processed, so the retry of the payment system must be accepted
+			if err != nil || c.Response().Status >= http.StatusMultipleChoices {
+				e = d.callbacks.Release(d.ctx, res.ReplayKey)
+			} else {
+				e = d.callbacks.Complete(d.ctx, res.ReplayKey)
+			}
+
+			if e != nil {
+				d.L().Error("callback verification failed", logger.PairArgs("err", e.Error(), "path", c.Path()))
+			}
+
+			return err
+		}
+	}
+}
+
+// BodyDumpMiddleware logs the headers and the bodies of the request and the response, the personal data and
+// the secrets are redacted before logging
 func (d *Dispatcher) BodyDumpMiddleware() echo.MiddlewareFunc {
 	return middleware.BodyDump(func(ctx echo.Context, reqBody, resBody []byte) {
 		data := map[string]interface{}{
-			"request_headers":  common.RequestResponseHeadersToString(ctx.Request().Header),
-			"request_body":     string(reqBody),
-			"response_headers": common.RequestResponseHeadersToString(ctx.Response().Header()),
-			"response_body":    string(resBody),
+			"request_headers":  common.RequestResponseHeadersToString(d.redactor.Headers(ctx.Request().Header)),
+			"request_body":     d.redactor.Body(reqBody),
+			"response_headers": common.RequestResponseHeadersToString(d.redactor.Headers(ctx.Response().Header())),
+			"response_body":    d.redactor.Body(resBody),
 		}
 		d.L().Info(ctx.Path(), logger.WithFields(data))
 	})
@@ -157,32 +506,387 @@ func (d *Dispatcher) SystemBinderPreMiddleware(next echo.HandlerFunc) echo.Handl
 // AuthOneMerchantPreMiddleware
 func (d *Dispatcher) AuthOneMerchantPreMiddleware() echo.MiddlewareFunc {
 	return common.ContextWrapperCallback(func(c echo.Context, next echo.HandlerFunc) error {
+		if common.ExtractApiKeyContext(c) != nil {
+			return next(c)
+		}
+
 		handleFn := jwtMiddleware.AuthOneJwtCallableWithConfig(
 			d.appSet.JwtVerifier,
-			func(ui *jwtverifier.UserInfo) {
-				user := common.ExtractUserContext(c)
-				user.Name = "Merchant User"
+			func(ui *jwtverifier.UserInfo) {},
+		)(d.MerchantUserMiddleware(next))
+		return handleFn(c)
+	})
+}
+
+// MerchantUserMiddleware sets the merchant and the role of the authenticated user, the merchant is chosen
+// by the X-Merchant-Id header or the merchant_id query parameter, the first merchant of the user is used if none
+// is requested
+func (d *Dispatcher) MerchantUserMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user := common.ExtractUserContext(c)
+		user.Name = "Merchant User"
+
+		res, err := d.getMerchantsForUser(c, user.Id)
 
-				res, err := d.appSet.Services.Billing.GetMerchantsForUser(
-					c.Request().Context(),
-					&billingpb.GetMerchantsForUserRequest{UserId: user.Id},
+		if err != nil {
+			d.L().Error(c.Path(), logger.Args(err.Error()), logger.Stack("stacktrace"))
+			return next(c)
+		}
+
+		if len(res.Merchants) < 1 {
+			d.L().Error(c.Path(), logger.Args("user_id", user.Id))
+			return next(c)
+		}
+
+		idx := selectMerchant(res, requestedMerchantId(c))
+
+		if idx < 0 {
+			return echo.NewHTTPError(http.StatusForbidden, common.ErrorMessageMerchantNotAllowed)
+		}
+
+		user.Role = res.Merchants[idx].Role
+		user.MerchantId = res.Merchants[idx].Id
+		common.SetUserContext(c, user)
+
+		return next(c)
+	}
+}
+
+// ApiKeyMiddleware authenticates requests with the "Authorization: ApiKey ..." header as the synthetic user of the key,
+// the requests with any other authorization are passed to the next middleware as is
+func (d *Dispatcher) ApiKeyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		match := common.ApiKeyRegex.FindStringSubmatch(req.Header.Get(echo.HeaderAuthorization))
+
+		// the keys are disabled without redis, the header is left to the user authentication
+		if len(match) < 2 || d.appSet.ApiKeys == nil {
+			return next(c)
+		}
+
+		key, err := d.appSet.ApiKeys.Authenticate(req.Context(), match[1])
+
+		if err == apikey.ErrKeyInvalid {
+			return echo.NewHTTPError(http.StatusUnauthorized, common.ErrorApiKeyInvalid)
+		}
+
+		if err != nil {
+			d.L().Error("api key authentication failed", logger.PairArgs("err", err.Error(), "path", c.Path()))
+			return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		}
+
+		if !d.appSet.ApiKeys.Allowed(key, req.Method, req.URL.Path) {
+			return echo.NewHTTPError(http.StatusForbidden, common.ErrorApiKeyRouteNotAllowed)
+		}
+
+		res, err := d.getMerchantsForUser(c, key.UserId)
+
+		if err != nil {
+			d.L().Error(c.Path(), logger.Args(err.Error()), logger.Stack("stacktrace"))
+			return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
+		}
+
+		// the key is valid as long as its owner is still a user of the merchant
+		idx := selectMerchant(res, key.MerchantId)
+
+		if idx < 0 {
+			return echo.NewHTTPError(http.StatusUnauthorized, common.ErrorApiKeyInvalid)
+		}
+
+		common.SetApiKeyContext(c, key)
+		common.SetUserContext(c, &common.AuthUser{
+			Id:         key.SyntheticUserId(),
+			Name:       key.Name,
+			Role:       res.Merchants[idx].Role,
+			MerchantId: key.MerchantId,
+		})
+
+		return next(c)
+	}
+}
+
+// getMerchantsForUser
+func (d *Dispatcher) getMerchantsForUser(c echo.Context, userId string) (*billingpb.GetMerchantsForUserResponse, error) {
+	if res, ok := d.appSet.AuthCache.GetMerchants(userId); ok {
+		return res, nil
+	}
+
+	res, err := d.appSet.Services.Billing.GetMerchantsForUser(
+		c.Request().Context(),
+		&billingpb.GetMerchantsForUserRequest{UserId: userId},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Merchants) > 0 {
+		d.appSet.AuthCache.SetMerchants(userId, res)
+	}
+
+	return res, nil
+}
+
+// RateLimitMiddleware takes a token from the bucket of the route or the group rule and rejects the request if the bucket is empty
+func (d *Dispatcher) RateLimitMiddleware(group string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id, rule := d.rateLimiter.Rule(group, c.Request().Method, c.Path())
+
+			if !rule.Enabled() {
+				return next(c)
+			}
+
+			value := rateLimitValue(c, rule.Key, d.cfg.RateLimit.TrustProxyHeaders)
+			res, err := d.rateLimiter.Take(c.Request().Context(), id, value, rule)
+
+			// the limiter must not take the api down together with the shared store
+			if err != nil {
+				d.L().Error("rate limit store call failed", logger.PairArgs("err", err.Error(), "path", c.Path()))
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set(common.HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			h.Set(common.HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			h.Set(common.HeaderRateLimitReset, strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				h.Set(common.HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				return echo.NewHTTPError(http.StatusTooManyRequests, common.ErrorRateLimitExceeded)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// rateLimitValue falls back to the client IP for the requests without an authorized user
+func rateLimitValue(c echo.Context, key string, trustProxyHeaders bool) string {
+	user := common.ExtractUserContext(c)
+
+	switch {
+	case key == ratelimit.KeyMerchant && user.MerchantId != "":
+		return user.MerchantId
+	case key == ratelimit.KeyUser && user.Id != "":
+		return user.Id
+	}
+
+	return clientIp(c, trustProxyHeaders)
+}
+
+// clientIp returns the host of the connection address unless the proxy headers are trusted, as any client
+// is able to send them
+func clientIp(c echo.Context, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		return c.RealIP()
+	}
+
+	req := c.Request()
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return ip
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// captureRawBody reads the body once and replaces it with the copy to be read again by the binder
+func captureRawBody(c echo.Context) ([]byte, error) {
+	if rawBody := common.ExtractRawBodyContext(c); rawBody != nil {
+		return rawBody, nil
+	}
+
+	req := c.Request()
+	rawBody := []byte{}
+
+	if req.Body != nil {
+		buf, err := ioutil.ReadAll(req.Body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rawBody = buf
+		req.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
+	}
+
+	common.SetRawBodyContext(c, rawBody)
+	return rawBody, nil
+}
+
+func routeGroup(path string) string {
+	for _, group := range routeGroups {
+		if strings.HasPrefix(path, group.prefix) {
+			return group.name
+		}
+	}
+	return ""
+}
+
+// requestedMerchantId returns the merchant chosen by the client, the header takes precedence over the query parameter
+func requestedMerchantId(c echo.Context) string {
+	if id := c.Request().Header.Get(common.HeaderXMerchantId); id != "" {
+		return id
+	}
+	return c.QueryParam(common.QueryParameterNameMerchantId)
+}
+
+// selectMerchant returns index of the requested merchant in the user's merchants list, the first one is used
+// if nothing was requested and -1 means the user doesn't belong to the requested merchant
+func selectMerchant(res *billingpb.GetMerchantsForUserResponse, merchantId string) int {
+	if merchantId == "" {
+		return 0
+	}
+	for i, merchant := range res.Merchants {
+		if merchant.Id == merchantId {
+			return i
+		}
+	}
+	return -1
+}
+
+// IdempotencyMiddleware replays the first response to requests retried with the same Idempotency-Key header
+func (d *Dispatcher) IdempotencyMiddleware() echo.MiddlewareFunc {
+	routes := d.cfg.Idempotency.Routes
+	if len(routes) == 0 {
+		routes = defaultIdempotentRoutes
+	}
+	paths := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		paths[route] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(common.HeaderIdempotencyKey)
+
+			if key == "" || req.Method != http.MethodPost || !paths[c.Path()] {
+				return next(c)
+			}
+
+			if len(key) > idempotencyKeyMaxLength {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorIdempotencyKeyIncorrect)
+			}
+
+			user := common.ExtractUserContext(c)
+			storeKey := hashParts(user.MerchantId, user.Id, key)
+			rawBody, err := captureRawBody(c)
+
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestDataInvalid)
+			}
+
+			fingerprint := hashParts(req.Method, req.URL.Path, string(rawBody))
+
+			record, err := d.idempotency.Get(req.Context(), storeKey)
+
+			if err != nil {
+				d.L().Error("idempotency store call failed", logger.PairArgs("err", err.Error(), "path", c.Path()))
+				return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+			}
+
+			if record == nil {
+				reserved, err := d.idempotency.Reserve(
+					req.Context(),
+					storeKey,
+					&idempotency.Record{Fingerprint: fingerprint},
+					d.cfg.Idempotency.LockTtl,
 				)
 
 				if err != nil {
-					d.L().Error(c.Path(), logger.Args(err.Error()), logger.Stack("stacktrace"))
-					return
+					d.L().Error("idempotency store call failed", logger.PairArgs("err", err.Error(), "path", c.Path()))
+					return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
 				}
 
-				if len(res.Merchants) < 1 {
-					d.L().Error(c.Path(), logger.Args("user_id", user.Id))
-					return
+				if reserved {
+					return d.idempotentCall(c, next, storeKey, fingerprint)
 				}
 
-				user.Role = res.Merchants[0].Role
-				user.MerchantId = res.Merchants[0].Id
-				common.SetUserContext(c, user)
-			},
-		)(next)
-		return handleFn(c)
-	})
+				// another request with the same key has won the race
+				if record, err = d.idempotency.Get(req.Context(), storeKey); err != nil || record == nil {
+					return echo.NewHTTPError(http.StatusConflict, common.ErrorIdempotencyRequestInProgress)
+				}
+			}
+
+			if record.Fingerprint != fingerprint {
+				return echo.NewHTTPError(http.StatusUnprocessableEntity, common.ErrorIdempotencyKeyReused)
+			}
+
+			if !record.Completed {
+				return echo.NewHTTPError(http.StatusConflict, common.ErrorIdempotencyRequestInProgress)
+			}
+
+			c.Response().Header().Set(common.HeaderIdempotentReplayed, "true")
+			return c.Blob(record.Status, record.ContentType, record.Body)
+		}
+	}
+}
+
+func (d *Dispatcher) idempotentCall(c echo.Context, next echo.HandlerFunc, key, fingerprint string) error {
+	buf := new(bytes.Buffer)
+	rsp := c.Response()
+	rsp.Writer = &bodyCaptureWriter{Writer: io.MultiWriter(rsp.Writer, buf), ResponseWriter: rsp.Writer}
+
+	err := next(c)
+
+	// errors are not remembered so the client is able to fix the request and retry it with the same key
+	if err != nil || rsp.Status >= http.StatusInternalServerError {
+		if e := d.idempotency.Delete(d.ctx, key); e != nil {
+			d.L().Error("idempotency store call failed", logger.PairArgs("err", e.Error(), "path", c.Path()))
+		}
+		return err
+	}
+
+	record := &idempotency.Record{
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Status:      rsp.Status,
+		ContentType: rsp.Header().Get(echo.HeaderContentType),
+		Body:        buf.Bytes(),
+	}
+
+	if e := d.idempotency.Save(d.ctx, key, record, d.cfg.Idempotency.Ttl); e != nil {
+		d.L().Error("idempotency store call failed", logger.PairArgs("err", e.Error(), "path", c.Path()))
+	}
+
+	return nil
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type bodyCaptureWriter struct {
+	io.Writer
+	http.ResponseWriter
+}
+
+func (w *bodyCaptureWriter) WriteHeader(code int) {
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	return w.Writer.Write(b)
+}
+
+func (w *bodyCaptureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *bodyCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
 }
@@ -0,0 +1,324 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/idempotency"
+	"github.com/paysuper/paysuper-management-api/internal/ratelimit"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/paysuper/paysuper-proto/go/billingpb/mocks"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MerchantUserMiddlewareTestSuite struct {
+	suite.Suite
+	dispatcher *Dispatcher
+	billing    *mocks.BillingService
+}
+
+func Test_MerchantUserMiddleware(t *testing.T) {
+	suite.Run(t, new(MerchantUserMiddlewareTestSuite))
+}
+
+func (suite *MerchantUserMiddlewareTestSuite) SetupTest() {
+	suite.billing = &mocks.BillingService{}
+	suite.billing.On("GetMerchantsForUser", mock2.Anything, mock2.Anything).Return(&billingpb.GetMerchantsForUserResponse{
+		Status: billingpb.ResponseStatusOk,
+		Merchants: []*billingpb.MerchantForUserInfo{
+			{Id: "5e96c1f4ff5d7c9a3c8d1b97", Role: "merchant_owner"},
+			{Id: "5e96c1f4ff5d7c9a3c8d1b98", Role: "merchant_developer"},
+		},
+	}, nil)
+	suite.dispatcher = &Dispatcher{appSet: AppSet{Services: common.Services{Billing: suite.billing}}}
+}
+
+func (suite *MerchantUserMiddlewareTestSuite) TestMerchantUser_HeaderAbsent_FirstMerchant() {
+	user, err := suite.exec(func(req *http.Request) {})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "5e96c1f4ff5d7c9a3c8d1b97", user.MerchantId)
+	assert.Equal(suite.T(), "merchant_owner", user.Role)
+}
+
+func (suite *MerchantUserMiddlewareTestSuite) TestMerchantUser_HeaderAllowed() {
+	user, err := suite.exec(func(req *http.Request) {
+		req.Header.Set(common.HeaderXMerchantId, "5e96c1f4ff5d7c9a3c8d1b98")
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "5e96c1f4ff5d7c9a3c8d1b98", user.MerchantId)
+	assert.Equal(suite.T(), "merchant_developer", user.Role)
+}
+
+func (suite *MerchantUserMiddlewareTestSuite) TestMerchantUser_HeaderForeign_Forbidden() {
+	user, err := suite.exec(func(req *http.Request) {
+		req.Header.Set(common.HeaderXMerchantId, "5e96c1f4ff5d7c9a3c8d1b99")
+	})
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorMessageMerchantNotAllowed, httpErr.Message)
+	assert.Nil(suite.T(), user)
+}
+
+func (suite *MerchantUserMiddlewareTestSuite) TestMerchantUser_HeaderTakesPrecedenceOverQuery() {
+	user, err := suite.exec(func(req *http.Request) {
+		req.URL.RawQuery = common.QueryParameterNameMerchantId + "=5e96c1f4ff5d7c9a3c8d1b99"
+		req.Header.Set(common.HeaderXMerchantId, "5e96c1f4ff5d7c9a3c8d1b98")
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "5e96c1f4ff5d7c9a3c8d1b98", user.MerchantId)
+}
+
+// exec runs the middleware for the request of the authenticated user and returns the user passed to the handler
+func (suite *MerchantUserMiddlewareTestSuite) exec(init func(req *http.Request)) (*common.AuthUser, error) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	init(req)
+
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	common.SetUserContext(ctx, &common.AuthUser{Id: "ffffffffffffffffffffffff"})
+
+	var user *common.AuthUser
+	err := suite.dispatcher.MerchantUserMiddleware(func(c echo.Context) error {
+		user = common.ExtractUserContext(c)
+		return nil
+	})(ctx)
+
+	return user, err
+}
+
+func Test_AccessLogRedactionMiddleware(t *testing.T) {
+	redactor, err := redact.New(&redact.Config{})
+	require.NoError(t, err)
+	d := &Dispatcher{redactor: redactor}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/report_file/download/string.pdf?lang=en&token=secret", nil)
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+	err = d.AccessLogRedactionMiddleware(func(c echo.Context) error {
+		assert.Equal(t, "secret", c.QueryParam("token"))
+		return nil
+	})(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/report_file/download/string.pdf?lang=en&token="+redact.Mask, req.RequestURI)
+}
+
+func Test_ApiKeyMiddleware_Disabled(t *testing.T) {
+	d := &Dispatcher{}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/api/v1/projects", nil)
+	req.Header.Set(echo.HeaderAuthorization, "ApiKey psk_0123456789abcdef.secret")
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+	called := false
+	err := d.ApiKeyMiddleware(func(c echo.Context) error {
+		called = true
+		assert.Nil(t, common.ExtractApiKeyContext(c))
+		return nil
+	})(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func Test_IdempotencyMiddleware(t *testing.T) {
+	path := common.AuthUserGroupPath + "/paylinks"
+	url := "/admin/api/v1/paylinks"
+	body := `{"name":"paylink"}`
+	storeKey := hashParts("", "", "key")
+	fingerprint := hashParts(http.MethodPost, url, body)
+
+	cases := []struct {
+		name     string
+		stored   *idempotency.Record
+		body     string
+		err      error
+		status   int
+		called   bool
+		replayed bool
+		saved    bool
+	}{
+		{name: "first request", body: body, status: http.StatusCreated, called: true, saved: true},
+		{
+			name:     "completed request replayed",
+			stored:   &idempotency.Record{Fingerprint: fingerprint, Completed: true, Status: http.StatusCreated, ContentType: echo.MIMEApplicationJSONCharsetUTF8, Body: []byte(`{"id":"1"}`)},
+			body:     body,
+			status:   http.StatusCreated,
+			replayed: true,
+			saved:    true,
+		},
+		{
+			name:   "request in progress",
+			stored: &idempotency.Record{Fingerprint: fingerprint},
+			body:   body,
+			status: http.StatusConflict,
+			saved:  true,
+		},
+		{
+			name:   "key reused with the other body",
+			stored: &idempotency.Record{Fingerprint: fingerprint, Completed: true, Status: http.StatusCreated},
+			body:   `{"name":"other"}`,
+			status: http.StatusUnprocessableEntity,
+			saved:  true,
+		},
+		{
+			name:   "error isn't remembered",
+			body:   body,
+			err:    echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestDataInvalid),
+			status: http.StatusBadRequest,
+			called: true,
+		},
+	}
+
+	for _, c := range cases {
+		store := idempotency.NewMemoryStore()
+		d := &Dispatcher{
+			ctx:         context.Background(),
+			cfg:         Config{Idempotency: idempotency.Config{Ttl: time.Hour, LockTtl: time.Minute}},
+			idempotency: store,
+		}
+
+		if c.stored != nil {
+			require.NoError(t, store.Save(context.Background(), storeKey, c.stored, time.Hour), c.name)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(c.body))
+		req.Header.Set(common.HeaderIdempotencyKey, "key")
+		rsp := httptest.NewRecorder()
+		e := echo.New()
+		ctx := e.NewContext(req, rsp)
+		ctx.SetPath(path)
+
+		called := false
+		err := d.IdempotencyMiddleware()(func(ctx echo.Context) error {
+			called = true
+			if c.err != nil {
+				return c.err
+			}
+			return ctx.JSON(http.StatusCreated, map[string]string{"id": "1"})
+		})(ctx)
+
+		if err != nil {
+			e.HTTPErrorHandler(err, ctx)
+		}
+
+		assert.Equal(t, c.status, rsp.Code, c.name)
+		assert.Equal(t, c.called, called, c.name)
+		assert.Equal(t, c.replayed, rsp.Header().Get(common.HeaderIdempotentReplayed) == "true", c.name)
+
+		record, err := store.Get(context.Background(), storeKey)
+		require.NoError(t, err, c.name)
+		assert.Equal(t, c.saved, record != nil, c.name)
+
+		if c.called && c.saved {
+			assert.Equal(t, fingerprint, record.Fingerprint, c.name)
+			assert.True(t, record.Completed, c.name)
+			assert.JSONEq(t, `{"id":"1"}`, string(record.Body), c.name)
+		}
+	}
+}
+
+func Test_IdempotencyMiddleware_ReservationLost(t *testing.T) {
+	d := &Dispatcher{
+		ctx:         context.Background(),
+		cfg:         Config{Idempotency: idempotency.Config{Ttl: time.Hour, LockTtl: time.Minute}},
+		idempotency: &lostReservationStore{Store: idempotency.NewMemoryStore()},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api/v1/paylinks", strings.NewReader(`{}`))
+	req.Header.Set(common.HeaderIdempotencyKey, "key")
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	ctx.SetPath(common.AuthUserGroupPath + "/paylinks")
+
+	err := d.IdempotencyMiddleware()(func(_ echo.Context) error {
+		return errors.New("the handler isn't expected to be called")
+	})(ctx)
+
+	require.Error(t, err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, httpErr.Code)
+	assert.Equal(t, common.ErrorIdempotencyRequestInProgress, httpErr.Message)
+}
+
+// lostReservationStore emulates the request with the same key that has reserved it and failed between the store calls
+type lostReservationStore struct {
+	idempotency.Store
+}
+
+func (s *lostReservationStore) Reserve(context.Context, string, *idempotency.Record, time.Duration) (bool, error) {
+	return false, nil
+}
+
+func Test_rateLimitValue(t *testing.T) {
+	cases := []struct {
+		name      string
+		key       string
+		user      *common.AuthUser
+		remote    string
+		forwarded string
+		realIp    string
+		trust     bool
+		expected  string
+	}{
+		{name: "merchant", key: ratelimit.KeyMerchant, user: &common.AuthUser{Id: "user", MerchantId: "merchant"}, remote: "10.0.0.1:5000", expected: "merchant"},
+		{name: "user", key: ratelimit.KeyUser, user: &common.AuthUser{Id: "user", MerchantId: "merchant"}, remote: "10.0.0.1:5000", expected: "user"},
+		{name: "ip", key: ratelimit.KeyIp, user: &common.AuthUser{Id: "user"}, remote: "10.0.0.1:5000", expected: "10.0.0.1"},
+		{name: "without user", key: ratelimit.KeyMerchant, remote: "10.0.0.1:5000", expected: "10.0.0.1"},
+		{name: "ipv6", key: ratelimit.KeyIp, remote: "[2001:db8::1]:5000", expected: "2001:db8::1"},
+		{name: "without port", key: ratelimit.KeyIp, remote: "10.0.0.1", expected: "10.0.0.1"},
+		{
+			name:      "proxy headers ignored",
+			key:       ratelimit.KeyIp,
+			remote:    "10.0.0.1:5000",
+			forwarded: "203.0.113.1",
+			realIp:    "203.0.113.2",
+			expected:  "10.0.0.1",
+		},
+		{
+			name:      "forwarded for trusted",
+			key:       ratelimit.KeyIp,
+			remote:    "10.0.0.1:5000",
+			forwarded: "203.0.113.1, 10.0.0.2",
+			trust:     true,
+			expected:  "203.0.113.1",
+		},
+		{name: "real ip trusted", key: ratelimit.KeyIp, remote: "10.0.0.1:5000", realIp: "203.0.113.2", trust: true, expected: "203.0.113.2"},
+		{name: "trusted without headers", key: ratelimit.KeyIp, remote: "10.0.0.1:5000", trust: true, expected: "10.0.0.1"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/order", nil)
+		req.RemoteAddr = c.remote
+
+		if c.forwarded != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, c.forwarded)
+		}
+
+		if c.realIp != "" {
+			req.Header.Set(echo.HeaderXRealIP, c.realIp)
+		}
+
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+
+		if c.user != nil {
+			common.SetUserContext(ctx, c.user)
+		}
+
+		assert.Equal(t, c.expected, rateLimitValue(ctx, c.key, c.trust), c.name)
+	}
+}
@@ -9,9 +9,15 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/google/wire"
+	goMicro "github.com/micro/go-micro"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"github.com/paysuper/paysuper-management-api/internal/validators"
 	"github.com/paysuper/paysuper-management-api/pkg/micro"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
 	"github.com/paysuper/paysuper-proto/go/recurringpb"
 	"github.com/paysuper/paysuper-proto/go/reporterpb"
@@ -47,6 +53,65 @@ func ProviderJwtVerifier(cfg *common.Config) *jwtverifier.JwtVerifier {
 	})
 }
 
+// ProviderAuthCache
+func ProviderAuthCache(cfg *common.Config) *common.AuthCache {
+	return common.NewAuthCache(&cfg.AuthCache)
+}
+
+// ProviderRedis returns nil client if redis address isn't configured
+func ProviderRedis(cfg *Config) (*redis.Client, func(), error) {
+	if cfg.Redis.Addr == "" {
+		return nil, func() {}, nil
+	}
+	client := redis.New(&cfg.Redis)
+	return client, func() { _ = client.Close() }, nil
+}
+
+// ProviderApiKeys returns nil registry if redis address isn't configured, the api key authentication is disabled then
+func ProviderApiKeys(set provider.AwareSet, cfg *Config, client *redis.Client) (*apikey.Registry, func(), error) {
+	if client == nil {
+		set.Logger.Warning("api key authentication is disabled, redis address isn't configured")
+		return nil, func() {}, nil
+	}
+	store, err := apikey.NewStore(client)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	return newApiKeys(cfg, store)
+}
+
+// ProviderAudit
+func ProviderAudit(set provider.AwareSet, cfg *Config, client *redis.Client) audit.Store {
+	return audit.NewStore(&cfg.Audit, client, set.Logger)
+}
+
+// ProviderRedactor masks the personal data of the payment system callbacks shown to the system users
+func ProviderRedactor(cfg *Config) (*redact.Redactor, error) {
+	return redact.New(&cfg.Redaction)
+}
+
+// ProviderReportFiles
+func ProviderReportFiles(set provider.AwareSet, cfg *common.Config, client *redis.Client) reportfile.Store {
+	return reportfile.NewStore(client, cfg.ReportFiles.OwnerTtl, set.Logger)
+}
+
+// ProviderApiKeysTest keeps the api keys in memory
+func ProviderApiKeysTest(cfg *Config) (*apikey.Registry, func(), error) {
+	return newApiKeys(cfg, apikey.NewMemoryStore())
+}
+
+func newApiKeys(cfg *Config, store apikey.Store) (*apikey.Registry, func(), error) {
+	perms, err := apikey.LoadPermissions(
+		cfg.WorkDir+common.CasbinPolicyFile,
+		common.AuthUserGroupPath,
+		common.AuthUserGroupPath+common.ApiKeysRoutePath,
+	)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	return apikey.NewRegistry(store, perms, cfg.ApiKeys.LastUsedInterval), func() {}, nil
+}
+
 // ProviderServices
 func ProviderServices(srv *micro.Micro, cfg *micro.Config) common.Services {
 	return common.Services{
@@ -55,6 +120,8 @@ func ProviderServices(srv *micro.Micro, cfg *micro.Config) common.Services {
 		Billing:    billingpb.NewBillingService(billingpb.ServiceName, srv.Client(cfg.BillingVersion, cfg.BillingFallbackVersion)),
 		Tax:        taxpb.NewTaxService(taxpb.ServiceName, srv.Client("", "")),
 		Reporter:   reporterpb.NewReporterService(reporterpb.ServiceName, srv.Client("", "")),
+
+		WebhookNotifier: goMicro.NewPublisher(cfg.WebhookNotifierTopic, srv.Client("", "")),
 	}
 }
 
@@ -129,6 +196,12 @@ var (
 		ProviderDispatcher,
 		ProviderServices,
 		ProviderJwtVerifier,
+		ProviderAuthCache,
+		ProviderRedis,
+		ProviderApiKeys,
+		ProviderAudit,
+		ProviderReportFiles,
+		ProviderRedactor,
 		ProviderValidators,
 		ProviderCfg,
 		ProviderGlobalCfg,
@@ -138,6 +211,9 @@ var (
 	WireTestSet = wire.NewSet(
 		ProviderDispatcher,
 		ProviderJwtVerifier,
+		ProviderAuthCache,
+		ProviderRedis,
+		ProviderApiKeysTest,
 		ProviderValidators,
 		ProviderCfg,
 		ProviderGlobalCfg,
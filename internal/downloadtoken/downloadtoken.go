@@ -0,0 +1,84 @@
+package downloadtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("download token is invalid")
+	ErrExpired = errors.New("download token is expired")
+)
+
+// Claims of the token, the token allows to download the file only
+type Claims struct {
+	File       string `json:"f"`
+	UserId     string `json:"u"`
+	MerchantId string `json:"m,omitempty"`
+	ExpiresAt  int64  `json:"e"`
+}
+
+// Signer issues and verifies the tokens, the token is the base64 encoded claims and their hmac-sha256 signature
+// separated by the dot
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigner
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret), now: time.Now}
+}
+
+// Sign returns the token of the claims valid until the ttl expires
+func (s *Signer) Sign(claims *Claims, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := s.now().Add(ttl).Truncate(time.Second)
+	claims.ExpiresAt = expiresAt.Unix()
+	b, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+
+	return payload + "." + s.signature(payload), expiresAt, nil
+}
+
+// Verify returns the claims of the token if the signature is valid and the token isn't expired
+func (s *Signer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(s.signature(parts[0]))) {
+		return nil, ErrInvalid
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	claims := &Claims{}
+
+	if err = json.Unmarshal(b, claims); err != nil || claims.File == "" {
+		return nil, ErrInvalid
+	}
+
+	if !s.now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrExpired
+	}
+
+	return claims, nil
+}
+
+func (s *Signer) signature(payload string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
@@ -109,7 +109,7 @@ func (h *AdminUsersRoute) listUsers(ctx echo.Context) error {
 // @success 200 {object} billingpb.InviteUserAdminResponse Returns the admin user role data
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
-// @router /system/api/v1/users/roles/invite [post]
+// @router /system/api/v1/users/invite [post]
 func (h *AdminUsersRoute) sendInvite(ctx echo.Context) error {
 	req := &billingpb.InviteUserAdminRequest{}
 
@@ -140,7 +140,7 @@ func (h *AdminUsersRoute) sendInvite(ctx echo.Context) error {
 // @success 200 {object} billingpb.EmptyResponseWithStatus Returns an empty response body if the user's invitation was successfully send
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
-// @router /system/api/v1/users/roles/resend [post]
+// @router /system/api/v1/users/resend [post]
 func (h *AdminUsersRoute) resendInvite(ctx echo.Context) error {
 	req := &billingpb.ResendInviteAdminRequest{}
 
@@ -170,7 +170,7 @@ func (h *AdminUsersRoute) resendInvite(ctx echo.Context) error {
 // @success 200 {object} billingpb.GetRoleListResponse Returns the admin roles data
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
-// @router /system/api/v1/roles [get]
+// @router /system/api/v1/users/roles [get]
 func (h *AdminUsersRoute) listRoles(ctx echo.Context) error {
 	req := &billingpb.GetRoleListRequest{Type: billingpb.RoleTypeSystem}
 	res, err := h.dispatch.Services.Billing.GetRoleList(ctx.Request().Context(), req)
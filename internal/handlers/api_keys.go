@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"net/http"
+	"time"
+)
+
+const (
+	apiKeysPath       = common.ApiKeysRoutePath
+	apiKeysIdPath     = common.ApiKeysRoutePath + "/:key_id"
+	apiKeysScopesPath = common.ApiKeysRoutePath + "/scopes"
+)
+
+type ApiKeyRequest struct {
+	// The key name.
+	Name string `json:"name" validate:"required,max=255"`
+	// The list of permissions granted to the key. The available values are returned by the /admin/api/v1/api_keys/scopes method.
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+	// The date when the key expires. The key never expires if the date is omitted.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ApiKeyCreateResponse struct {
+	Key *apikey.Key `json:"key"`
+	// The token to be sent in the "Authorization: ApiKey <token>" header. It's returned only once.
+	Token string `json:"token"`
+}
+
+type ApiKeysListResponse struct {
+	Items []*apikey.Key `json:"items"`
+}
+
+type ApiKeysRoute struct {
+	dispatch common.HandlerSet
+	cfg      common.Config
+	provider.LMT
+}
+
+func NewApiKeysRoute(set common.HandlerSet, cfg *common.Config) *ApiKeysRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "ApiKeysRoute"})
+	return &ApiKeysRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      *cfg,
+	}
+}
+
+func (h *ApiKeysRoute) Route(groups *common.Groups) {
+	// the keys are disabled if redis address isn't configured
+	if h.dispatch.ApiKeys == nil {
+		return
+	}
+	groups.AuthUser.GET(apiKeysPath, h.listApiKeys)
+	groups.AuthUser.POST(apiKeysPath, h.createApiKey)
+	groups.AuthUser.GET(apiKeysScopesPath, h.listApiKeyScopes)
+	groups.AuthUser.GET(apiKeysIdPath, h.getApiKey)
+	groups.AuthUser.PUT(apiKeysIdPath, h.updateApiKey)
+	groups.AuthUser.DELETE(apiKeysIdPath, h.revokeApiKey)
+}
+
+// @summary Get the list of API keys
+// @desc Get the list of API keys including the revoked ones for the authorized merchant
+// @id apiKeysPathListApiKeys
+// @tag API keys
+// @accept application/json
+// @produce application/json
+// @success 200 {object} ApiKeysListResponse Returns the list of API keys
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /admin/api/v1/api_keys [get]
+func (h *ApiKeysRoute) listApiKeys(ctx echo.Context) error {
+	authUser := common.ExtractUserContext(ctx)
+	keys, err := h.dispatch.ApiKeys.List(ctx.Request().Context(), authUser.MerchantId)
+
+	if err != nil {
+		return h.registryError(err, "List")
+	}
+
+	return ctx.JSON(http.StatusOK, &ApiKeysListResponse{Items: keys})
+}
+
+// @summary Get the list of API key scopes
+// @desc Get the list of permissions which can be granted to an API key
+// @id apiKeysScopesPathListApiKeyScopes
+// @tag API keys
+// @accept application/json
+// @produce application/json
+// @success 200 {array} string Returns the list of permission names
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @router /admin/api/v1/api_keys/scopes [get]
+func (h *ApiKeysRoute) listApiKeyScopes(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.dispatch.ApiKeys.Scopes())
+}
+
+// @summary Create an API key
+// @desc Create an API key for machine-to-machine access. The key acts on behalf of the authorized user and can't exceed the user's permissions
+// @id apiKeysPathCreateApiKey
+// @tag API keys
+// @accept application/json
+// @produce application/json
+// @body ApiKeyRequest
+// @success 201 {object} ApiKeyCreateResponse Returns the API key and its token
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /admin/api/v1/api_keys [post]
+func (h *ApiKeysRoute) createApiKey(ctx echo.Context) error {
+	req := &ApiKeyRequest{}
+
+	if err := h.bindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	authUser := common.ExtractUserContext(ctx)
+	key, token, err := h.dispatch.ApiKeys.Create(ctx.Request().Context(), &apikey.Key{
+		MerchantId: authUser.MerchantId,
+		UserId:     authUser.Id,
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		ExpiresAt:  req.ExpiresAt,
+	})
+
+	if err != nil {
+		return h.registryError(err, "Create")
+	}
+
+	return ctx.JSON(http.StatusCreated, &ApiKeyCreateResponse{Key: key, Token: token})
+}
+
+// @summary Get the API key
+// @desc Get the API key using its ID
+// @id apiKeysIdPathGetApiKey
+// @tag API keys
+// @accept application/json
+// @produce application/json
+// @success 200 {object} apikey.Key Returns the API key
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The API key not found
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param key_id path {string} true The unique identifier for the API key.
+// @router /admin/api/v1/api_keys/{key_id} [get]
+func (h *ApiKeysRoute) getApiKey(ctx echo.Context) error {
+	authUser := common.ExtractUserContext(ctx)
+	key, err := h.dispatch.ApiKeys.Get(ctx.Request().Context(), authUser.MerchantId, ctx.Param(common.RequestParameterApiKeyId))
+
+	if err != nil {
+		return h.registryError(err, "Get")
+	}
+
+	return ctx.JSON(http.StatusOK, key)
+}
+
+// @summary Update the API key
+// @desc Change the name, scopes and expiration date of the API key
+// @id apiKeysIdPathUpdateApiKey
+// @tag API keys
+// @accept application/json
+// @produce application/json
+// @body ApiKeyRequest
+// @success 200 {object} apikey.Key Returns the updated API key
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The API key not found
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param key_id path {string} true The unique identifier for the API key.
+// @router /admin/api/v1/api_keys/{key_id} [put]
+func (h *ApiKeysRoute) updateApiKey(ctx echo.Context) error {
+	req := &ApiKeyRequest{}
+
+	if err := h.bindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	authUser := common.ExtractUserContext(ctx)
+	key, err := h.dispatch.ApiKeys.Update(
+		ctx.Request().Context(),
+		authUser.MerchantId,
+		ctx.Param(common.RequestParameterApiKeyId),
+		req.Name,
+		req.Scopes,
+		req.ExpiresAt,
+	)
+
+	if err != nil {
+		return h.registryError(err, "Update")
+	}
+
+	return ctx.JSON(http.StatusOK, key)
+}
+
+// @summary Revoke the API key
+// @desc Revoke the API key using its ID. The revoked key can't be restored
+// @id apiKeysIdPathRevokeApiKey
+// @tag API keys
+// @accept application/json
+// @produce application/json
+// @success 200 {object} apikey.Key Returns the revoked API key
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The API key not found
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param key_id path {string} true The unique identifier for the API key.
+// @router /admin/api/v1/api_keys/{key_id} [delete]
+func (h *ApiKeysRoute) revokeApiKey(ctx echo.Context) error {
+	authUser := common.ExtractUserContext(ctx)
+	key, err := h.dispatch.ApiKeys.Revoke(ctx.Request().Context(), authUser.MerchantId, ctx.Param(common.RequestParameterApiKeyId))
+
+	if err != nil {
+		return h.registryError(err, "Revoke")
+	}
+
+	return ctx.JSON(http.StatusOK, key)
+}
+
+func (h *ApiKeysRoute) bindAndValidate(req *ApiKeyRequest, ctx echo.Context) error {
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestDataInvalid)
+	}
+
+	return nil
+}
+
+func (h *ApiKeysRoute) registryError(err error, method string) error {
+	switch err {
+	case apikey.ErrKeyNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorApiKeyNotFound)
+	case apikey.ErrScopesInvalid:
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorApiKeyScopesIncorrect)
+	}
+
+	h.L().Error("api keys registry call failed", logger.PairArgs("err", err.Error(), "method", method))
+	return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type ApiKeysTestSuite struct {
+	suite.Suite
+	router *ApiKeysRoute
+	caller *test.EchoReqResCaller
+}
+
+func Test_ApiKeys(t *testing.T) {
+	suite.Run(t, new(ApiKeysTestSuite))
+}
+
+func (suite *ApiKeysTestSuite) SetupTest() {
+	user := &common.AuthUser{
+		Id:         "ffffffffffffffffffffffff",
+		MerchantId: "ffffffffffffffffffffffff",
+	}
+
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{
+		Billing: mock.NewBillingServerOkMock(),
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		mw.Pre(test.PreAuthUserMiddleware(user))
+		suite.router = NewApiKeysRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *ApiKeysTestSuite) TearDownTest() {}
+
+func (suite *ApiKeysTestSuite) createKey() *ApiKeyCreateResponse {
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath + apiKeysPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"name": "reports export", "scopes": ["merchantGetRole"]}`).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, res.Code)
+
+	rsp := &ApiKeyCreateResponse{}
+	assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	return rsp
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_CreateApiKey_Ok() {
+	rsp := suite.createKey()
+
+	assert.NotEmpty(suite.T(), rsp.Key.Id)
+	assert.Equal(suite.T(), "ffffffffffffffffffffffff", rsp.Key.MerchantId)
+	assert.Equal(suite.T(), "ffffffffffffffffffffffff", rsp.Key.UserId)
+	assert.True(suite.T(), strings.HasPrefix(rsp.Token, apikey.TokenPrefix+rsp.Key.Id+"."))
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_CreateApiKey_UnknownScope_Error() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath + apiKeysPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"name": "reports export", "scopes": ["merchantCreateApiKey"]}`).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorApiKeyScopesIncorrect, httpErr.Message)
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_CreateApiKey_ValidationError() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath + apiKeysPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"name": "reports export"}`).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_ListApiKeys_Ok() {
+	suite.createKey()
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + apiKeysPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+		rsp := &ApiKeysListResponse{}
+		assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+		assert.Len(suite.T(), rsp.Items, 1)
+		assert.NotContains(suite.T(), res.Body.String(), "secret")
+	}
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_ListApiKeyScopes_Ok() {
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + apiKeysScopesPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+		assert.Contains(suite.T(), res.Body.String(), "merchantGetRole")
+		assert.NotContains(suite.T(), res.Body.String(), "merchantCreateApiKey")
+	}
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_UpdateApiKey_Ok() {
+	key := suite.createKey().Key
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPut).
+		Params(":"+common.RequestParameterApiKeyId, key.Id).
+		Path(common.AuthUserGroupPath + apiKeysIdPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"name": "payouts export", "scopes": ["merchantGetRole", "merchantGetMerchantUsers"]}`).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+		rsp := &apikey.Key{}
+		assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+		assert.Equal(suite.T(), "payouts export", rsp.Name)
+		assert.Len(suite.T(), rsp.Scopes, 2)
+	}
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_RevokeApiKey_Ok() {
+	key := suite.createKey().Key
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodDelete).
+		Params(":"+common.RequestParameterApiKeyId, key.Id).
+		Path(common.AuthUserGroupPath + apiKeysIdPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+		rsp := &apikey.Key{}
+		assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+		assert.NotNil(suite.T(), rsp.RevokedAt)
+	}
+}
+
+func (suite *ApiKeysTestSuite) TestApiKeys_GetApiKey_NotFound() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterApiKeyId, "0000000000000000").
+		Path(common.AuthUserGroupPath + apiKeysIdPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorApiKeyNotFound, httpErr.Message)
+}
@@ -45,7 +45,7 @@ func (h *CountryApiV1) get(ctx echo.Context) error {
 
 	res, err := h.dispatch.Services.Billing.GetCountriesList(ctx.Request().Context(), &billingpb.EmptyRequest{})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError /*ErrorCountriesListError*/, err)
+		return common.SrvCallError(err, http.StatusInternalServerError /*ErrorCountriesListError*/, err)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -74,12 +74,12 @@ func (h *CountryApiV1) getById(ctx echo.Context) error {
 	}
 	err := h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetCountry(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, common.ErrorCountryNotFound)
+		return common.SrvCallError(err, http.StatusNotFound, common.ErrorCountryNotFound)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
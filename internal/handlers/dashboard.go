@@ -59,14 +59,14 @@ func (h *DashboardRoute) getMainReports(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetDashboardMainReport(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetDashboardMainReport", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -98,14 +98,14 @@ func (h *DashboardRoute) getRevenueDynamicsReport(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetDashboardRevenueDynamicsReport(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetDashboardMainReport", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -137,14 +137,14 @@ func (h *DashboardRoute) getBaseReports(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetDashboardBaseReport(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetDashboardMainReport", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -77,13 +77,13 @@ func (h *KeyProductRoute) unpublishKeyProduct(ctx echo.Context) error {
 	req.KeyProductId = ctx.Param("key_product_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.UnPublishKeyProduct(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -115,13 +115,13 @@ func (h *KeyProductRoute) publishKeyProduct(ctx echo.Context) error {
 	req.KeyProductId = ctx.Param("key_product_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.PublishKeyProduct(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Message != nil {
@@ -159,13 +159,13 @@ func (h *KeyProductRoute) getPlatformsList(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPlatforms(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -196,13 +196,13 @@ func (h *KeyProductRoute) deleteKeyProductById(ctx echo.Context) error {
 	req.Id = ctx.Param("key_product_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeleteKeyProduct(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -234,13 +234,13 @@ func (h *KeyProductRoute) changeKeyProduct(ctx echo.Context) error {
 	req.Id = ctx.Param("key_product_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreateOrUpdateKeyProduct(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -271,13 +271,13 @@ func (h *KeyProductRoute) getKeyProductById(ctx echo.Context) error {
 	req.Id = ctx.Param("key_product_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetKeyProduct(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -305,7 +305,7 @@ func (h *KeyProductRoute) createKeyProduct(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	h.L().Info("createKeyProduct", logger.PairArgs("req", req))
@@ -313,7 +313,7 @@ func (h *KeyProductRoute) createKeyProduct(ctx echo.Context) error {
 	res, err := h.dispatch.Services.Billing.CreateOrUpdateKeyProduct(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Message != nil {
@@ -357,13 +357,13 @@ func (h *KeyProductRoute) getKeyProductList(ctx echo.Context) error {
 	req.MerchantId = authUser.MerchantId
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetKeyProducts(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Message != nil {
@@ -398,7 +398,7 @@ func (h *KeyProductRoute) getKeyProduct(ctx echo.Context) error {
 	req.KeyProductId = ctx.Param("key_product_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	if req.Currency == "" && req.Country == "" {
@@ -417,7 +417,7 @@ func (h *KeyProductRoute) getKeyProduct(ctx echo.Context) error {
 	res, err := h.dispatch.Services.Billing.GetKeyProductInfo(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -473,7 +473,7 @@ func (h *KeyProductRoute) uploadKeys(ctx echo.Context) error {
 	keyProductRes, err := h.dispatch.Services.Billing.GetKeyProduct(ctx.Request().Context(), &billingpb.RequestKeyProductMerchant{Id: req.KeyProductId, MerchantId: req.MerchantId})
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if keyProductRes.Status != billingpb.ResponseStatusOk {
@@ -481,13 +481,13 @@ func (h *KeyProductRoute) uploadKeys(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.UploadKeysFile(ctx.Request().Context(), req, client.WithRequestTimeout(time.Minute*10))
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -509,7 +509,7 @@ func (h *KeyProductRoute) uploadKeys(ctx echo.Context) error {
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
 // @param key_product_id path {string} true The unique identifier for the key-activated product.
 // @param platform_id path {string} true The platform's name. Available values: steam, gog, uplay, origin, psn, xbox, nintendo, itch, egs.
-// @router /auth/api/v1/key-products/{key_product_id}/platforms/{platform_id}/count [get]
+// @router /admin/api/v1/key-products/{key_product_id}/platforms/{platform_id}/count [get]
 func (h *KeyProductRoute) getCountOfKeys(ctx echo.Context) error {
 	req := &billingpb.GetPlatformKeyCountRequest{}
 	if err := ctx.Bind(req); err != nil {
@@ -520,14 +520,14 @@ func (h *KeyProductRoute) getCountOfKeys(ctx echo.Context) error {
 	req.PlatformId = ctx.Param("platform_id")
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetAvailableKeysCount(ctx.Request().Context(), req)
 
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.PairArgs("err", err.Error()))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
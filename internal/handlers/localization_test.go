@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/i18n"
+	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
+	"github.com/paysuper/paysuper-management-api/internal/test"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type LocalizationTestSuite struct {
+	suite.Suite
+	caller *test.EchoReqResCaller
+}
+
+func Test_Localization(t *testing.T) {
+	suite.Run(t, new(LocalizationTestSuite))
+}
+
+func (suite *LocalizationTestSuite) SetupTest() {
+	user := &common.AuthUser{
+		Id:         "ffffffffffffffffffffffff",
+		MerchantId: "ffffffffffffffffffffffff",
+	}
+
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{
+		Billing: mock.NewBillingServerOkMock(),
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		mw.Pre(test.PreAuthUserMiddleware(user))
+		return common.Handlers{
+			NewOrderRoute(set.HandlerSet, orderlog.NewCloudWatch(&mock.CloudWatchInterface{}), set.GlobalConfig),
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *LocalizationTestSuite) TearDownTest() {}
+
+func (suite *LocalizationTestSuite) TestLocalization_RequiredLocales_AllErrorsTranslated() {
+	errs := common.ResponseErrors()
+	assert.NotEmpty(suite.T(), errs)
+
+	for _, locale := range i18n.RequiredLocales {
+		for _, rspErr := range errs {
+			msg, ok := i18n.Message(locale, rspErr.Code, rspErr.Message)
+			assert.True(suite.T(), ok, "%s: no translation of %s %q", locale, rspErr.Code, rspErr.Message)
+			assert.NotEmpty(suite.T(), msg)
+		}
+
+		_, ok := i18n.ValidationMask(locale)
+		assert.True(suite.T(), ok, "%s: no translation of the validation details", locale)
+	}
+}
+
+func (suite *LocalizationTestSuite) TestLocalization_SharedCode_TranslatedByMessage() {
+	price, ok := i18n.Message(i18n.LocaleDe, common.ErrorMessageGetProductPrice.Code, common.ErrorMessageGetProductPrice.Message)
+	assert.True(suite.T(), ok)
+
+	update, ok := i18n.Message(i18n.LocaleDe, common.ErrorMessageUpdateProductPrice.Code, common.ErrorMessageUpdateProductPrice.Message)
+	assert.True(suite.T(), ok)
+	assert.NotEqual(suite.T(), price, update)
+}
+
+func (suite *LocalizationTestSuite) TestLocalization_ParseAcceptLanguage() {
+	cases := map[string]string{
+		"":                              i18n.LocaleEn,
+		"*":                             i18n.LocaleEn,
+		"ru":                            i18n.LocaleRu,
+		"de-AT,de;q=0.9":                i18n.LocaleDe,
+		"fr-FR,fr;q=0.9,zh-CN;q=0.8":    i18n.LocaleZh,
+		"en;q=0.5,ru;q=0.8":             i18n.LocaleRu,
+		"ru;q=0,en":                     i18n.LocaleEn,
+		"fr, ja;q=0.9":                  i18n.LocaleEn,
+		"de;q=0.7, zh-Hans-CN;q=0.7":    i18n.LocaleDe,
+		"ZH-TW , en-US;q=0.9, de;q=0.8": i18n.LocaleZh,
+	}
+
+	for header, locale := range cases {
+		assert.Equal(suite.T(), locale, i18n.ParseAcceptLanguage(header), header)
+	}
+}
+
+func (suite *LocalizationTestSuite) TestLocalization_ValidationError_Ru_Ok() {
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + orderRefundsIdsPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			request.Header.Set(common.HeaderAcceptLanguage, "ru-RU,ru;q=0.9,en;q=0.8")
+		}).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), i18n.LocaleRu, res.Header().Get(common.HeaderContentLanguage))
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+
+	msg, ok := httpErr.Message.(*billingpb.ResponseErrorMessage)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorValidationFailed.Code, msg.Code)
+
+	expected, _ := i18n.Message(i18n.LocaleRu, common.ErrorValidationFailed.Code, common.ErrorValidationFailed.Message)
+	assert.Equal(suite.T(), expected, msg.Message)
+
+	mask, _ := i18n.ValidationMask(i18n.LocaleRu)
+	assert.True(suite.T(), strings.HasPrefix(msg.Details, strings.Split(mask, "%s")[0]), msg.Details)
+
+	assert.Equal(suite.T(), "validation failed", common.ErrorValidationFailed.Message)
+}
+
+func (suite *LocalizationTestSuite) TestLocalization_AllValidationErrors_Zh_Ok() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + orderRefundsIdsPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			request.Header.Set(common.HeaderXValidationErrors, common.ValidationErrorsAll)
+			request.Header.Set(common.HeaderAcceptLanguage, "zh-CN")
+		}).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+
+	res, ok := httpErr.Message.(*common.ValidationErrorsResponse)
+	assert.True(suite.T(), ok)
+	assert.NotEmpty(suite.T(), res.Errors)
+
+	mask, _ := i18n.ValidationMask(i18n.LocaleZh)
+	for _, e := range res.Errors {
+		assert.NotEqual(suite.T(), common.ErrorValidationFailed.Message, e.Message)
+		assert.Equal(suite.T(), fmt.Sprintf(mask, e.Field, e.Tag), e.Details)
+	}
+}
+
+func (suite *LocalizationTestSuite) TestLocalization_UnsupportedLanguage_English() {
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + orderRefundsIdsPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			request.Header.Set(common.HeaderAcceptLanguage, "fr-FR")
+		}).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderContentLanguage))
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+
+	msg, ok := httpErr.Message.(*billingpb.ResponseErrorMessage)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorValidationFailed.Message, msg.Message)
+	assert.True(suite.T(), strings.HasPrefix(msg.Details, strings.Split(common.ErrorMessageMask, "%s")[0]), msg.Details)
+}
@@ -69,7 +69,7 @@ func (h *BalanceRoute) getBalance(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMerchantBalance", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != http.StatusOK {
@@ -71,6 +71,8 @@ func (h *MerchantUsersRoute) changeRole(ctx echo.Context) error {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
+	h.dispatch.AuthCache.InvalidateMerchant(common.ExtractUserContext(ctx).MerchantId)
+
 	return ctx.NoContent(http.StatusOK)
 }
 
@@ -215,6 +217,8 @@ func (h *MerchantUsersRoute) deleteUser(ctx echo.Context) error {
 		return echo.NewHTTPError(int(res.Status), res.Message)
 	}
 
+	h.dispatch.AuthCache.InvalidateMerchant(common.ExtractUserContext(ctx).MerchantId)
+
 	return ctx.JSON(http.StatusOK, res)
 }
 
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"net/http"
 	"testing"
+	"time"
 )
 
 type MerchantUsersTestSuite struct {
@@ -179,3 +180,90 @@ func (suite *MerchantUsersTestSuite) TestMerchantChangeRole_Ok() {
 	shouldBe.Equal(http.StatusOK, res.Code)
 	shouldBe.Empty(res.Body.String())
 }
+
+func (suite *MerchantUsersTestSuite) TestMerchantChangeRole_InvalidatesAuthCache() {
+	shouldBe := require.New(suite.T())
+
+	cache := suite.setUpAuthCache()
+
+	billingService := suite.router.dispatch.Services.Billing.(*mocks.BillingService)
+	billingService.On("ChangeRoleForMerchantUser", mock2.Anything, mock2.Anything).Return(&billingpb.EmptyResponseWithStatus{
+		Status: 200,
+	}, nil)
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPut).
+		Params(":"+common.RequestRoleId, bson.NewObjectId().Hex()).
+		Path(common.AuthUserGroupPath + merchantUsersRole).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"role": "some_role"}`).
+		Exec(suite.T())
+
+	shouldBe.NoError(err)
+
+	_, ok := cache.GetMerchants("5e96c1f4ff5d7c9a3c8d1b97")
+	shouldBe.False(ok)
+	_, ok = cache.GetMerchants("5e96c1f4ff5d7c9a3c8d1b98")
+	shouldBe.True(ok)
+}
+
+func (suite *MerchantUsersTestSuite) TestMerchantDeleteUser_InvalidatesAuthCache() {
+	shouldBe := require.New(suite.T())
+
+	cache := suite.setUpAuthCache()
+
+	billingService := suite.router.dispatch.Services.Billing.(*mocks.BillingService)
+	billingService.On("DeleteMerchantUser", mock2.Anything, mock2.Anything).Return(&billingpb.EmptyResponseWithStatus{
+		Status: 200,
+	}, nil)
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodDelete).
+		Params(":"+common.RequestRoleId, bson.NewObjectId().Hex()).
+		Path(common.AuthUserGroupPath + merchantUsersRole).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	shouldBe.NoError(err)
+
+	_, ok := cache.GetMerchants("5e96c1f4ff5d7c9a3c8d1b97")
+	shouldBe.False(ok)
+	_, ok = cache.GetMerchants("5e96c1f4ff5d7c9a3c8d1b98")
+	shouldBe.True(ok)
+}
+
+func (suite *MerchantUsersTestSuite) TestMerchantDeleteUser_Error_KeepsAuthCache() {
+	shouldBe := require.New(suite.T())
+
+	cache := suite.setUpAuthCache()
+
+	billingService := suite.router.dispatch.Services.Billing.(*mocks.BillingService)
+	billingService.On("DeleteMerchantUser", mock2.Anything, mock2.Anything).Return(nil, errors.New("error"))
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodDelete).
+		Params(":"+common.RequestRoleId, bson.NewObjectId().Hex()).
+		Path(common.AuthUserGroupPath + merchantUsersRole).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	shouldBe.Error(err)
+
+	_, ok := cache.GetMerchants("5e96c1f4ff5d7c9a3c8d1b97")
+	shouldBe.True(ok)
+}
+
+// setUpAuthCache caches the merchants of the user of the current merchant and of the user of another merchant
+func (suite *MerchantUsersTestSuite) setUpAuthCache() *common.AuthCache {
+	cache := common.NewAuthCache(&common.AuthCacheSettings{Size: 10, TokenTtl: time.Minute, MerchantTtl: time.Minute})
+	cache.SetMerchants("5e96c1f4ff5d7c9a3c8d1b97", &billingpb.GetMerchantsForUserResponse{
+		Status:    billingpb.ResponseStatusOk,
+		Merchants: []*billingpb.MerchantForUserInfo{{Id: "ffffffffffffffffffffffff"}},
+	})
+	cache.SetMerchants("5e96c1f4ff5d7c9a3c8d1b98", &billingpb.GetMerchantsForUserResponse{
+		Status:    billingpb.ResponseStatusOk,
+		Merchants: []*billingpb.MerchantForUserInfo{{Id: "5e96c1f4ff5d7c9a3c8d1b99"}},
+	})
+	suite.router.dispatch.AuthCache = cache
+	return cache
+}
@@ -142,7 +142,7 @@ func (h *OnboardingRoute) getMerchant(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMerchantBy", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -163,7 +163,7 @@ func (h *OnboardingRoute) getMerchantByUser(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMerchantBy", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -210,14 +210,14 @@ func (h *OnboardingRoute) listMerchants(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.ListMerchants(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "ListMerchants", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -245,7 +245,7 @@ func (h *OnboardingRoute) changeMerchantStatus(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	req.UserId = authUser.Id
@@ -253,7 +253,7 @@ func (h *OnboardingRoute) changeMerchantStatus(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "ChangeMerchantStatus", req)
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	if res.Status != http.StatusOK {
@@ -285,7 +285,7 @@ func (h *OnboardingRoute) createNotification(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	req.UserId = authUser.Id
@@ -293,7 +293,7 @@ func (h *OnboardingRoute) createNotification(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "CreateNotification", req)
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	if res.Status != http.StatusOK {
@@ -379,14 +379,14 @@ func (h *OnboardingRoute) listNotifications(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.ListNotifications(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "ListNotifications", req)
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -633,14 +633,14 @@ func (h *OnboardingRoute) setMerchantCompany(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.ChangeMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "ChangeMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -696,14 +696,14 @@ func (h *OnboardingRoute) setMerchantContacts(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.ChangeMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "ChangeMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -759,14 +759,14 @@ func (h *OnboardingRoute) setMerchantBanking(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.ChangeMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "ChangeMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -834,14 +834,14 @@ func (h *OnboardingRoute) getTariffRates(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetMerchantTariffRates(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMerchantTariffRates", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -888,7 +888,7 @@ func (h *OnboardingRoute) setTariffRates(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.SetMerchantTariffRates(
@@ -899,7 +899,7 @@ func (h *OnboardingRoute) setTariffRates(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SetMerchantTariffRates", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -1056,14 +1056,14 @@ func (h *OnboardingRoute) setOperatingCompany(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.SetMerchantOperatingCompany(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SetMerchantOperatingCompany", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -54,7 +54,7 @@ func (h *OperatingCompanyRoute) getOperatingCompanyList(ctx echo.Context) error
 	res, err := h.dispatch.Services.Billing.GetOperatingCompaniesList(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetOperatingCompaniesList", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -81,7 +81,7 @@ func (h *OperatingCompanyRoute) getOperatingCompany(ctx echo.Context) error {
 	res, err := h.dispatch.Services.Billing.GetOperatingCompany(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetOperatingCompaniesList", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -133,14 +133,14 @@ func (h *OperatingCompanyRoute) addOrUpdateOperatingCompany(ctx echo.Context, op
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.AddOperatingCompany(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "AddOperatingCompany", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -4,15 +4,18 @@ import (
 	"encoding/json"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/labstack/echo/v4"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
 	"github.com/paysuper/paysuper-proto/go/reporterpb"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -23,10 +26,27 @@ const (
 	orderRefundsIdsPath  = "/order/:order_id/refunds/:refund_id"
 	orderReplaceCodePath = "/order/:order_id/replace_code"
 	orderGetLogsPath     = "/order/:order_id/logs"
+	orderWebhooksPath    = "/order/webhooks"
 )
 
 const (
 	errorTemplateName = "error.html"
+
+	orderLogsLimitDefault     = 100
+	orderLogsLimitMaxDefault  = 1000
+	orderLogsWindowDefault    = 7 * 24 * time.Hour
+	orderLogsWindowMaxDefault = 31 * 24 * time.Hour
+
+	webhookDeliveriesLimitDefault = 20
+	webhookDeliveriesLimitMax     = 100
+	webhookDeliveryExcerptSize    = 512
+	webhookDeliveryStatusSuccess  = "success"
+	webhookDeliveryStatusFailed   = "failed"
+	// webhookDeliveriesScanMax is the number of the orders which attempts are read to fill the page filtered by
+	// the status, the next page token continues the scan if the page isn't filled
+	webhookDeliveriesScanMax = 200
+	// webhookDeliveriesConcurrency is the number of the orders which attempts are requested at the same time
+	webhookDeliveriesConcurrency = 8
 )
 
 type CreateOrderJsonProjectResponse struct {
@@ -58,15 +78,33 @@ type ListOrdersRequest struct {
 	PmDateTo int64 `json:"pm_date_to" validate:"omitempty,numeric,gt=0"`
 }
 
-type cloudWatchLogSettings struct {
-	group   string
-	pattern func(order *billingpb.OrderViewPublic) string
-	setter  func(result *GetOrderLogsResponse, value *LogOrder)
+type orderLogSettings struct {
+	stream string
+	terms  func(order *billingpb.OrderViewPublic) []string
+	token  func(req *GetOrderLogsRequest) string
+	setter func(result *GetOrderLogsResponse, page []*LogOrder, nextToken string)
+}
+
+type orderLogs struct {
+	logSettings []*orderLogSettings
+	// deliveries are the logs of the webhook notifier shown to the merchants
+	deliveries *orderLogSettings
+	source     orderlog.Source
 }
 
-type cloudWatch struct {
-	logSettings []*cloudWatchLogSettings
-	instance    common.CloudWatchInterface
+type GetOrderLogsRequest struct {
+	// The start date of the logs period in the Unix timestamp format. By default, the order's creation date.
+	DateFrom int64 `query:"date_from" validate:"omitempty,gt=0"`
+	// The end date of the logs period in the Unix timestamp format. By default, 7 days after the start date.
+	DateTo int64 `query:"date_to" validate:"omitempty,gt=0"`
+	// The maximum number of the records of each log on the page.
+	Limit int64 `query:"limit" validate:"omitempty,gt=0"`
+	// The next page token of the payment creation logs. If any token is passed only the logs with the tokens are returned.
+	CreateToken string `query:"create_token"`
+	// The next page token of the payment callback logs.
+	CallbackToken string `query:"callback_token"`
+	// The next page token of the payment notification logs.
+	NotifyToken string `query:"notify_token"`
 }
 
 type LogRequest struct {
@@ -93,6 +131,72 @@ type GetOrderLogsResponse struct {
 	Callback []*LogOrder `json:"callback"`
 	// The order's logs list of the notification about the payment status sent to the project.
 	Notify []*LogOrder `json:"notify"`
+	// The next page token of the payment creation logs. It's empty on the last page.
+	CreateNextToken string `json:"create_next_token,omitempty"`
+	// The next page token of the payment callback logs. It's empty on the last page.
+	CallbackNextToken string `json:"callback_next_token,omitempty"`
+	// The next page token of the payment notification logs. It's empty on the last page.
+	NotifyNextToken string `json:"notify_next_token,omitempty"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	// The unique identifier for the merchant.
+	MerchantId string `json:"-" validate:"required,hexadecimal,len=24"`
+	// The list of projects.
+	Project []string `query:"project" validate:"omitempty,dive,hexadecimal,len=24"`
+	// The start date of the delivery attempts in the Unix timestamp format. By default, the order's creation date.
+	DateFrom int64 `query:"date_from" validate:"omitempty,gt=0"`
+	// The end date of the delivery attempts in the Unix timestamp format. By default, 7 days after the start date. Only the orders paid before it are listed.
+	DateTo int64 `query:"date_to" validate:"omitempty,gt=0"`
+	// The delivery status. Available values: success, failed. The attempts with the status are paged by the next page token instead of the orders.
+	Status string `query:"status" validate:"omitempty,oneof=success failed"`
+	// The number of orders returned in one page, the number of attempts if the status is passed. Default value is 20.
+	Limit int64 `query:"limit" validate:"omitempty,gt=0"`
+	// The ranking number of the first order on the page. It isn't applied if the status is passed.
+	Offset int64 `query:"offset" validate:"omitempty,gte=0"`
+	// The next page token of the attempts with the status.
+	Token string `query:"token"`
+}
+
+type WebhookDelivery struct {
+	// The unique identifier for the order.
+	OrderId string `json:"order_id"`
+	// The unique identifier for the project.
+	ProjectId string `json:"project_id"`
+	// The date of the delivery attempt.
+	Date time.Time `json:"date"`
+	// The URL of the project's webhook.
+	Url string `json:"url"`
+	// The HTTP status code of the project's response. It's 0 if the project didn't respond.
+	StatusCode int `json:"status_code"`
+	// The time of the project's response in milliseconds.
+	Latency int64 `json:"latency"`
+	// The beginning of the project's response body.
+	ResponseBody string `json:"response_body"`
+	// The number of the delivery attempt starting from 1.
+	Retry int64 `json:"retry"`
+	// Has a true value if the project's response status code is 2xx.
+	Success bool `json:"success"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	// The total number of the orders paid before the end of the period.
+	Count int64 `json:"count"`
+	// The delivery attempts of the orders on the page.
+	Items []*WebhookDelivery `json:"items"`
+	// The orders on the page which delivery attempts couldn't be read, the attempts of the other orders are returned.
+	FailedOrders []string `json:"failed_orders,omitempty"`
+	// The next page token of the attempts with the status. It's empty on the last page.
+	NextToken string `json:"next_token,omitempty"`
+}
+
+// orderWebhookDeliveries are the delivery attempts of the order made within the period
+type orderWebhookDeliveries struct {
+	items []*WebhookDelivery
+	// requested has a true value if the order was created before the end of the period
+	requested bool
+	// failed has a true value if the attempts couldn't be read from the log source
+	failed bool
 }
 
 type OrderListRefundsBinder struct {
@@ -123,53 +227,65 @@ type OrderRoute struct {
 	dispatch common.HandlerSet
 	cfg      common.Config
 	provider.LMT
-	*cloudWatch
+	*orderLogs
 }
 
 func NewOrderRoute(
 	set common.HandlerSet,
-	cloudWatchLog common.CloudWatchInterface,
+	orderLogSource orderlog.Source,
 	cfg *common.Config,
 ) *OrderRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "OrderRoute"})
-	cloudWatch := &cloudWatch{
-		logSettings: []*cloudWatchLogSettings{
+	streams := cfg.OrderLogStreams()
+	deliveries := &orderLogSettings{
+		stream: streams.WebhookNotifier,
+		terms: func(order *billingpb.OrderViewPublic) []string {
+			return []string{order.Uuid, "delivery_try"}
+		},
+		token: func(req *GetOrderLogsRequest) string {
+			return req.NotifyToken
+		},
+		setter: func(result *GetOrderLogsResponse, page []*LogOrder, nextToken string) {
+			result.Notify, result.NotifyNextToken = page, nextToken
+		},
+	}
+	orderLogs := &orderLogs{
+		logSettings: []*orderLogSettings{
 			{
-				group: cfg.AwsCloudWatchLogGroupBillingServer,
-				pattern: func(order *billingpb.OrderViewPublic) string {
-					return order.Id + " cardpay"
+				stream: streams.BillingServer,
+				terms: func(order *billingpb.OrderViewPublic) []string {
+					return []string{order.Id, "cardpay"}
 				},
-				setter: func(result *GetOrderLogsResponse, value *LogOrder) {
-					result.Create = append(result.Create, value)
-				},
-			},
-			{
-				group: cfg.AwsCloudWatchLogGroupManagementApi,
-				pattern: func(order *billingpb.OrderViewPublic) string {
-					return order.Id + " webhook"
+				token: func(req *GetOrderLogsRequest) string {
+					return req.CreateToken
 				},
-				setter: func(result *GetOrderLogsResponse, value *LogOrder) {
-					result.Callback = append(result.Callback, value)
+				setter: func(result *GetOrderLogsResponse, page []*LogOrder, nextToken string) {
+					result.Create, result.CreateNextToken = page, nextToken
 				},
 			},
 			{
-				group: cfg.AwsCloudWatchLogGroupWebhookNotifier,
-				pattern: func(order *billingpb.OrderViewPublic) string {
-					return order.Uuid + " delivery_try"
+				stream: streams.ManagementApi,
+				terms: func(order *billingpb.OrderViewPublic) []string {
+					return []string{order.Id, "webhook"}
+				},
+				token: func(req *GetOrderLogsRequest) string {
+					return req.CallbackToken
 				},
-				setter: func(result *GetOrderLogsResponse, value *LogOrder) {
-					result.Notify = append(result.Notify, value)
+				setter: func(result *GetOrderLogsResponse, page []*LogOrder, nextToken string) {
+					result.Callback, result.CallbackNextToken = page, nextToken
 				},
 			},
+			deliveries,
 		},
-		instance: cloudWatchLog,
+		deliveries: deliveries,
+		source:     orderLogSource,
 	}
 
 	return &OrderRoute{
-		dispatch:   set,
-		LMT:        &set.AwareSet,
-		cloudWatch: cloudWatch,
-		cfg:        *cfg,
+		dispatch:  set,
+		LMT:       &set.AwareSet,
+		orderLogs: orderLogs,
+		cfg:       *cfg,
 	}
 }
 
@@ -179,6 +295,7 @@ func (h *OrderRoute) Route(groups *common.Groups) {
 	groups.AuthUser.GET(orderIdPath, h.getOrderPublic)
 	groups.SystemUser.GET(orderIdPath, h.getOrderPublic)
 	groups.SystemUser.GET(orderGetLogsPath, h.getOrderLogs)
+	groups.AuthUser.GET(orderWebhooksPath, h.listWebhookDeliveries)
 
 	groups.AuthUser.POST(orderDownloadPath, h.downloadOrdersPublic)
 
@@ -295,7 +412,7 @@ func (h *OrderRoute) listOrdersPublic(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.FindAllOrdersPublic(ctx.Request().Context(), req)
@@ -412,7 +529,7 @@ func (h *OrderRoute) listRefunds(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.ListRefunds(ctx.Request().Context(), req)
@@ -444,7 +561,7 @@ func (h *OrderRoute) replaceCode(ctx echo.Context) error {
 
 	req.OrderId = ctx.Param("order_id")
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res := &billingpb.ChangeCodeInOrderResponse{}
@@ -499,7 +616,7 @@ func (h *OrderRoute) createRefund(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	req.CreatorId = authUser.Id
@@ -517,7 +634,7 @@ func (h *OrderRoute) createRefund(ctx echo.Context) error {
 }
 
 // @summary Get the order's logs list
-// @desc Get the order's logs list using the order ID
+// @desc Get the order's logs list using the order ID. The logs are paged separately, pass the next page tokens of the logs to get their next pages.
 // @id orderLogsPathListLogs
 // @tag Order
 // @accept application/json
@@ -526,8 +643,24 @@ func (h *OrderRoute) createRefund(ctx echo.Context) error {
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
 // @param order_id path {string} true The unique identifier for the order.
+// @param date_from query {integer} false The start date of the logs period in the Unix timestamp format. By default, the order's creation date.
+// @param date_to query {integer} false The end date of the logs period in the Unix timestamp format. By default, 7 days after the start date.
+// @param limit query {integer} false The maximum number of the records of each log on the page.
+// @param create_token query {string} false The next page token of the payment creation logs. If any token is passed only the logs with the tokens are returned.
+// @param callback_token query {string} false The next page token of the payment callback logs.
+// @param notify_token query {string} false The next page token of the payment notification logs.
 // @router /system/api/v1/order/{order_id}/logs [get]
 func (h *OrderRoute) getOrderLogs(ctx echo.Context) error {
+	req := &GetOrderLogsRequest{}
+
+	if err := new(echo.DefaultBinder).Bind(req, ctx); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
 	order, err := h.getOrder(ctx)
 
 	if err != nil {
@@ -540,44 +673,61 @@ func (h *OrderRoute) getOrderLogs(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
 	}
 
+	start, end, err := h.orderLogsPeriod(req.DateFrom, req.DateTo, createdAt)
+
+	if err != nil {
+		return err
+	}
+
+	limit := h.orderLogsLimit(req.Limit)
+	paged := req.CreateToken != "" || req.CallbackToken != "" || req.NotifyToken != ""
 	result := new(GetOrderLogsResponse)
 
-	for _, val := range h.cloudWatch.logSettings {
-		pattern := val.pattern(order)
-
-		rsp, err := h.cloudWatch.instance.FilterLogEventsWithContext(
-			ctx.Request().Context(),
-			&cloudwatchlogs.FilterLogEventsInput{
-				Limit:         aws.Int64(100),
-				LogGroupName:  aws.String(val.group),
-				StartTime:     aws.Int64(aws.TimeUnixMilli(createdAt)),
-				EndTime:       aws.Int64(aws.TimeUnixMilli(createdAt.AddDate(0, 0, 7))),
-				FilterPattern: aws.String(pattern),
-			},
-		)
+	for _, val := range h.orderLogs.logSettings {
+		token := val.token(req)
+
+		if paged && token == "" {
+			continue
+		}
+
+		query := &orderlog.Query{
+			Stream: val.stream,
+			Terms:  val.terms(order),
+			Start:  start,
+			End:    end,
+			Limit:  limit,
+			Token:  token,
+		}
+		page, err := h.orderLogs.source.Filter(ctx.Request().Context(), query)
+
+		if err == orderlog.ErrInvalidToken {
+			return echo.NewHTTPError(http.StatusBadRequest, common.ErrorOrderLogsTokenInvalid)
+		}
 
 		if err != nil {
 			h.dispatch.AwareSet.L().Error(
-				"get logs form amazon cloudwatch failed",
+				"get order logs failed",
 				logger.PairArgs(
-					"group", val.group,
-					"pattern", pattern,
+					"stream", val.stream,
+					"terms", query.Terms,
 				),
 				logger.WithPrettyFields(logger.Fields{"err": err}),
 			)
 			continue
 		}
 
-		for _, event := range rsp.Events {
+		var logOrders []*LogOrder
+
+		for _, event := range page.Events {
 			log := make(map[string]interface{})
-			err = json.Unmarshal([]byte(*event.Message), &log)
+			err = json.Unmarshal([]byte(event.Message), &log)
 
 			if err != nil {
 				continue
 			}
 
 			logOrder := &LogOrder{
-				Date: aws.MillisecondsTimeValue(event.Timestamp),
+				Date: event.Timestamp,
 				Uri:  log["msg"],
 				Request: &LogRequest{
 					Headers: log["request_headers"],
@@ -591,13 +741,393 @@ func (h *OrderRoute) getOrderLogs(ctx echo.Context) error {
 					},
 				},
 			}
-			val.setter(result, logOrder)
+			logOrders = append(logOrders, logOrder)
+		}
+		val.setter(result, logOrders, page.NextToken)
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// orderLogsPeriod returns the period of the order logs, the default period starts at the order creation
+func (h *OrderRoute) orderLogsPeriod(dateFrom, dateTo int64, createdAt time.Time) (time.Time, time.Time, error) {
+	window := h.cfg.OrderLogs.Window
+
+	if window <= 0 {
+		window = orderLogsWindowDefault
+	}
+
+	start := createdAt
+
+	if dateFrom > 0 {
+		start = time.Unix(dateFrom, 0)
+	}
+
+	end := start.Add(window)
+
+	if dateTo > 0 {
+		end = time.Unix(dateTo, 0)
+	}
+
+	windowMax := h.cfg.OrderLogs.WindowMax
+
+	if windowMax <= 0 {
+		windowMax = orderLogsWindowMaxDefault
+	}
+
+	if !end.After(start) || end.Sub(start) > windowMax {
+		return start, end, echo.NewHTTPError(http.StatusBadRequest, common.ErrorOrderLogsWindowInvalid)
+	}
+
+	return start, end, nil
+}
+
+// orderLogsLimit returns the requested number of the log events limited by the maximum one
+func (h *OrderRoute) orderLogsLimit(limit int64) int64 {
+	if limit <= 0 {
+		limit = h.cfg.OrderLogs.Limit
+	}
+
+	if limit <= 0 {
+		limit = orderLogsLimitDefault
+	}
+
+	limitMax := h.cfg.OrderLogs.LimitMax
+
+	if limitMax <= 0 {
+		limitMax = orderLogsLimitMaxDefault
+	}
+
+	if limit > limitMax {
+		limit = limitMax
+	}
+
+	return limit
+}
+
+// @summary Get the webhook delivery attempts
+// @desc Get the attempts to deliver the payment notifications to the webhooks of the merchant's projects. The orders paid before the end of the period are paged, the attempts of the orders on the page made within the period are returned. If the status is passed, the attempts with the status are paged by the next page token, the page may have fewer attempts than the limit if the scanned orders have no more of them.
+// @id orderWebhooksPathListWebhookDeliveries
+// @tag Order
+// @accept application/json
+// @produce application/json
+// @success 200 {object} ListWebhookDeliveriesResponse Returns the webhook delivery attempts
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project query {[]string} false The list of projects.
+// @param status query {string} false The delivery status. Available values: success, failed. The attempts with the status are paged by the next page token instead of the orders.
+// @param date_from query {integer} false The start date of the delivery attempts in the Unix timestamp format. By default, the order's creation date.
+// @param date_to query {integer} false The end date of the delivery attempts in the Unix timestamp format. By default, 7 days after the start date. Only the orders paid before it are listed.
+// @param limit query {integer} false The number of orders returned in one page, the number of attempts if the status is passed. Default value is 20.
+// @param offset query {integer} false The ranking number of the first order on the page. It isn't applied if the status is passed.
+// @param token query {string} false The next page token of the attempts with the status.
+// @router /admin/api/v1/order/webhooks [get]
+func (h *OrderRoute) listWebhookDeliveries(ctx echo.Context) error {
+	req := &ListWebhookDeliveriesRequest{}
+
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	if req.DateFrom > 0 && req.DateTo > 0 {
+		if _, _, err := h.orderLogsPeriod(req.DateFrom, req.DateTo, time.Time{}); err != nil {
+			return err
+		}
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = webhookDeliveriesLimitDefault
+	}
+
+	if req.Limit > webhookDeliveriesLimitMax {
+		req.Limit = webhookDeliveriesLimitMax
+	}
+
+	if req.Status != "" {
+		return h.listWebhookDeliveriesByStatus(ctx, req)
+	}
+
+	res, err := h.listWebhookDeliveryOrders(ctx, req, req.Limit, req.Offset)
+
+	if err != nil {
+		return err
+	}
+
+	result := &ListWebhookDeliveriesResponse{Items: []*WebhookDelivery{}}
+
+	if res == nil {
+		return ctx.JSON(http.StatusOK, result)
+	}
+
+	result.Count = res.Count
+	requested := 0
+
+	for i, deliveries := range h.webhookDeliveries(ctx, req, res.Items) {
+		if !deliveries.requested {
+			continue
+		}
+
+		requested++
+
+		if deliveries.failed {
+			result.FailedOrders = append(result.FailedOrders, res.Items[i].Uuid)
+			continue
 		}
+
+		result.Items = append(result.Items, deliveries.items...)
+	}
+
+	// the partial result is returned if the attempts of some orders are read, nothing is known about the page otherwise
+	if requested > 0 && len(result.FailedOrders) == requested {
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	return ctx.JSON(http.StatusOK, result)
 }
 
+// listWebhookDeliveriesByStatus pages the attempts with the status, the orders are scanned from the position
+// of the next page token until the page is filled or webhookDeliveriesScanMax orders are scanned
+func (h *OrderRoute) listWebhookDeliveriesByStatus(ctx echo.Context, req *ListWebhookDeliveriesRequest) error {
+	offset, skip, err := parseWebhookDeliveriesToken(req.Token)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorOrderLogsTokenInvalid)
+	}
+
+	result := &ListWebhookDeliveriesResponse{Items: []*WebhookDelivery{}}
+	requested := 0
+
+	respond := func() error {
+		if requested > 0 && len(result.FailedOrders) == requested {
+			return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		}
+
+		return ctx.JSON(http.StatusOK, result)
+	}
+
+	for scanned := 0; scanned < webhookDeliveriesScanMax; {
+		res, err := h.listWebhookDeliveryOrders(ctx, req, webhookDeliveriesLimitMax, offset)
+
+		if err != nil {
+			return err
+		}
+
+		if res == nil || len(res.Items) == 0 {
+			return respond()
+		}
+
+		result.Count = res.Count
+
+		for i, deliveries := range h.webhookDeliveries(ctx, req, res.Items) {
+			if deliveries.requested {
+				requested++
+			}
+
+			if deliveries.failed {
+				result.FailedOrders = append(result.FailedOrders, res.Items[i].Uuid)
+			}
+
+			matched := 0
+
+			for _, delivery := range deliveries.items {
+				if delivery.Success != (req.Status == webhookDeliveryStatusSuccess) {
+					continue
+				}
+
+				matched++
+
+				if matched <= skip {
+					continue
+				}
+
+				if int64(len(result.Items)) == req.Limit {
+					result.NextToken = webhookDeliveriesToken(offset, matched-1)
+					return respond()
+				}
+
+				result.Items = append(result.Items, delivery)
+			}
+
+			offset++
+			skip = 0
+		}
+
+		scanned += len(res.Items)
+
+		if offset >= res.Count {
+			return respond()
+		}
+	}
+
+	result.NextToken = webhookDeliveriesToken(offset, 0)
+
+	return respond()
+}
+
+// listWebhookDeliveryOrders returns the page of the merchant's orders paid before the end of the period,
+// the result is nil if the billing server returned no orders
+func (h *OrderRoute) listWebhookDeliveryOrders(
+	ctx echo.Context,
+	req *ListWebhookDeliveriesRequest,
+	limit, offset int64,
+) (*billingpb.ListOrdersPublicResponseItem, error) {
+	// the orders paid before the start of the period are listed too, their notifications can be retried within it
+	ordersReq := &billingpb.ListOrdersRequest{
+		Merchant: []string{req.MerchantId},
+		Project:  req.Project,
+		PmDateTo: req.DateTo,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	res, err := h.dispatch.Services.Billing.FindAllOrdersPublic(ctx.Request().Context(), ordersReq)
+
+	if err != nil {
+		return nil, h.dispatch.SrvCallHandler(ordersReq, err, billingpb.ServiceName, "FindAllOrdersPublic")
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return nil, echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return res.Item, nil
+}
+
+// webhookDeliveries reads the delivery attempts of the orders made within the period
+func (h *OrderRoute) webhookDeliveries(
+	ctx echo.Context,
+	req *ListWebhookDeliveriesRequest,
+	orders []*billingpb.OrderViewPublic,
+) []*orderWebhookDeliveries {
+	queries := make([]*orderlog.Query, len(orders))
+	pages := make([]*orderlog.Page, len(orders))
+	limit := h.orderLogsLimit(0)
+
+	for i, order := range orders {
+		createdAt, err := ptypes.Timestamp(order.CreatedAt)
+
+		if err != nil {
+			continue
+		}
+
+		// the attempts of the orders created after the end of the period are skipped
+		start, end, err := h.orderLogsPeriod(req.DateFrom, req.DateTo, createdAt)
+
+		if err != nil {
+			continue
+		}
+
+		queries[i] = &orderlog.Query{
+			Stream: h.orderLogs.deliveries.stream,
+			Terms:  h.orderLogs.deliveries.terms(order),
+			Start:  start,
+			End:    end,
+			Limit:  limit,
+		}
+	}
+
+	// the attempts of the orders are requested concurrently, the number of the simultaneous requests is bounded
+	// to stay within the request rate of the log source
+	sem := make(chan struct{}, webhookDeliveriesConcurrency)
+	wg := sync.WaitGroup{}
+
+	for i, query := range queries {
+		if query == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, query *orderlog.Query) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			page, err := h.orderLogs.source.Filter(ctx.Request().Context(), query)
+
+			if err != nil {
+				h.dispatch.AwareSet.L().Error(
+					"get webhook deliveries failed",
+					logger.PairArgs(
+						"stream", query.Stream,
+						"terms", query.Terms,
+					),
+					logger.WithPrettyFields(logger.Fields{"err": err}),
+				)
+				return
+			}
+
+			pages[i] = page
+		}(i, query)
+	}
+
+	wg.Wait()
+
+	result := make([]*orderWebhookDeliveries, len(orders))
+
+	for i, order := range orders {
+		result[i] = &orderWebhookDeliveries{requested: queries[i] != nil}
+
+		if queries[i] == nil {
+			continue
+		}
+
+		if pages[i] == nil {
+			result[i].failed = true
+			continue
+		}
+
+		for _, event := range pages[i].Events {
+			if event.Timestamp.Before(queries[i].Start) || event.Timestamp.After(queries[i].End) {
+				continue
+			}
+
+			delivery, ok := newWebhookDelivery(order, event)
+
+			if !ok {
+				continue
+			}
+
+			result[i].items = append(result[i].items, delivery)
+		}
+	}
+
+	return result
+}
+
+// webhookDeliveriesToken is the offset of the order and the number of its attempts with the status already returned
+func webhookDeliveriesToken(offset int64, skip int) string {
+	return strconv.FormatInt(offset, 10) + "." + strconv.Itoa(skip)
+}
+
+func parseWebhookDeliveriesToken(token string) (int64, int, error) {
+	if token == "" {
+		return 0, 0, nil
+	}
+
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 2 {
+		return 0, 0, orderlog.ErrInvalidToken
+	}
+
+	offset, err := strconv.ParseInt(parts[0], 10, 64)
+
+	if err != nil || offset < 0 {
+		return 0, 0, orderlog.ErrInvalidToken
+	}
+
+	skip, err := strconv.Atoi(parts[1])
+
+	if err != nil || skip < 0 {
+		return 0, 0, orderlog.ErrInvalidToken
+	}
+
+	return offset, skip, nil
+}
+
 func (h *OrderRoute) getOrder(ctx echo.Context) (*billingpb.OrderViewPublic, error) {
 	req := &billingpb.GetOrderRequest{}
 
@@ -617,3 +1147,87 @@ func (h *OrderRoute) getOrder(ctx echo.Context) (*billingpb.OrderViewPublic, err
 
 	return rsp.Item, nil
 }
+
+// newWebhookDelivery parses the delivery attempt logged by the webhook notifier
+func newWebhookDelivery(order *billingpb.OrderViewPublic, event *orderlog.Event) (*WebhookDelivery, bool) {
+	log := make(map[string]interface{})
+	decoder := json.NewDecoder(strings.NewReader(event.Message))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&log); err != nil {
+		return nil, false
+	}
+
+	delivery := &WebhookDelivery{
+		OrderId:    order.Uuid,
+		Date:       event.Timestamp,
+		StatusCode: int(logInt(log["response_status"])),
+		Retry:      logInt(log["try"]),
+	}
+
+	if order.Project != nil {
+		delivery.ProjectId = order.Project.Id
+	}
+
+	delivery.Success = delivery.StatusCode >= http.StatusOK && delivery.StatusCode < http.StatusMultipleChoices
+	delivery.Url, _ = log["url"].(string)
+
+	if delivery.Url == "" {
+		delivery.Url, _ = log["msg"].(string)
+	}
+
+	switch latency := log["latency"].(type) {
+	case json.Number:
+		delivery.Latency = logInt(latency)
+	case string:
+		if d, err := time.ParseDuration(latency); err == nil {
+			delivery.Latency = int64(d / time.Millisecond)
+		}
+	}
+
+	switch body := log["response_body"].(type) {
+	case string:
+		delivery.ResponseBody = body
+	case nil:
+	default:
+		b, _ := json.Marshal(body)
+		delivery.ResponseBody = string(b)
+	}
+
+	if len(delivery.ResponseBody) > webhookDeliveryExcerptSize {
+		size := webhookDeliveryExcerptSize
+
+		// the excerpt mustn't end with the part of the multibyte character
+		for size > 0 && !utf8.RuneStart(delivery.ResponseBody[size]) {
+			size--
+		}
+
+		delivery.ResponseBody = delivery.ResponseBody[:size]
+	}
+
+	return delivery, true
+}
+
+// logInt returns the integer value of the log field, the numbers and the numeric strings are supported
+func logInt(value interface{}) int64 {
+	var s string
+
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return 0
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(f)
+	}
+
+	return 0
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/globalsign/mgo/bson"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
@@ -14,21 +16,30 @@ import (
 
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
 	"github.com/paysuper/paysuper-management-api/internal/test"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
 	"github.com/stretchr/testify/assert"
 	mock2 "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type OrderTestSuite struct {
 	suite.Suite
-	router *OrderRoute
-	caller *test.EchoReqResCaller
+	router  *OrderRoute
+	caller  *test.EchoReqResCaller
+	workDir string
 }
 
 func Test_Order(t *testing.T) {
@@ -62,7 +73,8 @@ func (suite *OrderTestSuite) SetupTest() {
 
 	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
 		mw.Pre(test.PreAuthUserMiddleware(user))
-		suite.router = NewOrderRoute(set.HandlerSet, cloudwatchMock, set.GlobalConfig)
+		suite.workDir = set.Initial.WorkDir
+		suite.router = NewOrderRoute(set.HandlerSet, orderlog.NewCloudWatch(cloudwatchMock), set.GlobalConfig)
 		return common.Handlers{
 			suite.router,
 		}
@@ -113,6 +125,37 @@ func (suite *OrderTestSuite) TestOrder_GetRefund_RefundIdEmpty_Error() {
 	assert.Regexp(suite.T(), common.NewValidationError("RefundId"), httpErr.Message)
 }
 
+func (suite *OrderTestSuite) TestOrder_GetRefund_AllValidationErrors_Error() {
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + orderRefundsIdsPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			request.Header.Set(common.HeaderXValidationErrors, common.ValidationErrorsAll)
+		}).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+
+	res, ok := httpErr.Message.(*common.ValidationErrorsResponse)
+	assert.True(suite.T(), ok)
+	assert.NotEmpty(suite.T(), res.Errors)
+	assert.Equal(suite.T(), res.Errors[0].Code, res.Code)
+
+	var fields []string
+	for _, e := range res.Errors {
+		fields = append(fields, e.Field)
+		assert.Equal(suite.T(), common.ErrorValidationFailed.Code, e.Code)
+	}
+	assert.Contains(suite.T(), fields, "OrderId")
+	assert.Contains(suite.T(), fields, "RefundId")
+}
+
 func (suite *OrderTestSuite) TestOrder_GetRefund_OrderIdEmpty_Error() {
 
 	_, err := suite.caller.Builder().
@@ -315,6 +358,76 @@ func (suite *OrderTestSuite) TestOrder_CreateRefund_BillingServer_CreateError()
 	assert.Equal(suite.T(), mock.SomeError, httpErr.Message)
 }
 
+func (suite *OrderTestSuite) TestOrder_CreateRefund_IdempotencyKey_Replayed() {
+	data := `{"amount": 10, "reason": "test"}`
+	orderId := uuid.New().String()
+	reqInitKey := func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(common.HeaderIdempotencyKey, "refund-key")
+	}
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", orderId).
+		Path(common.AuthUserGroupPath + orderRefundsPath).
+		Init(test.ReqInitJSON()).
+		Init(reqInitKey).
+		BodyString(data).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, res.Code)
+	assert.Empty(suite.T(), res.Header().Get(common.HeaderIdempotentReplayed))
+
+	res1, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", orderId).
+		Path(common.AuthUserGroupPath + orderRefundsPath).
+		Init(test.ReqInitJSON()).
+		Init(reqInitKey).
+		BodyString(data).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, res1.Code)
+	assert.Equal(suite.T(), "true", res1.Header().Get(common.HeaderIdempotentReplayed))
+	assert.Equal(suite.T(), res.Body.String(), res1.Body.String())
+}
+
+func (suite *OrderTestSuite) TestOrder_CreateRefund_IdempotencyKey_ReusedWithAnotherBody() {
+	orderId := uuid.New().String()
+	reqInitKey := func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(common.HeaderIdempotencyKey, "refund-key")
+	}
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", orderId).
+		Path(common.AuthUserGroupPath + orderRefundsPath).
+		Init(test.ReqInitJSON()).
+		Init(reqInitKey).
+		BodyString(`{"amount": 10, "reason": "test"}`).
+		Exec(suite.T())
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, res.Code)
+
+	_, err = suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", orderId).
+		Path(common.AuthUserGroupPath + orderRefundsPath).
+		Init(test.ReqInitJSON()).
+		Init(reqInitKey).
+		BodyString(`{"amount": 20, "reason": "test"}`).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorIdempotencyKeyReused, httpErr.Message)
+}
+
 func (suite *OrderTestSuite) TestOrder_GetOrders_Ok() {
 	bs := &billMock.BillingService{}
 	bs.On("FindAllOrdersPublic", mock2.Anything, mock2.Anything, mock2.Anything).
@@ -655,6 +768,92 @@ func (suite *OrderTestSuite) TestOrder_GetOrderLogs_Ok() {
 	assert.NotEmpty(suite.T(), logs.Notify)
 }
 
+func (suite *OrderTestSuite) TestOrder_GetOrderLogs_RedactedWebhookLog_Ok() {
+	orderId := bson.NewObjectId().Hex()
+	reqBody := `{"merchant_order":{"id":"` + orderId + `"},"payment_method":"BANKCARD",` +
+		`"card_account":{"card":{"pan":"400000******0077","holder":"CARDHOLDER"}},` +
+		`"customer":{"email":"customer@unit.test","ip":"127.0.0.1"}}`
+
+	body := suite.getRedactedCallbackBody([]byte(reqBody))
+	assert.Contains(suite.T(), body, orderId)
+	assert.Contains(suite.T(), body, redact.Mask)
+	assert.NotContains(suite.T(), body, "400000******0077")
+	assert.NotContains(suite.T(), body, "CARDHOLDER")
+	assert.NotContains(suite.T(), body, "customer@unit.test")
+}
+
+func (suite *OrderTestSuite) TestOrder_GetOrderLogs_RedactedCardPayCallback_Ok() {
+	reqBody, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_payment.json")
+	require.NoError(suite.T(), err)
+
+	body := suite.getRedactedCallbackBody(reqBody)
+	assert.Contains(suite.T(), body, "5e95b0a7ff5d7c9a3c8d1b8e")
+	assert.Contains(suite.T(), body, redact.Mask)
+	assert.NotContains(suite.T(), body, "400000...0077")
+	assert.NotContains(suite.T(), body, "CARDHOLDER")
+	assert.NotContains(suite.T(), body, "customer@unit.test")
+	assert.NotContains(suite.T(), body, "127.0.0.1")
+}
+
+// getRedactedCallbackBody returns the request body of the callback log written with the default redaction rules
+func (suite *OrderTestSuite) getRedactedCallbackBody(reqBody []byte) string {
+	redactor, err := redact.New(&redact.Config{})
+	require.NoError(suite.T(), err)
+
+	headers := http.Header{
+		echo.HeaderContentType:                       []string{echo.MIMEApplicationJSON},
+		common.CardPayPaymentResponseHeaderSignature: []string{"signature"},
+	}
+
+	message, err := json.Marshal(map[string]interface{}{
+		"level":            "info",
+		"msg":              common.WebHookGroupPath + "/cardpay/payment",
+		"request_headers":  common.RequestResponseHeadersToString(redactor.Headers(headers)),
+		"request_body":     redactor.Body(reqBody),
+		"response_headers": common.RequestResponseHeadersToString(redactor.Headers(http.Header{})),
+		"response_body":    redactor.Body([]byte(`{"message":"ok"}`)),
+	})
+	require.NoError(suite.T(), err)
+
+	cloudwatchMock := &mock.CloudWatchInterface{}
+	cloudwatchMock.On("FilterLogEventsWithContext", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(
+			&cloudwatchlogs.FilterLogEventsOutput{
+				Events: []*cloudwatchlogs.FilteredLogEvent{
+					{Timestamp: aws.Int64(1586868621704), Message: aws.String(string(message))},
+				},
+			},
+			nil,
+		)
+	suite.router.orderLogs.source = orderlog.NewCloudWatch(cloudwatchMock)
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":order_id", "ace2fc5c-b8c2-4424-96e8-5b631a73b88a").
+		Path(common.SystemUserGroupPath + orderGetLogsPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), http.StatusOK, res.Code)
+
+	logs := new(GetOrderLogsResponse)
+	err = json.Unmarshal(res.Body.Bytes(), &logs)
+	require.NoError(suite.T(), err)
+	require.NotEmpty(suite.T(), logs.Callback)
+
+	callback := logs.Callback[0]
+	assert.Equal(suite.T(), common.WebHookGroupPath+"/cardpay/payment", callback.Uri)
+
+	reqHeaders, ok := callback.Request.Headers.(string)
+	require.True(suite.T(), ok)
+	assert.Contains(suite.T(), reqHeaders, common.CardPayPaymentResponseHeaderSignature+":"+redact.Mask)
+
+	body, ok := callback.Request.Body.(string)
+	require.True(suite.T(), ok)
+	return body
+}
+
 func (suite *OrderTestSuite) TestOrder_GetOrderLogs_GetOrderPublic_Error() {
 	billingMock := &billMock.BillingService{}
 	billingMock.On("GetOrderPublic", mock2.Anything, mock2.Anything, mock2.Anything).
@@ -714,7 +913,7 @@ func (suite *OrderTestSuite) TestOrder_GetOrderLogs_CloudWatchLog_Error() {
 	cloudwatchMock := &mock.CloudWatchInterface{}
 	cloudwatchMock.On("FilterLogEventsWithContext", mock2.Anything, mock2.Anything, mock2.Anything).
 		Return(nil, errors.New("some error"))
-	suite.router.cloudWatch.instance = cloudwatchMock
+	suite.router.orderLogs.source = orderlog.NewCloudWatch(cloudwatchMock)
 
 	res, err := suite.caller.Builder().
 		Method(http.MethodGet).
@@ -734,3 +933,537 @@ func (suite *OrderTestSuite) TestOrder_GetOrderLogs_CloudWatchLog_Error() {
 	assert.Nil(suite.T(), logs.Callback)
 	assert.Nil(suite.T(), logs.Notify)
 }
+
+func (suite *OrderTestSuite) TestOrder_GetOrderLogs_NextPage_Ok() {
+	var input *cloudwatchlogs.FilterLogEventsInput
+
+	cloudwatchMock := &mock.CloudWatchInterface{}
+	cloudwatchMock.On("FilterLogEventsWithContext", mock2.Anything, mock2.Anything, mock2.Anything).
+		Run(func(args mock2.Arguments) {
+			input = args.Get(1).(*cloudwatchlogs.FilterLogEventsInput)
+		}).
+		Return(
+			&cloudwatchlogs.FilterLogEventsOutput{
+				Events: []*cloudwatchlogs.FilteredLogEvent{
+					{Timestamp: aws.Int64(1586868621704), Message: aws.String(`{"msg":"/webhook/cardpay/payment"}`)},
+				},
+				NextToken: aws.String("next_token"),
+			},
+			nil,
+		)
+	suite.router.orderLogs.source = orderlog.NewCloudWatch(cloudwatchMock)
+
+	dateFrom := time.Now().Add(-time.Hour).Unix()
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":order_id", "ace2fc5c-b8c2-4424-96e8-5b631a73b88a").
+		Path(common.SystemUserGroupPath+orderGetLogsPath).
+		SetQueryParam("date_from", strconv.FormatInt(dateFrom, 10)).
+		SetQueryParam("date_to", strconv.FormatInt(dateFrom+3600, 10)).
+		SetQueryParam("limit", "10").
+		SetQueryParam("callback_token", "token").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	cloudwatchMock.AssertNumberOfCalls(suite.T(), "FilterLogEventsWithContext", 1)
+
+	require.NotNil(suite.T(), input)
+	assert.Equal(suite.T(), "token", aws.StringValue(input.NextToken))
+	assert.EqualValues(suite.T(), 10, aws.Int64Value(input.Limit))
+	assert.EqualValues(suite.T(), dateFrom*1000, aws.Int64Value(input.StartTime))
+
+	logs := new(GetOrderLogsResponse)
+	err = json.Unmarshal(res.Body.Bytes(), &logs)
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), logs.Create)
+	assert.Nil(suite.T(), logs.Notify)
+	assert.Len(suite.T(), logs.Callback, 1)
+	assert.Equal(suite.T(), "next_token", logs.CallbackNextToken)
+	assert.Empty(suite.T(), logs.CreateNextToken)
+}
+
+func (suite *OrderTestSuite) TestOrder_GetOrderLogs_FileSource_Ok() {
+	createdAt := time.Now().Add(-time.Hour)
+	order := &billingpb.OrderViewPublic{
+		Id:   bson.NewObjectId().Hex(),
+		Uuid: uuid.New().String(),
+	}
+	order.CreatedAt, _ = ptypes.TimestampProto(createdAt)
+
+	billingMock := &billMock.BillingService{}
+	billingMock.On("GetOrderPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(&billingpb.GetOrderPublicResponse{Status: billingpb.ResponseStatusOk, Item: order}, nil)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	record := func(ts time.Time, orderId, status string) string {
+		return fmt.Sprintf(
+			`{"level":"info","ts":%d,"msg":"/webhook/cardpay/payment","request_body":"{\"id\":\"%s\"}","response_status":%s}`,
+			ts.Unix(), orderId, status,
+		)
+	}
+	lines := []string{
+		record(createdAt.Add(-time.Minute), order.Id, "200"),
+		record(createdAt.Add(time.Minute), order.Id, "500"),
+		record(createdAt.Add(2*time.Minute), bson.NewObjectId().Hex(), "200"),
+		record(createdAt.Add(3*time.Minute), order.Id, "200"),
+	}
+
+	dir, err := ioutil.TempDir("", "order_logs")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "management_api.log")
+	err = ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	require.NoError(suite.T(), err)
+
+	suite.router.orderLogs.source = orderlog.NewFile(&orderlog.FileConfig{TimestampField: "ts"})
+	for _, settings := range suite.router.orderLogs.logSettings {
+		settings.stream = path
+	}
+
+	getLogs := func(token string) *GetOrderLogsResponse {
+		res, err := suite.caller.Builder().
+			Method(http.MethodGet).
+			Params(":order_id", order.Uuid).
+			Path(common.SystemUserGroupPath+orderGetLogsPath).
+			SetQueryParam("limit", "1").
+			SetQueryParam("callback_token", token).
+			Init(test.ReqInitJSON()).
+			Exec(suite.T())
+
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), http.StatusOK, res.Code)
+
+		logs := new(GetOrderLogsResponse)
+		err = json.Unmarshal(res.Body.Bytes(), &logs)
+		require.NoError(suite.T(), err)
+		return logs
+	}
+
+	logs := getLogs("")
+	require.Len(suite.T(), logs.Callback, 1)
+	assert.EqualValues(suite.T(), 500, logs.Callback[0].Response.HttpStatus)
+	assert.Empty(suite.T(), logs.Notify)
+	require.NotEmpty(suite.T(), logs.CallbackNextToken)
+
+	logs = getLogs(logs.CallbackNextToken)
+	require.Len(suite.T(), logs.Callback, 1)
+	assert.EqualValues(suite.T(), 200, logs.Callback[0].Response.HttpStatus)
+	assert.Nil(suite.T(), logs.Create)
+
+	logs = getLogs(logs.CallbackNextToken)
+	assert.Empty(suite.T(), logs.Callback)
+	assert.Empty(suite.T(), logs.CallbackNextToken)
+}
+
+func (suite *OrderTestSuite) TestOrder_GetOrderLogs_InvalidToken_Error() {
+	suite.router.orderLogs.source = orderlog.NewFile(&orderlog.FileConfig{TimestampField: "ts"})
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":order_id", "ace2fc5c-b8c2-4424-96e8-5b631a73b88a").
+		Path(common.SystemUserGroupPath+orderGetLogsPath).
+		SetQueryParam("notify_token", "invalid").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.EqualValues(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorOrderLogsTokenInvalid, httpErr.Message)
+}
+
+func (suite *OrderTestSuite) TestOrder_GetOrderLogs_InvalidPeriod_Error() {
+	dateFrom := time.Now().Unix()
+	periods := []string{
+		strconv.FormatInt(dateFrom-1, 10),
+		strconv.FormatInt(dateFrom+int64((1000*24*time.Hour).Seconds()), 10),
+	}
+
+	for _, dateTo := range periods {
+		_, err := suite.caller.Builder().
+			Method(http.MethodGet).
+			Params(":order_id", "ace2fc5c-b8c2-4424-96e8-5b631a73b88a").
+			Path(common.SystemUserGroupPath+orderGetLogsPath).
+			SetQueryParam("date_from", strconv.FormatInt(dateFrom, 10)).
+			SetQueryParam("date_to", dateTo).
+			Init(test.ReqInitJSON()).
+			Exec(suite.T())
+
+		assert.Error(suite.T(), err)
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.EqualValues(suite.T(), http.StatusBadRequest, httpErr.Code)
+		assert.Equal(suite.T(), common.ErrorOrderLogsWindowInvalid, httpErr.Message)
+	}
+}
+
+func (suite *OrderTestSuite) TestOrder_ListWebhookDeliveries_Ok() {
+	createdAt := time.Now().Add(-time.Hour)
+	orders := []*billingpb.OrderViewPublic{
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+	}
+	for _, order := range orders {
+		order.CreatedAt, _ = ptypes.TimestampProto(createdAt)
+	}
+
+	var ordersReq *billingpb.ListOrdersRequest
+	billingMock := &billMock.BillingService{}
+	billingMock.On("FindAllOrdersPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Run(func(args mock2.Arguments) {
+			ordersReq = args.Get(1).(*billingpb.ListOrdersRequest)
+		}).
+		Return(
+			&billingpb.ListOrdersPublicResponse{
+				Status: billingpb.ResponseStatusOk,
+				Item:   &billingpb.ListOrdersPublicResponseItem{Count: 2, Items: orders},
+			},
+			nil,
+		)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	record := func(ts time.Time, orderUuid string, status, try int, body string) string {
+		return fmt.Sprintf(
+			`{"level":"info","ts":%d,"msg":"delivery_try","order_id":"%s","url":"https://project.unit.test/webhook",`+
+				`"response_status":%d,"latency":"150ms","try":%d,"response_body":%q}`,
+			ts.Unix(), orderUuid, status, try, body,
+		)
+	}
+	lines := []string{
+		record(createdAt.Add(time.Minute), orders[0].Uuid, 500, 1, "internal error"),
+		record(createdAt.Add(2*time.Minute), uuid.New().String(), 200, 1, "ok"),
+		record(createdAt.Add(3*time.Minute), orders[0].Uuid, 200, 2, strings.Repeat("ы", webhookDeliveryExcerptSize)),
+		record(createdAt.Add(4*time.Minute), orders[1].Uuid, 200, 1, "ok"),
+	}
+
+	dir, err := ioutil.TempDir("", "order_logs")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "webhook_notifier.log")
+	err = ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	require.NoError(suite.T(), err)
+
+	suite.router.orderLogs.source = orderlog.NewFile(&orderlog.FileConfig{TimestampField: "ts"})
+	suite.router.orderLogs.deliveries.stream = path
+
+	listDeliveries := func(dateFrom, dateTo time.Time) *ListWebhookDeliveriesResponse {
+		builder := suite.caller.Builder().
+			Method(http.MethodGet).
+			Path(common.AuthUserGroupPath + orderWebhooksPath)
+
+		if !dateFrom.IsZero() {
+			builder = builder.
+				SetQueryParam("date_from", strconv.FormatInt(dateFrom.Unix(), 10)).
+				SetQueryParam("date_to", strconv.FormatInt(dateTo.Unix(), 10))
+		}
+
+		res, err := builder.Init(test.ReqInitJSON()).Exec(suite.T())
+
+		require.NoError(suite.T(), err)
+		require.Equal(suite.T(), http.StatusOK, res.Code)
+
+		deliveries := new(ListWebhookDeliveriesResponse)
+		err = json.Unmarshal(res.Body.Bytes(), &deliveries)
+		require.NoError(suite.T(), err)
+		return deliveries
+	}
+
+	deliveries := listDeliveries(time.Time{}, time.Time{})
+	require.NotNil(suite.T(), ordersReq)
+	assert.Equal(suite.T(), []string{"ffffffffffffffffffffffff"}, ordersReq.Merchant)
+	assert.EqualValues(suite.T(), webhookDeliveriesLimitDefault, ordersReq.Limit)
+	assert.Zero(suite.T(), ordersReq.PmDateTo)
+
+	assert.EqualValues(suite.T(), 2, deliveries.Count)
+	require.Len(suite.T(), deliveries.Items, 3)
+
+	failed := deliveries.Items[0]
+	assert.Equal(suite.T(), orders[0].Uuid, failed.OrderId)
+	assert.Equal(suite.T(), "https://project.unit.test/webhook", failed.Url)
+	assert.Equal(suite.T(), http.StatusInternalServerError, failed.StatusCode)
+	assert.EqualValues(suite.T(), 150, failed.Latency)
+	assert.EqualValues(suite.T(), 1, failed.Retry)
+	assert.Equal(suite.T(), "internal error", failed.ResponseBody)
+	assert.False(suite.T(), failed.Success)
+
+	retried := deliveries.Items[1]
+	assert.EqualValues(suite.T(), 2, retried.Retry)
+	assert.True(suite.T(), retried.Success)
+	assert.Len(suite.T(), retried.ResponseBody, webhookDeliveryExcerptSize)
+	assert.Equal(suite.T(), orders[1].Uuid, deliveries.Items[2].OrderId)
+
+	// the period is applied to the delivery attempts, the orders paid before its start are listed
+	dateFrom := createdAt.Add(2 * time.Minute)
+	dateTo := createdAt.Add(3*time.Minute + time.Second)
+	deliveries = listDeliveries(dateFrom, dateTo)
+	assert.Zero(suite.T(), ordersReq.PmDateFrom)
+	assert.Equal(suite.T(), dateTo.Unix(), ordersReq.PmDateTo)
+	require.Len(suite.T(), deliveries.Items, 1)
+	assert.Equal(suite.T(), orders[0].Uuid, deliveries.Items[0].OrderId)
+	assert.EqualValues(suite.T(), 2, deliveries.Items[0].Retry)
+}
+
+func (suite *OrderTestSuite) TestOrder_ListWebhookDeliveries_PartialResult() {
+	createdAt := time.Now().Add(-time.Hour)
+	orders := []*billingpb.OrderViewPublic{
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+	}
+	for _, order := range orders {
+		order.CreatedAt, _ = ptypes.TimestampProto(createdAt)
+	}
+
+	billingMock := &billMock.BillingService{}
+	billingMock.On("FindAllOrdersPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(
+			&billingpb.ListOrdersPublicResponse{
+				Status: billingpb.ResponseStatusOk,
+				Item:   &billingpb.ListOrdersPublicResponseItem{Count: 2, Items: orders},
+			},
+			nil,
+		)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	source := &failingOrderLogSource{
+		failed: map[string]bool{orders[0].Uuid: true},
+		events: []*orderlog.Event{
+			{
+				Timestamp: createdAt.Add(time.Minute),
+				Message:   `{"msg":"delivery_try","url":"https://project.unit.test/webhook","response_status":200,"try":1}`,
+			},
+		},
+	}
+	suite.router.orderLogs.source = source
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + orderWebhooksPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), http.StatusOK, res.Code)
+
+	deliveries := new(ListWebhookDeliveriesResponse)
+	err = json.Unmarshal(res.Body.Bytes(), &deliveries)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{orders[0].Uuid}, deliveries.FailedOrders)
+	require.Len(suite.T(), deliveries.Items, 1)
+	assert.Equal(suite.T(), orders[1].Uuid, deliveries.Items[0].OrderId)
+
+	source.failed[orders[1].Uuid] = true
+
+	_, err = suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.AuthUserGroupPath + orderWebhooksPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.EqualValues(suite.T(), http.StatusInternalServerError, httpErr.Code)
+}
+
+func (suite *OrderTestSuite) TestOrder_ListWebhookDeliveries_Status() {
+	createdAt := time.Now().Add(-time.Hour)
+	orders := []*billingpb.OrderViewPublic{
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+		{Id: bson.NewObjectId().Hex(), Uuid: uuid.New().String()},
+	}
+	for _, order := range orders {
+		order.CreatedAt, _ = ptypes.TimestampProto(createdAt)
+	}
+
+	var ordersReqs []*billingpb.ListOrdersRequest
+	billingMock := &billMock.BillingService{}
+
+	for i := range orders {
+		offset := int64(i)
+		billingMock.On(
+			"FindAllOrdersPublic",
+			mock2.Anything,
+			mock2.MatchedBy(func(req *billingpb.ListOrdersRequest) bool { return req.Offset == offset }),
+			mock2.Anything,
+		).
+			Run(func(args mock2.Arguments) {
+				ordersReqs = append(ordersReqs, args.Get(1).(*billingpb.ListOrdersRequest))
+			}).
+			Return(
+				&billingpb.ListOrdersPublicResponse{
+					Status: billingpb.ResponseStatusOk,
+					Item:   &billingpb.ListOrdersPublicResponseItem{Count: 3, Items: orders[offset:]},
+				},
+				nil,
+			)
+	}
+	suite.router.dispatch.Services.Billing = billingMock
+
+	record := func(minutes time.Duration, orderUuid string, status, try int) string {
+		return fmt.Sprintf(
+			`{"level":"info","ts":%d,"msg":"delivery_try","order_id":"%s","url":"https://project.unit.test/webhook",`+
+				`"response_status":%d,"latency":"150ms","try":%d,"response_body":"body"}`,
+			createdAt.Add(minutes*time.Minute).Unix(), orderUuid, status, try,
+		)
+	}
+	lines := []string{
+		record(1, orders[0].Uuid, 500, 1),
+		record(2, orders[0].Uuid, 200, 2),
+		record(3, orders[1].Uuid, 502, 1),
+		record(4, orders[1].Uuid, 0, 2),
+		record(5, orders[1].Uuid, 204, 3),
+		record(6, orders[2].Uuid, 200, 1),
+	}
+
+	dir, err := ioutil.TempDir("", "order_logs")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "webhook_notifier.log")
+	err = ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	require.NoError(suite.T(), err)
+
+	suite.router.orderLogs.source = orderlog.NewFile(&orderlog.FileConfig{TimestampField: "ts"})
+	suite.router.orderLogs.deliveries.stream = path
+
+	type attempt struct {
+		order int
+		retry int64
+	}
+
+	cases := []struct {
+		name      string
+		status    string
+		limit     string
+		token     string
+		expected  []attempt
+		nextToken string
+	}{
+		{
+			name:      "failed first page",
+			status:    webhookDeliveryStatusFailed,
+			limit:     "2",
+			expected:  []attempt{{order: 0, retry: 1}, {order: 1, retry: 1}},
+			nextToken: "1.1",
+		},
+		{
+			name:     "failed last page",
+			status:   webhookDeliveryStatusFailed,
+			limit:    "2",
+			token:    "1.1",
+			expected: []attempt{{order: 1, retry: 2}},
+		},
+		{
+			name:     "success",
+			status:   webhookDeliveryStatusSuccess,
+			expected: []attempt{{order: 0, retry: 2}, {order: 1, retry: 3}, {order: 2, retry: 1}},
+		},
+		{
+			name:      "success by one",
+			status:    webhookDeliveryStatusSuccess,
+			limit:     "1",
+			token:     "0.1",
+			expected:  []attempt{{order: 1, retry: 3}},
+			nextToken: "2.0",
+		},
+	}
+
+	for _, c := range cases {
+		ordersReqs = nil
+		builder := suite.caller.Builder().
+			Method(http.MethodGet).
+			Path(common.AuthUserGroupPath+orderWebhooksPath).
+			SetQueryParam("status", c.status)
+
+		if c.limit != "" {
+			builder = builder.SetQueryParam("limit", c.limit)
+		}
+
+		if c.token != "" {
+			builder = builder.SetQueryParam("token", c.token)
+		}
+
+		res, err := builder.Init(test.ReqInitJSON()).Exec(suite.T())
+		require.NoError(suite.T(), err, c.name)
+		require.Equal(suite.T(), http.StatusOK, res.Code, c.name)
+
+		deliveries := new(ListWebhookDeliveriesResponse)
+		err = json.Unmarshal(res.Body.Bytes(), &deliveries)
+		require.NoError(suite.T(), err, c.name)
+
+		// the orders are scanned by the batches of the maximum size instead of the limit of the attempts
+		require.NotEmpty(suite.T(), ordersReqs, c.name)
+		assert.EqualValues(suite.T(), webhookDeliveriesLimitMax, ordersReqs[0].Limit, c.name)
+
+		assert.EqualValues(suite.T(), 3, deliveries.Count, c.name)
+		assert.Equal(suite.T(), c.nextToken, deliveries.NextToken, c.name)
+		require.Len(suite.T(), deliveries.Items, len(c.expected), c.name)
+
+		for i, expected := range c.expected {
+			assert.Equal(suite.T(), orders[expected.order].Uuid, deliveries.Items[i].OrderId, c.name)
+			assert.Equal(suite.T(), expected.retry, deliveries.Items[i].Retry, c.name)
+			assert.Equal(suite.T(), c.status == webhookDeliveryStatusSuccess, deliveries.Items[i].Success, c.name)
+		}
+	}
+}
+
+func (suite *OrderTestSuite) TestOrder_ListWebhookDeliveries_ValidationError() {
+	cases := []struct {
+		name    string
+		params  map[string]string
+		message *billingpb.ResponseErrorMessage
+	}{
+		{name: "negative limit", params: map[string]string{"limit": "-1"}},
+		{name: "unknown status", params: map[string]string{"status": "pending"}},
+		{
+			name:    "invalid token",
+			params:  map[string]string{"status": webhookDeliveryStatusFailed, "token": "1"},
+			message: common.ErrorOrderLogsTokenInvalid,
+		},
+		{
+			name:    "negative token",
+			params:  map[string]string{"status": webhookDeliveryStatusFailed, "token": "-1.0"},
+			message: common.ErrorOrderLogsTokenInvalid,
+		},
+	}
+
+	for _, c := range cases {
+		builder := suite.caller.Builder().
+			Method(http.MethodGet).
+			Path(common.AuthUserGroupPath + orderWebhooksPath)
+
+		for name, value := range c.params {
+			builder = builder.SetQueryParam(name, value)
+		}
+
+		_, err := builder.Init(test.ReqInitJSON()).Exec(suite.T())
+
+		require.Error(suite.T(), err, c.name)
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(suite.T(), ok, c.name)
+		assert.EqualValues(suite.T(), http.StatusBadRequest, httpErr.Code, c.name)
+
+		if c.message != nil {
+			assert.Equal(suite.T(), c.message, httpErr.Message, c.name)
+		}
+	}
+}
+
+// failingOrderLogSource returns the same events for every order except the failed ones
+type failingOrderLogSource struct {
+	failed map[string]bool
+	events []*orderlog.Event
+}
+
+func (s *failingOrderLogSource) Filter(_ context.Context, query *orderlog.Query) (*orderlog.Page, error) {
+	if s.failed[query.Terms[0]] {
+		return nil, errors.New("log source is unavailable")
+	}
+	return &orderlog.Page{Events: s.events}, nil
+}
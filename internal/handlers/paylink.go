@@ -79,13 +79,13 @@ func (h *PayLinkRoute) getPaylinksList(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinks(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinks", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -114,13 +114,13 @@ func (h *PayLinkRoute) getPaylink(ctx echo.Context) error {
 
 	err := h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylink(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylink", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -158,7 +158,7 @@ func (h *PayLinkRoute) getPaylinkUrl(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkURL", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 
 	if res.Status != http.StatusOK {
@@ -189,13 +189,13 @@ func (h *PayLinkRoute) deletePaylink(ctx echo.Context) error {
 	}
 
 	if err := h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeletePaylink(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "DeletePaylink", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -248,13 +248,13 @@ func (h *PayLinkRoute) createOrUpdatePaylink(ctx echo.Context, paylinkId string)
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreateOrUpdatePaylink(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "CreateOrUpdatePaylink", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -285,13 +285,13 @@ func (h *PayLinkRoute) getPaylinkStatSummary(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinkStatTotal(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkStatTotal", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -322,13 +322,13 @@ func (h *PayLinkRoute) getPaylinkStatByCountry(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinkStatByCountry(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkStatByCountry", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -359,13 +359,13 @@ func (h *PayLinkRoute) getPaylinkStatByReferrer(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinkStatByReferrer(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkStatByReferrer", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -396,13 +396,13 @@ func (h *PayLinkRoute) getPaylinkStatByDate(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinkStatByDate(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkStatByDate", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -433,13 +433,13 @@ func (h *PayLinkRoute) getPaylinkStatByUtm(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinkStatByUtm(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkStatByUtm", req)
-		return ctx.Render(http.StatusBadRequest, errorTemplateName, map[string]interface{}{})
+		return ctx.Render(common.SrvCallStatus(err, http.StatusBadRequest), errorTemplateName, map[string]interface{}{})
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -470,13 +470,13 @@ func (h *PayLinkRoute) getPaylinkTransactions(ctx echo.Context) error {
 
 	err := h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaylinkTransactions(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaylinkTransactions", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != http.StatusOK {
@@ -92,7 +92,7 @@ func (h *PaymentCostRoute) getPaymentChannelCostSystem(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaymentChannelCostSystem(ctx.Request().Context(), req)
@@ -100,7 +100,7 @@ func (h *PaymentCostRoute) getPaymentChannelCostSystem(ctx echo.Context) error {
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaymentChannelCostSystem", req)
 
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -139,14 +139,14 @@ func (h *PaymentCostRoute) getPaymentChannelCostMerchant(ctx echo.Context) error
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaymentChannelCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetPaymentChannelCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -185,14 +185,14 @@ func (h *PaymentCostRoute) getMoneyBackCostSystem(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetMoneyBackCostSystem(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMoneyBackCostSystem", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -233,14 +233,14 @@ func (h *PaymentCostRoute) getMoneyBackCostMerchant(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetMoneyBackCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMoneyBackCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -266,14 +266,14 @@ func (h *PaymentCostRoute) deletePaymentChannelCostSystem(ctx echo.Context) erro
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeletePaymentChannelCostSystem(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "DeletePaymentChannelCostSystem", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -299,14 +299,14 @@ func (h *PaymentCostRoute) deletePaymentChannelCostMerchant(ctx echo.Context) er
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeletePaymentChannelCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "DeletePaymentChannelCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -332,14 +332,14 @@ func (h *PaymentCostRoute) deleteMoneyBackCostSystem(ctx echo.Context) error {
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeleteMoneyBackCostSystem(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "DeleteMoneyBackCostSystem", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -365,14 +365,14 @@ func (h *PaymentCostRoute) deleteMoneyBackCostMerchant(ctx echo.Context) error {
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeleteMoneyBackCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "DeleteMoneyBackCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -421,14 +421,14 @@ func (h *PaymentCostRoute) setPaymentChannelCostSystem(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.SetPaymentChannelCostSystem(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SetPaymentChannelCostSystem", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -481,14 +481,14 @@ func (h *PaymentCostRoute) setPaymentChannelCostMerchant(ctx echo.Context) error
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.SetPaymentChannelCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SetPaymentChannelCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -521,7 +521,7 @@ func (h *PaymentCostRoute) setPaymentChannelCostMerchant(ctx echo.Context) error
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
 // @param id path {string} true The unique identifier for the cost.
-// @router /system/api/v1/payment_costs/money_back/system/{id} [post]
+// @router /system/api/v1/payment_costs/money_back/system/{id} [put]
 func (h *PaymentCostRoute) setMoneyBackCostSystem(ctx echo.Context) error {
 	req := &billingpb.MoneyBackCostSystem{}
 	err := ctx.Bind(req)
@@ -537,14 +537,14 @@ func (h *PaymentCostRoute) setMoneyBackCostSystem(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.SetMoneyBackCostSystem(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SetMoneyBackCostSystem", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -597,14 +597,14 @@ func (h *PaymentCostRoute) setMoneyBackCostMerchant(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.SetMoneyBackCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SetMoneyBackCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -629,7 +629,7 @@ func (h *PaymentCostRoute) getAllPaymentChannelCostSystem(ctx echo.Context) erro
 
 	if err != nil {
 		h.L().Error(billingpb.ErrorGrpcServiceCallFailed, logger.PairArgs("err", err.Error(), common.ErrorFieldService, billingpb.ServiceName, common.ErrorFieldMethod, "GetAllPaymentChannelCostSystem"))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -655,14 +655,14 @@ func (h *PaymentCostRoute) getAllPaymentChannelCostMerchant(ctx echo.Context) er
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetAllPaymentChannelCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetAllPaymentChannelCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -687,7 +687,7 @@ func (h *PaymentCostRoute) getAllMoneyBackCostSystem(ctx echo.Context) error {
 
 	if err != nil {
 		h.L().Error(billingpb.ErrorGrpcServiceCallFailed, logger.PairArgs("err", err.Error(), common.ErrorFieldService, billingpb.ServiceName, common.ErrorFieldMethod, "GetAllMoneyBackCostSystem"))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -713,14 +713,14 @@ func (h *PaymentCostRoute) getAllMoneyBackCostMerchant(ctx echo.Context) error {
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetAllMoneyBackCostMerchant(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetAllMoneyBackCostMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != http.StatusOK {
@@ -87,13 +87,13 @@ func (h *PaymentMethodApiV1) createOrUpdatePaymentMethod(ctx echo.Context) error
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreateOrUpdatePaymentMethod(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -126,13 +126,13 @@ func (h *PaymentMethodApiV1) getProductionSettings(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaymentMethodProductionSettings(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -184,13 +184,13 @@ func (h *PaymentMethodApiV1) createOrUpdateProductionSettings(ctx echo.Context)
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreateOrUpdatePaymentMethodProductionSettings(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -223,13 +223,13 @@ func (h *PaymentMethodApiV1) deleteProductionSettings(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeletePaymentMethodProductionSettings(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -262,13 +262,13 @@ func (h *PaymentMethodApiV1) getTestSettings(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPaymentMethodTestSettings(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -319,13 +319,13 @@ func (h *PaymentMethodApiV1) createOrUpdateTestSettings(ctx echo.Context) error
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreateOrUpdatePaymentMethodTestSettings(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -359,13 +359,13 @@ func (h *PaymentMethodApiV1) deleteTestSettings(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.DeletePaymentMethodTestSettings(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -0,0 +1,608 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/callbackinbox"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/webhookadapter"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/paysuper/paysuper-proto/go/recurringpb"
+	"net/http"
+)
+
+const (
+	paymentSystemWebHookPath = "/:provider/:event"
+	callbackInboxPath        = "/callbacks/inbox"
+	callbackInboxIdPath      = "/callbacks/inbox/:callback_id"
+	callbackInboxReplayPath  = "/callbacks/inbox/:callback_id/replay"
+
+	callbackInboxLimitDefault = 20
+	callbackInboxLimitMax     = 100
+)
+
+// defaultWebhookProviders accept the callbacks when the config doesn't set the providers
+var defaultWebhookProviders = []string{webhookadapter.CardPayName}
+
+type ListCallbackInboxRequest struct {
+	// The callback status. Available values: pending, processed, rejected, stuck.
+	Status string `query:"status" validate:"omitempty,oneof=pending processed rejected stuck"`
+	// The number of callbacks returned in one page. Default value is 20.
+	Limit int `query:"limit" validate:"omitempty,gt=0"`
+	// The ranking number of the first callback on the page.
+	Offset int `query:"offset" validate:"omitempty,gte=0"`
+}
+
+type ListCallbackInboxResponse struct {
+	// The total number of the callbacks.
+	Count int `json:"count"`
+	// The list of the callbacks starting from the latest one.
+	Items []*callbackinbox.Callback `json:"items"`
+}
+
+type PaymentSystemWebHook struct {
+	dispatch common.HandlerSet
+	cfg      common.Config
+	adapters *webhookadapter.Registry
+	inbox    *callbackinbox.Inbox
+	provider.LMT
+}
+
+// NewPaymentSystemWebHook creates the handler of the payment system callbacks, the callbacks are forwarded
+// to the billing directly if the inbox is nil
+func NewPaymentSystemWebHook(set common.HandlerSet, inbox *callbackinbox.Inbox, cfg *common.Config) (*PaymentSystemWebHook, error) {
+	names := cfg.WebhookProviders
+
+	if len(names) == 0 {
+		names = defaultWebhookProviders
+	}
+
+	adapters := webhookadapter.NewRegistry()
+
+	for _, name := range names {
+		adapter, err := webhookadapter.New(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = adapters.Register(adapter); err != nil {
+			return nil, err
+		}
+	}
+
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "PaymentSystemWebHook"})
+	return &PaymentSystemWebHook{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      *cfg,
+		adapters: adapters,
+		inbox:    inbox,
+	}, nil
+}
+
+func (h *PaymentSystemWebHook) Route(groups *common.Groups) {
+	groups.WebHooks.POST(paymentSystemWebHookPath, h.callback)
+	groups.SystemUser.GET(callbackInboxPath, h.listInbox)
+	groups.SystemUser.GET(callbackInboxIdPath, h.getInboxCallback)
+	groups.SystemUser.POST(callbackInboxReplayPath, h.replayInboxCallback)
+}
+
+// @summary Process the CardPay payment notification
+// @desc Process the notification of the payment status sent by CardPay
+// @id cardPayWebHookPaymentNotifyPathPaymentCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @body billingpb.CardPayPaymentCallback
+// @success 200 {object} billingpb.ResponseErrorMessage Returns the message of the notification processing result
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data or the notification signature
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /webhook/cardpay/payment [post]
+
+// @summary Process the CardPay payment notification
+// @desc Process the notification of the payment status sent by CardPay
+// @id cardPayWebHookPaymentUpperCaseNotifyPathPaymentCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @body billingpb.CardPayPaymentCallback
+// @success 200 {object} billingpb.ResponseErrorMessage Returns the message of the notification processing result
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data or the notification signature
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /webhook/cardpay/PAYMENT [post]
+
+// @summary Process the CardPay recurring payment notification
+// @desc Process the notification of the recurring payment status sent by CardPay and update its saved card and subscription
+// @id cardPayWebHookRecurringUpperCaseNotifyPathPaymentCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @body webhookadapter.CardPayRecurringCallback
+// @success 200 {object} billingpb.ResponseErrorMessage Returns the message of the notification processing result
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data or the notification signature
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error or the saved card and the subscription aren't updated
+// @router /webhook/cardpay/RECURRING [post]
+
+// @summary Process the CardPay refund notification
+// @desc Process the notification of the refund status sent by CardPay
+// @id cardPayWebHookRefundNotifyPathRefundCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @body billingpb.CardPayRefundCallback
+// @success 200 {object} billingpb.ResponseErrorMessage Returns the message of the notification processing result if any
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data or the notification signature
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /webhook/cardpay/refund [post]
+
+// @summary Process the CardPay refund notification
+// @desc Process the notification of the refund status sent by CardPay
+// @id cardPayWebHookRefundUpperCaseNotifyPathRefundCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @body billingpb.CardPayRefundCallback
+// @success 200 {object} billingpb.ResponseErrorMessage Returns the message of the notification processing result if any
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data or the notification signature
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /webhook/cardpay/REFUND [post]
+
+// @summary Process the sample acquirer notification
+// @desc Process the notification of the payment or the refund status sent by the sample acquirer, the event is payment or refund
+// @id sampleWebHookCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @param event path {string} true The callback event: payment or refund.
+// @body webhookadapter.SampleCallback
+// @success 200 {object} billingpb.ResponseErrorMessage Returns the accepted status
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data or the notification signature
+// @failure 422 {object} billingpb.ResponseErrorMessage The notification is declined by the billing
+// @failure 503 {object} billingpb.ResponseErrorMessage The notification must be retried
+// @router /webhook/sample/{event} [post]
+func (h *PaymentSystemWebHook) callback(ctx echo.Context) error {
+	adapter, ok := h.adapters.Get(ctx.Param("provider"))
+
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackProviderUnknown)
+	}
+
+	body := common.ExtractRawBodyContext(ctx)
+	callback, err := adapter.Decode(ctx.Param("event"), body)
+
+	if err == webhookadapter.ErrUnknownEvent {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackProviderUnknown)
+	}
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err = h.dispatch.Validate.Struct(callback.Message); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	saved := &callbackinbox.Callback{
+		Provider:  adapter.Name(),
+		Event:     ctx.Param("event"),
+		Kind:      callback.Kind,
+		Handler:   adapter.Handler(),
+		OrderId:   callback.OrderId,
+		RefundId:  callback.RefundId,
+		Signature: ctx.Request().Header.Get(adapter.SignatureHeader()),
+		Body:      string(body),
+	}
+
+	if h.inbox != nil {
+		if err = h.inbox.Add(saved); err != nil {
+			// the callback is still forwarded, the payment system retries it if the billing fails
+			h.L().Error("callback inbox write failed", logger.PairArgs("err", err.Error(), "order_id", callback.OrderId))
+		}
+	}
+
+	var result *webhookadapter.Result
+
+	// the failed callback is answered with the error anyway, the inbox is local to the instance, so the payment
+	// system retries the callback too
+	if h.inbox != nil && saved.Id != "" {
+		e := h.inbox.Process(ctx.Request().Context(), saved, func(c context.Context, cb *callbackinbox.Callback) (string, int32, string) {
+			result, err = h.forward(c, cb)
+			return callbackOutcome(cb.Kind, result, err)
+		})
+
+		if e != nil {
+			h.L().Error("callback inbox write failed", logger.PairArgs("err", e.Error(), "callback_id", saved.Id))
+		}
+	} else {
+		result, err = h.forward(ctx.Request().Context(), saved)
+	}
+
+	if err != nil {
+		h.L().Error(
+			common.InternalErrorTemplate,
+			logger.WithFields(logger.Fields{"err": err.Error(), "provider": adapter.Name(), "order_id": callback.OrderId}),
+		)
+	}
+
+	return adapter.Respond(ctx, callback, result, err)
+}
+
+// @summary Get the callbacks of the payment systems
+// @desc Get the list of the payment system callbacks saved to the inbox, the stuck callbacks aren't retried automatically
+// @id callbackInboxPathListInbox
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @param status query {string} false The callback status. Available values: pending, processed, rejected, stuck.
+// @param limit query {integer} false The number of callbacks returned in one page. Default value is 20.
+// @param offset query {integer} false The ranking number of the first callback on the page.
+// @success 200 {object} ListCallbackInboxResponse Returns the list of the callbacks
+// @failure 400 {object} billingpb.ResponseErrorMessage The error code and message with the error details
+// @failure 404 {object} billingpb.ResponseErrorMessage The callback inbox is disabled
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /system/api/v1/callbacks/inbox [get]
+func (h *PaymentSystemWebHook) listInbox(ctx echo.Context) error {
+	if h.inbox == nil {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackInboxDisabled)
+	}
+
+	req := &ListCallbackInboxRequest{}
+
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = callbackInboxLimitDefault
+	}
+
+	if req.Limit > callbackInboxLimitMax {
+		req.Limit = callbackInboxLimitMax
+	}
+
+	items, count, err := h.inbox.List(req.Status, req.Offset, req.Limit)
+
+	if err != nil {
+		h.L().Error("callback inbox read failed", logger.PairArgs("err", err.Error()))
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorCallbackInboxFailed)
+	}
+
+	for i, item := range items {
+		items[i] = h.redactCallback(item)
+	}
+
+	return ctx.JSON(http.StatusOK, &ListCallbackInboxResponse{Count: count, Items: items})
+}
+
+// @summary Get the callback of the payment system
+// @desc Get the payment system callback saved to the inbox with the redacted body and the last billing response
+// @id callbackInboxIdPathGetInboxCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @param callback_id path {string} true The unique identifier for the callback.
+// @success 200 {object} callbackinbox.Callback Returns the callback
+// @failure 404 {object} billingpb.ResponseErrorMessage The callback not found or the callback inbox is disabled
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /system/api/v1/callbacks/inbox/{callback_id} [get]
+func (h *PaymentSystemWebHook) getInboxCallback(ctx echo.Context) error {
+	callback, err := h.inboxCallback(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, h.redactCallback(callback))
+}
+
+// @summary Replay the callback of the payment system
+// @desc Forward the saved callback to the billing again, the stuck and the rejected callbacks are replayed after
+// @desc the cause of the failure is fixed
+// @id callbackInboxReplayPathReplayInboxCallback
+// @tag Payment system webhooks
+// @accept application/json
+// @produce application/json
+// @param callback_id path {string} true The unique identifier for the callback.
+// @success 200 {object} callbackinbox.Callback Returns the callback with the result of the replay
+// @failure 400 {object} billingpb.ResponseErrorMessage The callback is already processed
+// @failure 404 {object} billingpb.ResponseErrorMessage The callback not found or the callback inbox is disabled
+// @failure 409 {object} billingpb.ResponseErrorMessage The callback is being processed
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /system/api/v1/callbacks/inbox/{callback_id}/replay [post]
+func (h *PaymentSystemWebHook) replayInboxCallback(ctx echo.Context) error {
+	callback, err := h.inboxCallback(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if callback.Status == callbackinbox.StatusProcessed {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorCallbackAlreadyProcessed)
+	}
+
+	err = h.inbox.Process(ctx.Request().Context(), callback, h.process)
+
+	h.L().Info(
+		"callback replay",
+		logger.PairArgs(
+			"audit", "callback_replay",
+			"user_id", common.ExtractUserContext(ctx).Id,
+			"callback_id", callback.Id,
+			"provider", callback.Provider,
+			"order_id", callback.OrderId,
+			"result", callback.Status,
+		),
+	)
+
+	if err == callbackinbox.ErrBusy {
+		return echo.NewHTTPError(http.StatusConflict, common.ErrorCallbackBusy)
+	}
+
+	if err != nil {
+		h.L().Error("callback inbox write failed", logger.PairArgs("err", err.Error(), "callback_id", callback.Id))
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorCallbackInboxFailed)
+	}
+
+	return ctx.JSON(http.StatusOK, h.redactCallback(callback))
+}
+
+// redactCallback returns the copy of the saved callback with the personal data of the body and the signature masked
+// to show it to the system users, the saved callback is kept as is to replay it
+func (h *PaymentSystemWebHook) redactCallback(callback *callbackinbox.Callback) *callbackinbox.Callback {
+	redacted := *callback
+
+	if redacted.Body != "" {
+		redacted.Body = h.dispatch.Redactor.Body([]byte(redacted.Body))
+	}
+
+	if redacted.Signature != "" {
+		redacted.Signature = redact.Mask
+	}
+
+	return &redacted
+}
+
+func (h *PaymentSystemWebHook) inboxCallback(ctx echo.Context) (*callbackinbox.Callback, error) {
+	if h.inbox == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackInboxDisabled)
+	}
+
+	callback, err := h.inbox.Get(ctx.Param("callback_id"))
+
+	if err != nil {
+		h.L().Error("callback inbox read failed", logger.PairArgs("err", err.Error()))
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, common.ErrorCallbackInboxFailed)
+	}
+
+	if callback == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackNotFound)
+	}
+
+	return callback, nil
+}
+
+// Retry forwards the pending callbacks of the inbox until the context is canceled
+func (h *PaymentSystemWebHook) Retry(ctx context.Context) {
+	h.inbox.Run(ctx, h.process, h.L())
+}
+
+// process is the inbox processor of the saved callbacks
+func (h *PaymentSystemWebHook) process(ctx context.Context, callback *callbackinbox.Callback) (string, int32, string) {
+	result, err := h.forward(ctx, callback)
+
+	if err != nil {
+		h.L().Error(
+			common.InternalErrorTemplate,
+			logger.WithFields(logger.Fields{"err": err.Error(), "provider": callback.Provider, "callback_id": callback.Id}),
+		)
+	}
+
+	return callbackOutcome(callback.Kind, result, err)
+}
+
+// forward sends the callback to the billing, the retry of the recurring callback processed by the billing updates
+// the recurring state only
+func (h *PaymentSystemWebHook) forward(ctx context.Context, callback *callbackinbox.Callback) (*webhookadapter.Result, error) {
+	if callback.Kind == webhookadapter.KindRecurring && callback.Forwarded {
+		return &webhookadapter.Result{Status: callback.LastStatus}, h.updateRecurring(ctx, callback)
+	}
+
+	if callback.Kind == webhookadapter.KindRefund {
+		req := &billingpb.CallbackRequest{
+			Handler:   callback.Handler,
+			Body:      []byte(callback.Body),
+			Signature: callback.Signature,
+		}
+		res, err := h.dispatch.Services.Billing.ProcessRefundCallback(ctx, req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &webhookadapter.Result{Status: res.Status, Error: res.Error}, nil
+	}
+
+	req := &billingpb.PaymentNotifyRequest{
+		OrderId:   callback.OrderId,
+		Request:   []byte(callback.Body),
+		Signature: callback.Signature,
+	}
+	res, err := h.dispatch.Services.Billing.PaymentCallbackProcess(ctx, req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &webhookadapter.Result{Status: res.Status, Error: res.Error}
+
+	if callback.Kind != webhookadapter.KindRecurring || !paymentProcessed(result.Status) {
+		return result, nil
+	}
+
+	callback.Forwarded = true
+
+	return result, h.updateRecurring(ctx, callback)
+}
+
+// updateRecurring saves the card of the successful recurring payment and the state of its subscription, the billing
+// processes the payment of the order before it
+func (h *PaymentSystemWebHook) updateRecurring(ctx context.Context, callback *callbackinbox.Callback) error {
+	adapter, ok := h.adapters.Get(callback.Provider)
+
+	if !ok {
+		return fmt.Errorf("webhook adapter %q isn't registered", callback.Provider)
+	}
+
+	decoded, err := adapter.Decode(callback.Event, []byte(callback.Body))
+
+	if err != nil {
+		return err
+	}
+
+	recurring := decoded.Recurring
+
+	if recurring == nil {
+		return nil
+	}
+
+	if recurring.Completed && recurring.FilingId != "" {
+		if err = h.saveRecurringCard(ctx, callback, recurring); err != nil {
+			return err
+		}
+	}
+
+	if recurring.SubscriptionId == "" {
+		return nil
+	}
+
+	req := &recurringpb.GetSubscriptionRequest{Id: recurring.SubscriptionId}
+	res, err := h.dispatch.Services.Repository.GetSubscription(ctx, req)
+
+	if err != nil {
+		return err
+	}
+
+	// the subscriptions unknown to the repository aren't retried
+	if res.Status == billingpb.ResponseStatusNotFound || (res.Status == billingpb.ResponseStatusOk && res.Subscription == nil) {
+		h.L().Error(
+			"recurring subscription not found",
+			logger.PairArgs("subscription_id", recurring.SubscriptionId, "order_id", callback.OrderId),
+		)
+		return nil
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return fmt.Errorf("recurring subscription %s isn't read, status %d", recurring.SubscriptionId, res.Status)
+	}
+
+	if res.Subscription.IsActive == recurring.SubscriptionActive {
+		return nil
+	}
+
+	res.Subscription.IsActive = recurring.SubscriptionActive
+	updated, err := h.dispatch.Services.Repository.UpdateSubscription(ctx, res.Subscription)
+
+	if err != nil {
+		return err
+	}
+
+	if updated.Status != billingpb.ResponseStatusOk {
+		return fmt.Errorf("recurring subscription %s isn't updated, status %d", recurring.SubscriptionId, updated.Status)
+	}
+
+	return nil
+}
+
+// saveRecurringCard saves the card of the recurring payment to the project of the order, the card saved by the
+// previous attempt isn't inserted again
+func (h *PaymentSystemWebHook) saveRecurringCard(
+	ctx context.Context,
+	callback *callbackinbox.Callback,
+	recurring *webhookadapter.Recurring,
+) error {
+	cards, err := h.dispatch.Services.Repository.FindSavedCards(ctx, &recurringpb.SavedCardRequest{Token: recurring.CustomerId})
+
+	if err != nil {
+		return err
+	}
+
+	for _, card := range cards.SavedCards {
+		if card.RecurringId == recurring.FilingId {
+			return nil
+		}
+	}
+
+	order, err := h.dispatch.Services.Billing.GetOrderPrivate(ctx, &billingpb.GetOrderRequest{OrderId: callback.OrderId})
+
+	if err != nil {
+		return err
+	}
+
+	if order.Status != billingpb.ResponseStatusOk || order.Item == nil || order.Item.Project == nil {
+		return fmt.Errorf("order %s isn't read, status %d", callback.OrderId, order.Status)
+	}
+
+	req := &recurringpb.SavedCardRequest{
+		Token:       recurring.CustomerId,
+		MerchantId:  order.Item.Project.MerchantId,
+		ProjectId:   order.Item.Project.Id,
+		MaskedPan:   recurring.MaskedPan,
+		CardHolder:  recurring.CardHolder,
+		RecurringId: recurring.FilingId,
+		Expire: &recurringpb.CardExpire{
+			Month: recurring.ExpireMonth,
+			Year:  recurring.ExpireYear,
+		},
+	}
+	_, err = h.dispatch.Services.Repository.InsertSavedCard(ctx, req)
+
+	return err
+}
+
+// paymentProcessed reports if the billing accepted the payment callback
+func paymentProcessed(status int32) bool {
+	switch status {
+	case billingpb.StatusTemporary, billingpb.StatusErrorValidation, billingpb.StatusErrorSystem:
+		return false
+	}
+
+	return true
+}
+
+// callbackOutcome retries the callbacks failed by the transport errors and the temporary billing errors
+func callbackOutcome(kind string, result *webhookadapter.Result, err error) (string, int32, string) {
+	if err != nil && result != nil {
+		// the recurring state isn't updated after the payment is processed
+		return callbackinbox.OutcomeRetry, result.Status, err.Error()
+	}
+
+	if err != nil {
+		return callbackinbox.OutcomeRetry, 0, err.Error()
+	}
+
+	if kind == webhookadapter.KindRefund {
+		if result.Status != billingpb.ResponseStatusOk {
+			return callbackinbox.OutcomeRejected, result.Status, result.Error
+		}
+
+		return callbackinbox.OutcomeProcessed, result.Status, result.Error
+	}
+
+	switch result.Status {
+	case billingpb.StatusTemporary:
+		return callbackinbox.OutcomeRetry, result.Status, result.Error
+	case billingpb.StatusErrorValidation, billingpb.StatusErrorSystem:
+		return callbackinbox.OutcomeRejected, result.Status, result.Error
+	}
+
+	return callbackinbox.OutcomeProcessed, result.Status, result.Error
+}
@@ -0,0 +1,1053 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/globalsign/mgo/bson"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/paysuper/paysuper-proto/go/billingpb/mocks"
+	"github.com/paysuper/paysuper-proto/go/recurringpb"
+	"io/ioutil"
+
+	"github.com/paysuper/paysuper-management-api/internal/callbackinbox"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/test"
+	"github.com/paysuper/paysuper-management-api/internal/webhookadapter"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type PaymentSystemWebHookTestSuite struct {
+	suite.Suite
+	router  *PaymentSystemWebHook
+	caller  *test.EchoReqResCaller
+	workDir string
+	inbox   *callbackinbox.Inbox
+	tmpDir  string
+}
+
+func Test_PaymentSystemWebHookTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentSystemWebHookTestSuite))
+}
+
+func (suite *PaymentSystemWebHookTestSuite) SetupTest() {
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{
+		Billing: mock.NewBillingServerOkMock(),
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		var err error
+		suite.workDir = set.Initial.WorkDir
+		suite.router, err = NewPaymentSystemWebHook(set.HandlerSet, nil, set.GlobalConfig)
+		assert.NoError(suite.T(), err)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TearDownTest() {
+	if suite.inbox != nil {
+		assert.NoError(suite.T(), suite.inbox.Close())
+		assert.NoError(suite.T(), os.RemoveAll(suite.tmpDir))
+		suite.inbox = nil
+	}
+}
+
+// setUpCallbackVerification rebuilds the router with the callback verification of the test secret
+func (suite *PaymentSystemWebHookTestSuite) setUpCallbackVerification(allowedIps ...string) {
+	var e error
+	settings := test.DefaultSettings()
+	settings["dispatcher"].(map[string]interface{})["callbacks"] = map[string]interface{}{
+		"enabled": true,
+		"providers": map[string]interface{}{
+			"cardpay": map[string]interface{}{
+				"allowedIps": allowedIps,
+				"secrets":    map[string]interface{}{"project": "secret_key"},
+			},
+		},
+	}
+	srv := common.Services{
+		Billing: mock.NewBillingServerOkMock(),
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		var err error
+		suite.workDir = set.Initial.WorkDir
+		suite.router, err = NewPaymentSystemWebHook(set.HandlerSet, nil, set.GlobalConfig)
+		assert.NoError(suite.T(), err)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *PaymentSystemWebHookTestSuite) refundCallbackBody() []byte {
+	refundReq := &billingpb.CardPayRefundCallback{
+		MerchantOrder: &billingpb.CardPayMerchantOrder{
+			Id: bson.NewObjectId().Hex(),
+		},
+		PaymentMethod: "BANKCARD",
+		PaymentData: &billingpb.CardPayRefundCallbackPaymentData{
+			Id:              bson.NewObjectId().Hex(),
+			RemainingAmount: 0,
+		},
+		RefundData: &billingpb.CardPayRefundCallbackRefundData{
+			Amount:   100,
+			Created:  time.Now().Format("2006-01-02T15:04:05Z"),
+			Id:       bson.NewObjectId().Hex(),
+			Currency: "RUB",
+			Status:   billingpb.CardPayPaymentResponseStatusCompleted,
+			AuthCode: bson.NewObjectId().Hex(),
+			Is_3D:    true,
+			Rrn:      bson.NewObjectId().Hex(),
+		},
+		CallbackTime: time.Now().Format("2006-01-02T15:04:05Z"),
+		Customer: &billingpb.CardPayCustomer{
+			Email: "test@unut.test",
+			Id:    "test@unut.test",
+		},
+	}
+
+	b, err := json.Marshal(refundReq)
+	assert.NoError(suite.T(), err)
+
+	return b
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RefundCallback_Ok() {
+
+	refundReq := &billingpb.CardPayRefundCallback{
+		MerchantOrder: &billingpb.CardPayMerchantOrder{
+			Id: bson.NewObjectId().Hex(),
+		},
+		PaymentMethod: "BANKCARD",
+		PaymentData: &billingpb.CardPayRefundCallbackPaymentData{
+			Id:              bson.NewObjectId().Hex(),
+			RemainingAmount: 0,
+		},
+		RefundData: &billingpb.CardPayRefundCallbackRefundData{
+			Amount:   100,
+			Created:  time.Now().Format("2006-01-02T15:04:05Z"),
+			Id:       bson.NewObjectId().Hex(),
+			Currency: "RUB",
+			Status:   billingpb.CardPayPaymentResponseStatusCompleted,
+			AuthCode: bson.NewObjectId().Hex(),
+			Is_3D:    true,
+			Rrn:      bson.NewObjectId().Hex(),
+		},
+		CallbackTime: time.Now().Format("2006-01-02T15:04:05Z"),
+		Customer: &billingpb.CardPayCustomer{
+			Email: "test@unut.test",
+			Id:    "test@unut.test",
+		},
+	}
+
+	b, err := json.Marshal(refundReq)
+	assert.NoError(suite.T(), err)
+
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	res, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Body.String())
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RefundCallback_BindError() {
+
+	refundReq := `{"payment_method": 11111}`
+	hash := sha512.New()
+	hash.Write([]byte(refundReq + "secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	_, err := suite.caller.Request(http.MethodPost, path, strings.NewReader(refundReq), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorRequestParamsIncorrect, httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RefundCallback_ValidationError() {
+	refundReq := &billingpb.CardPayRefundCallback{
+		MerchantOrder: &billingpb.CardPayMerchantOrder{
+			Id: bson.NewObjectId().Hex(),
+		},
+		PaymentMethod: "BANKCARD",
+		RefundData: &billingpb.CardPayRefundCallbackRefundData{
+			Amount:   100,
+			Created:  time.Now().Format("2006-01-02T15:04:05Z"),
+			Id:       bson.NewObjectId().Hex(),
+			Currency: "RUB",
+			Status:   billingpb.CardPayPaymentResponseStatusCompleted,
+			AuthCode: bson.NewObjectId().Hex(),
+			Is_3D:    true,
+			Rrn:      bson.NewObjectId().Hex(),
+		},
+		CallbackTime: time.Now().Format("2006-01-02T15:04:05Z"),
+		Customer: &billingpb.CardPayCustomer{
+			Email: "test@unut.test",
+			Id:    "test@unut.test",
+		},
+	}
+
+	b, err := json.Marshal(refundReq)
+	assert.NoError(suite.T(), err)
+
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	_, err = suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), common.NewValidationError("PaymentData"), httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RefundCallback_BillingServerSystemError() {
+
+	refundReq := &billingpb.CardPayRefundCallback{
+		MerchantOrder: &billingpb.CardPayMerchantOrder{
+			Id: bson.NewObjectId().Hex(),
+		},
+		PaymentMethod: "BANKCARD",
+		PaymentData: &billingpb.CardPayRefundCallbackPaymentData{
+			Id:              bson.NewObjectId().Hex(),
+			RemainingAmount: 0,
+		},
+		RefundData: &billingpb.CardPayRefundCallbackRefundData{
+			Amount:   100,
+			Created:  time.Now().Format("2006-01-02T15:04:05Z"),
+			Id:       bson.NewObjectId().Hex(),
+			Currency: "RUB",
+			Status:   billingpb.CardPayPaymentResponseStatusCompleted,
+			AuthCode: bson.NewObjectId().Hex(),
+			Is_3D:    true,
+			Rrn:      bson.NewObjectId().Hex(),
+		},
+		CallbackTime: time.Now().Format("2006-01-02T15:04:05Z"),
+		Customer: &billingpb.CardPayCustomer{
+			Email: "test@unut.test",
+			Id:    "test@unut.test",
+		},
+	}
+
+	b, err := json.Marshal(refundReq)
+	assert.NoError(suite.T(), err)
+
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerSystemErrorMock()
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	_, err = suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorUnknown, httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RefundCallback_BillingServer_Error() {
+	refundReq := &billingpb.CardPayRefundCallback{
+		MerchantOrder: &billingpb.CardPayMerchantOrder{
+			Id: bson.NewObjectId().Hex(),
+		},
+		PaymentMethod: "BANKCARD",
+		PaymentData: &billingpb.CardPayRefundCallbackPaymentData{
+			Id:              bson.NewObjectId().Hex(),
+			RemainingAmount: 0,
+		},
+		RefundData: &billingpb.CardPayRefundCallbackRefundData{
+			Amount:   100,
+			Created:  time.Now().Format("2006-01-02T15:04:05Z"),
+			Id:       bson.NewObjectId().Hex(),
+			Currency: "RUB",
+			Status:   billingpb.CardPayPaymentResponseStatusCompleted,
+			AuthCode: bson.NewObjectId().Hex(),
+			Is_3D:    true,
+			Rrn:      bson.NewObjectId().Hex(),
+		},
+		CallbackTime: time.Now().Format("2006-01-02T15:04:05Z"),
+		Customer: &billingpb.CardPayCustomer{
+			Email: "test@unut.test",
+			Id:    "test@unut.test",
+		},
+	}
+
+	b, err := json.Marshal(refundReq)
+	assert.NoError(suite.T(), err)
+
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerErrorMock()
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	_, err = suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), echo.Map{"message": mock.SomeError.Message}, httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RefundCallback_BillingServerTemporary_Ok() {
+	refundReq := &billingpb.CardPayRefundCallback{
+		MerchantOrder: &billingpb.CardPayMerchantOrder{
+			Id: bson.NewObjectId().Hex(),
+		},
+		PaymentMethod: "BANKCARD",
+		PaymentData: &billingpb.CardPayRefundCallbackPaymentData{
+			Id:              bson.NewObjectId().Hex(),
+			RemainingAmount: 0,
+		},
+		RefundData: &billingpb.CardPayRefundCallbackRefundData{
+			Amount:   100,
+			Created:  time.Now().Format("2006-01-02T15:04:05Z"),
+			Id:       bson.NewObjectId().Hex(),
+			Currency: "RUB",
+			Status:   billingpb.CardPayPaymentResponseStatusCompleted,
+			AuthCode: bson.NewObjectId().Hex(),
+			Is_3D:    true,
+			Rrn:      bson.NewObjectId().Hex(),
+		},
+		CallbackTime: time.Now().Format("2006-01-02T15:04:05Z"),
+		Customer: &billingpb.CardPayCustomer{
+			Email: "test@unut.test",
+			Id:    "test@unut.test",
+		},
+	}
+
+	b, err := json.Marshal(refundReq)
+	assert.NoError(suite.T(), err)
+
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerOkTemporaryMock()
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	res, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), res.Body.String())
+
+	var body map[string]string
+	err = json.Unmarshal(res.Body.Bytes(), &body)
+	assert.NoError(suite.T(), err)
+
+	v, ok := body["message"]
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), mock.SomeError.Message, v)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_CallbackVerification_ReplayRejected() {
+	suite.setUpCallbackVerification("192.0.2.0/24")
+
+	b := suite.refundCallbackBody()
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	init := func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	}
+
+	res, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), init)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Body.String())
+
+	res, err = suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), init)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Contains(suite.T(), res.Body.String(), "already processed")
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_CallbackVerification_FailedCallbackRetried() {
+	suite.setUpCallbackVerification()
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerSystemErrorMock()
+
+	b := suite.refundCallbackBody()
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	init := func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	}
+
+	_, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), init)
+	assert.Error(suite.T(), err)
+
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerOkMock()
+
+	res, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), init)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Body.String())
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_CallbackVerification_SignatureInvalid() {
+	suite.setUpCallbackVerification()
+
+	b := suite.refundCallbackBody()
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "another_secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/refund"
+	_, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+	})
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCallbackSignatureInvalid, httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_CallbackVerification_IpNotAllowed() {
+	suite.setUpCallbackVerification("10.0.0.0/8", "198.51.100.7")
+
+	b := suite.refundCallbackBody()
+	hash := sha512.New()
+	hash.Write([]byte(string(b) + "secret_key"))
+
+	path := common.WebHookGroupPath + "/cardpay/PAYMENT"
+	_, err := suite.caller.Request(http.MethodPost, path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, hex.EncodeToString(hash.Sum(nil)))
+		request.Header.Set(echo.HeaderXForwardedFor, "198.51.100.7")
+	})
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusForbidden, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCallbackIpNotAllowed, httpErr.Message)
+}
+
+// setUpProviders rebuilds the router accepting the callbacks of the providers
+func (suite *PaymentSystemWebHookTestSuite) setUpProviders(providers ...string) {
+	var e error
+	settings := test.DefaultSettings()
+	srv := common.Services{
+		Billing: mock.NewBillingServerOkMock(),
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		var err error
+		cfg := *set.GlobalConfig
+		cfg.WebhookProviders = providers
+		suite.workDir = set.Initial.WorkDir
+		suite.router, err = NewPaymentSystemWebHook(set.HandlerSet, nil, &cfg)
+		assert.NoError(suite.T(), err)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_RecordedPayloads() {
+	suite.setUpProviders(webhookadapter.CardPayName, webhookadapter.SampleName)
+
+	cases := []struct {
+		name    string
+		path    string
+		payload string
+		header  string
+		billing billingpb.BillingService
+		code    int
+		body    string
+		err     *echo.HTTPError
+	}{
+		{
+			name:    "cardpay payment",
+			path:    "/cardpay/payment",
+			payload: "cardpay_payment.json",
+			header:  common.CardPayPaymentResponseHeaderSignature,
+			billing: mock.NewBillingServerOkMock(),
+			code:    http.StatusOK,
+			body:    `{"message":"Payment successfully complete"}`,
+		},
+		{
+			name:    "cardpay upper case payment",
+			path:    "/cardpay/PAYMENT",
+			payload: "cardpay_payment.json",
+			header:  common.CardPayPaymentResponseHeaderSignature,
+			billing: mock.NewBillingServerOkMock(),
+			code:    http.StatusOK,
+			body:    `{"message":"Payment successfully complete"}`,
+		},
+		{
+			name:    "cardpay recurring payment",
+			path:    "/cardpay/RECURRING",
+			payload: "cardpay_recurring.json",
+			header:  common.CardPayPaymentResponseHeaderSignature,
+			billing: mock.NewBillingServerOkMock(),
+			code:    http.StatusOK,
+			body:    `{"message":"Payment successfully complete"}`,
+		},
+		{
+			name:    "cardpay refund",
+			path:    "/cardpay/refund",
+			payload: "cardpay_refund.json",
+			header:  common.CardPayPaymentResponseHeaderSignature,
+			billing: mock.NewBillingServerOkMock(),
+			code:    http.StatusOK,
+		},
+		{
+			name:    "cardpay upper case refund with the billing message",
+			path:    "/cardpay/REFUND",
+			payload: "cardpay_refund.json",
+			header:  common.CardPayPaymentResponseHeaderSignature,
+			billing: mock.NewBillingServerOkTemporaryMock(),
+			code:    http.StatusOK,
+			body:    `{"message":"` + mock.SomeError.Message + `"}`,
+		},
+		{
+			name:    "cardpay unknown event",
+			path:    "/cardpay/PAYOUT",
+			payload: "cardpay_payment.json",
+			header:  common.CardPayPaymentResponseHeaderSignature,
+			billing: mock.NewBillingServerOkMock(),
+			err:     echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackProviderUnknown),
+		},
+		{
+			name:    "sample payment",
+			path:    "/sample/payment",
+			payload: "sample_payment.json",
+			header:  webhookadapter.SampleSignatureHeader,
+			billing: mock.NewBillingServerOkMock(),
+			code:    http.StatusOK,
+			body:    `{"status":"accepted"}`,
+		},
+		{
+			name:    "sample refund",
+			path:    "/sample/refund",
+			payload: "sample_refund.json",
+			header:  webhookadapter.SampleSignatureHeader,
+			billing: mock.NewBillingServerOkMock(),
+			code:    http.StatusOK,
+			body:    `{"status":"accepted"}`,
+		},
+		{
+			name:    "sample refund declined by the billing",
+			path:    "/sample/refund",
+			payload: "sample_refund.json",
+			header:  webhookadapter.SampleSignatureHeader,
+			billing: mock.NewBillingServerErrorMock(),
+			err:     echo.NewHTTPError(http.StatusUnprocessableEntity, echo.Map{"message": mock.SomeError.Message}),
+		},
+		{
+			name:    "sample refund with the billing unavailable",
+			path:    "/sample/refund",
+			payload: "sample_refund.json",
+			header:  webhookadapter.SampleSignatureHeader,
+			billing: mock.NewBillingServerSystemErrorMock(),
+			err:     echo.NewHTTPError(http.StatusServiceUnavailable, common.ErrorUnknown),
+		},
+		{
+			name:    "sample refund without the refund id",
+			path:    "/sample/refund",
+			payload: "sample_payment.json",
+			header:  webhookadapter.SampleSignatureHeader,
+			billing: mock.NewBillingServerOkMock(),
+			err:     echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect),
+		},
+		{
+			name:    "unknown provider",
+			path:    "/unknown/payment",
+			payload: "sample_payment.json",
+			header:  webhookadapter.SampleSignatureHeader,
+			billing: mock.NewBillingServerOkMock(),
+			err:     echo.NewHTTPError(http.StatusNotFound, common.ErrorCallbackProviderUnknown),
+		},
+	}
+
+	for _, tc := range cases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/" + tc.payload)
+			require.NoError(t, err)
+
+			suite.router.dispatch.Services.Billing = tc.billing
+			suite.router.dispatch.Services.Repository = suite.newRepositoryMock(true)
+
+			res, err := suite.caller.Request(http.MethodPost, common.WebHookGroupPath+tc.path, bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+				request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				request.Header.Set(tc.header, "signature")
+			})
+
+			if tc.err != nil {
+				require.Error(t, err)
+				httpErr, ok := err.(*echo.HTTPError)
+				require.True(t, ok)
+				assert.Equal(t, tc.err.Code, httpErr.Code)
+				assert.Equal(t, tc.err.Message, httpErr.Message)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.code, res.Code)
+
+			if tc.body == "" {
+				assert.Empty(t, res.Body.String())
+			} else {
+				assert.JSONEq(t, tc.body, res.Body.String())
+			}
+		})
+	}
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_SampleValidationError() {
+	suite.setUpProviders(webhookadapter.SampleName)
+
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/sample_invalid_status.json")
+	require.NoError(suite.T(), err)
+
+	_, err = suite.caller.Request(http.MethodPost, common.WebHookGroupPath+"/sample/payment", bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	})
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Regexp(suite.T(), common.NewValidationError("Status"), httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_ProviderDisabled() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/sample_payment.json")
+	require.NoError(suite.T(), err)
+
+	_, err = suite.caller.Request(http.MethodPost, common.WebHookGroupPath+"/sample/payment", bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	})
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCallbackProviderUnknown, httpErr.Message)
+}
+
+// setUpInbox rebuilds the router saving the callbacks to the inbox in the temporary directory
+func (suite *PaymentSystemWebHookTestSuite) setUpInbox() {
+	var e error
+	suite.tmpDir, e = ioutil.TempDir("", "callback_inbox")
+	if e != nil {
+		panic(e)
+	}
+
+	suite.inbox, e = callbackinbox.Open(&callbackinbox.Config{
+		Path:          suite.tmpDir + "/inbox.db",
+		EncryptionKey: "secret",
+		RetryDelay:    time.Minute,
+		RetryDelayMax: time.Hour,
+		MaxAttempts:   3,
+		BatchSize:     10,
+		Retention:     time.Hour,
+	})
+	if e != nil {
+		panic(e)
+	}
+
+	user := &common.AuthUser{
+		Id: "ffffffffffffffffffffffff",
+	}
+	settings := test.DefaultSettings()
+	srv := common.Services{
+		Billing: mock.NewBillingServerOkMock(),
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		var err error
+		mw.Pre(test.PreAuthUserMiddleware(user))
+		suite.workDir = set.Initial.WorkDir
+		suite.router, err = NewPaymentSystemWebHook(set.HandlerSet, suite.inbox, set.GlobalConfig)
+		assert.NoError(suite.T(), err)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *PaymentSystemWebHookTestSuite) sendRefundCallback() (*httptest.ResponseRecorder, error) {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_refund.json")
+	require.NoError(suite.T(), err)
+
+	return suite.caller.Request(http.MethodPost, common.WebHookGroupPath+"/cardpay/refund", bytes.NewReader(b), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, "signature")
+	})
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_Inbox_Processed() {
+	suite.setUpInbox()
+
+	res, err := suite.sendRefundCallback()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Empty(suite.T(), res.Body.String())
+
+	items, count, err := suite.inbox.List("", 0, 10)
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), 1, count)
+	assert.Equal(suite.T(), callbackinbox.StatusProcessed, items[0].Status)
+	assert.Equal(suite.T(), webhookadapter.CardPayName, items[0].Provider)
+	assert.Equal(suite.T(), "1244117", items[0].RefundId)
+	assert.Equal(suite.T(), 1, items[0].Attempts)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_Inbox_FailedAndReplayed() {
+	suite.setUpInbox()
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerSystemErrorMock()
+
+	// the failed callback is retried by the payment system too
+	_, err := suite.sendRefundCallback()
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.SystemUserGroupPath+callbackInboxPath).
+		SetQueryParam("status", callbackinbox.StatusPending).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	list := &ListCallbackInboxResponse{}
+	require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), list))
+	require.Equal(suite.T(), 1, list.Count)
+	assert.Equal(suite.T(), 1, list.Items[0].Attempts)
+	assert.Equal(suite.T(), mock.SomeError.Error(), list.Items[0].LastError)
+	assert.True(suite.T(), list.Items[0].NextAttemptAt.After(list.Items[0].UpdatedAt))
+	assert.Equal(suite.T(), redact.Mask, list.Items[0].Signature)
+	assert.Contains(suite.T(), list.Items[0].Body, `"1244117"`)
+	assert.NotContains(suite.T(), list.Items[0].Body, "customer@unit.test")
+
+	saved, err := suite.inbox.Get(list.Items[0].Id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "signature", saved.Signature)
+	assert.Contains(suite.T(), saved.Body, "customer@unit.test")
+
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerOkMock()
+
+	res, err = suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":callback_id", list.Items[0].Id).
+		Path(common.SystemUserGroupPath + callbackInboxReplayPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	callback := &callbackinbox.Callback{}
+	require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), callback))
+	assert.Equal(suite.T(), callbackinbox.StatusProcessed, callback.Status)
+	assert.Equal(suite.T(), 2, callback.Attempts)
+
+	saved, err = suite.inbox.Get(list.Items[0].Id)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), saved.Body)
+	assert.Empty(suite.T(), saved.Signature)
+
+	_, err = suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":callback_id", list.Items[0].Id).
+		Path(common.SystemUserGroupPath + callbackInboxReplayPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+	require.Error(suite.T(), err)
+
+	httpErr, ok = err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCallbackAlreadyProcessed, httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_Inbox_RetriedByWorker() {
+	suite.setUpInbox()
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerSystemErrorMock()
+
+	_, err := suite.sendRefundCallback()
+	require.Error(suite.T(), err)
+
+	items, _, err := suite.inbox.List(callbackinbox.StatusPending, 0, 10)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), items, 1)
+
+	// the callback isn't retried before the retry delay
+	due, err := suite.inbox.Due(10)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), due)
+
+	suite.router.dispatch.Services.Billing = mock.NewBillingServerOkMock()
+	require.NoError(suite.T(), suite.inbox.Process(context.Background(), items[0], suite.router.process))
+
+	callback, err := suite.inbox.Get(items[0].Id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), callbackinbox.StatusProcessed, callback.Status)
+
+	// the stale copy of the callback isn't processed twice
+	assert.Equal(suite.T(), callbackinbox.ErrBusy, suite.inbox.Process(context.Background(), items[0], suite.router.process))
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_Inbox_NotFound() {
+	suite.setUpInbox()
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":callback_id", bson.NewObjectId().Hex()).
+		Path(common.SystemUserGroupPath + callbackInboxIdPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCallbackNotFound, httpErr.Message)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestPaymentSystemWebHook_Inbox_Disabled() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Path(common.SystemUserGroupPath + callbackInboxPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorCallbackInboxDisabled, httpErr.Message)
+}
+
+// newRepositoryMock returns the repository saving the cards and keeping the subscription of the recurring fixture
+func (suite *PaymentSystemWebHookTestSuite) newRepositoryMock(active bool) *mock.RepositoryService {
+	repository := &mock.RepositoryService{}
+	repository.On("FindSavedCards", mock2.Anything, mock2.Anything).Return(&recurringpb.SavedCardList{}, nil)
+	repository.On("InsertSavedCard", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.Result{Status: billingpb.ResponseStatusOk}, nil)
+	repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{
+			Status:       billingpb.ResponseStatusOk,
+			Subscription: &recurringpb.Subscription{Id: "5e96c1f4ff5d7c9a3c8d1b95", IsActive: active},
+		}, nil)
+	repository.On("UpdateSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.UpdateSubscriptionResponse{Status: billingpb.ResponseStatusOk}, nil)
+	return repository
+}
+
+func (suite *PaymentSystemWebHookTestSuite) sendRecurringCallback(body []byte) (*httptest.ResponseRecorder, error) {
+	return suite.caller.Request(http.MethodPost, common.WebHookGroupPath+"/cardpay/RECURRING", bytes.NewReader(body), func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.CardPayPaymentResponseHeaderSignature, "signature")
+	})
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_Ok() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	repository := suite.newRepositoryMock(false)
+	suite.router.dispatch.Services.Repository = repository
+
+	res, err := suite.sendRecurringCallback(b)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	repository.AssertCalled(suite.T(), "InsertSavedCard", mock2.Anything, mock2.MatchedBy(func(req *recurringpb.SavedCardRequest) bool {
+		return req.RecurringId == "1243540" && req.Token == "customer@unit.test" && req.MaskedPan == "400000...0077" &&
+			req.Expire.Month == "02" && req.Expire.Year == "2022" && req.MerchantId == "5e96c1f4ff5d7c9a3c8d1b93" &&
+			req.ProjectId == "5e96c1f4ff5d7c9a3c8d1b94"
+	}))
+	repository.AssertCalled(suite.T(), "UpdateSubscription", mock2.Anything, mock2.MatchedBy(func(req *recurringpb.Subscription) bool {
+		return req.Id == "5e96c1f4ff5d7c9a3c8d1b95" && req.IsActive
+	}))
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_SubscriptionNotChanged() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	repository := suite.newRepositoryMock(true)
+	suite.router.dispatch.Services.Repository = repository
+
+	res, err := suite.sendRecurringCallback(b)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	repository.AssertNotCalled(suite.T(), "UpdateSubscription", mock2.Anything, mock2.Anything)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_RepositoryError() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	repository := &mock.RepositoryService{}
+	repository.On("FindSavedCards", mock2.Anything, mock2.Anything).Return(&recurringpb.SavedCardList{}, nil)
+	repository.On("InsertSavedCard", mock2.Anything, mock2.Anything).Return(nil, mock.SomeError)
+	suite.router.dispatch.Services.Repository = repository
+
+	_, err = suite.sendRecurringCallback(b)
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	repository.AssertNotCalled(suite.T(), "GetSubscription", mock2.Anything, mock2.Anything)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_SubscriptionEmpty() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	repository := &mock.RepositoryService{}
+	repository.On("FindSavedCards", mock2.Anything, mock2.Anything).Return(&recurringpb.SavedCardList{}, nil)
+	repository.On("InsertSavedCard", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.Result{Status: billingpb.ResponseStatusOk}, nil)
+	repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{Status: billingpb.ResponseStatusOk}, nil)
+	suite.router.dispatch.Services.Repository = repository
+
+	res, err := suite.sendRecurringCallback(b)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	repository.AssertNotCalled(suite.T(), "UpdateSubscription", mock2.Anything, mock2.Anything)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_CardAlreadySaved() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	repository := &mock.RepositoryService{}
+	repository.On("FindSavedCards", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.SavedCardList{SavedCards: []*recurringpb.SavedCard{{Id: "5e96c1f4ff5d7c9a3c8d1b92", RecurringId: "1243540"}}}, nil)
+	repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{
+			Status:       billingpb.ResponseStatusOk,
+			Subscription: &recurringpb.Subscription{Id: "5e96c1f4ff5d7c9a3c8d1b95", IsActive: true},
+		}, nil)
+	suite.router.dispatch.Services.Repository = repository
+
+	res, err := suite.sendRecurringCallback(b)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	repository.AssertNotCalled(suite.T(), "InsertSavedCard", mock2.Anything, mock2.Anything)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_Inbox_RetriesRepositoryOnly() {
+	suite.setUpInbox()
+
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	repository := &mock.RepositoryService{}
+	repository.On("FindSavedCards", mock2.Anything, mock2.Anything).Return(nil, mock.SomeError)
+	suite.router.dispatch.Services.Repository = repository
+
+	_, err = suite.sendRecurringCallback(b)
+	require.Error(suite.T(), err)
+
+	items, _, err := suite.inbox.List(callbackinbox.StatusPending, 0, 10)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), items, 1)
+	assert.True(suite.T(), items[0].Forwarded)
+
+	// the billing processed the payment, the retry doesn't send it again
+	billing := &mocks.BillingService{}
+	suite.router.dispatch.Services.Billing = billing
+	suite.router.dispatch.Services.Repository = suite.newRepositoryMock(true)
+	require.NoError(suite.T(), suite.inbox.Process(context.Background(), items[0], suite.router.process))
+
+	callback, err := suite.inbox.Get(items[0].Id)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), callbackinbox.StatusProcessed, callback.Status)
+	billing.AssertNotCalled(suite.T(), "PaymentCallbackProcess", mock2.Anything, mock2.Anything)
+}
+
+func (suite *PaymentSystemWebHookTestSuite) TestCardPay_RecurringCallback_ValidationError() {
+	b, err := ioutil.ReadFile(suite.workDir + "/test/webhooks/cardpay_recurring.json")
+	require.NoError(suite.T(), err)
+
+	callback := map[string]interface{}{}
+	require.NoError(suite.T(), json.Unmarshal(b, &callback))
+	delete(callback, "recurring_data")
+	b, err = json.Marshal(callback)
+	require.NoError(suite.T(), err)
+
+	repository := suite.newRepositoryMock(false)
+	suite.router.dispatch.Services.Repository = repository
+
+	_, err = suite.sendRecurringCallback(b)
+	require.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	repository.AssertNotCalled(suite.T(), "InsertSavedCard", mock2.Anything, mock2.Anything)
+}
@@ -162,7 +162,7 @@ func (h *PayoutDocumentsRoute) createPayoutDocument(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreatePayoutDocument(ctx.Request().Context(), req)
@@ -58,12 +58,12 @@ func (h *PriceGroup) getPriceGroupByCountry(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPriceGroupByCountry(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessagePriceGroupByCountry)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessagePriceGroupByCountry)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -95,12 +95,12 @@ func (h *PriceGroup) getCurrencyList(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPriceGroupCurrencies(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessagePriceGroupCurrencyList)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessagePriceGroupCurrencyList)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -128,12 +128,12 @@ func (h *PriceGroup) getCurrencyByRegion(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetPriceGroupCurrencyByRegion(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessagePriceGroupCurrencyByRegion)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessagePriceGroupCurrencyByRegion)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
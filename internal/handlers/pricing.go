@@ -59,12 +59,12 @@ func (h *Pricing) getRecommendedByConversion(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetRecommendedPriceByConversion(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessagePriceGroupRecommendedList)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessagePriceGroupRecommendedList)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -93,12 +93,12 @@ func (h *Pricing) getRecommendedBySteam(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetRecommendedPriceByPriceGroup(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessagePriceGroupRecommendedList)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessagePriceGroupRecommendedList)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -126,12 +126,12 @@ func (h *Pricing) getRecommendedTable(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetRecommendedPriceTable(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessagePriceGroupRecommendedList)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessagePriceGroupRecommendedList)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/globalsign/mgo/bson"
 	"github.com/labstack/echo/v4"
+	microErrors "github.com/micro/go-micro/errors"
 
 	billMock "github.com/paysuper/paysuper-proto/go/billingpb/mocks"
 
@@ -574,6 +576,44 @@ func (suite *ProjectTestSuite) TestProjectCheckSku_InternalError() {
 	shouldBe.Equal(http.StatusInternalServerError, httpErr.Code)
 }
 
+func (suite *ProjectTestSuite) TestProjectCheckSku_TransportError() {
+	cases := []struct {
+		err    error
+		status int
+		rspErr *billingpb.ResponseErrorMessage
+	}{
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, common.ErrorServiceTimeout},
+		{microErrors.Timeout("go.micro.client", "context deadline exceeded"), http.StatusGatewayTimeout, common.ErrorServiceTimeout},
+		{context.Canceled, common.StatusClientClosedRequest, common.ErrorRequestCancelled},
+		{microErrors.Timeout("go.micro.client", "context canceled"), common.StatusClientClosedRequest, common.ErrorRequestCancelled},
+		{microErrors.InternalServerError("go.micro.client", "service p1paybilling: not found"), http.StatusServiceUnavailable, common.ErrorServiceUnavailable},
+		{microErrors.InternalServerError("go.micro.client", "connection error: dial tcp: connection refused"), http.StatusServiceUnavailable, common.ErrorServiceUnavailable},
+		{microErrors.New(billingpb.ServiceName, "unavailable", http.StatusServiceUnavailable), http.StatusServiceUnavailable, common.ErrorServiceUnavailable},
+		{microErrors.NotFound(billingpb.ServiceName, "project not found"), http.StatusNotFound, common.ErrorServiceObjectNotFound},
+		{microErrors.InternalServerError(billingpb.ServiceName, "some error"), http.StatusInternalServerError, common.ErrorUnknown},
+	}
+
+	for _, c := range cases {
+		billingService := &billMock.BillingService{}
+		billingService.On("CheckSkuAndKeyProject", mock2.Anything, mock2.Anything).Return(nil, c.err)
+		suite.router.dispatch.Services.Billing = billingService
+
+		_, err := suite.caller.Builder().
+			Method(http.MethodPost).
+			Params(":"+common.RequestParameterProjectId, bson.NewObjectId().Hex()).
+			Path(common.AuthUserGroupPath + projectsSkuPath).
+			Init(test.ReqInitJSON()).
+			BodyString(`{"sku": "test"}`).
+			Exec(suite.T())
+
+		assert.Error(suite.T(), err, c.err.Error())
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(suite.T(), ok)
+		assert.Equal(suite.T(), c.status, httpErr.Code, c.err.Error())
+		assert.Equal(suite.T(), c.rspErr, httpErr.Message, c.err.Error())
+	}
+}
+
 func (suite *ProjectTestSuite) TestProjectCheckSku_ServiceError() {
 	shouldBe := require.New(suite.T())
 	body := `{"sku": "test"}`
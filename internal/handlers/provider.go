@@ -1,18 +1,31 @@
 package handlers
 
 import (
+	"context"
 	"github.com/ProtocolONE/go-core/v2/pkg/config"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	awsWrapper "github.com/paysuper/paysuper-aws-manager"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
+	"github.com/paysuper/paysuper-management-api/internal/callbackinbox"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/objectstorage"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"gopkg.in/go-playground/validator.v9"
 )
 
-func ProviderHandlers(initial config.Initial, srv common.Services, validator *validator.Validate, set provider.AwareSet, cfg *common.Config) (common.Handlers, func(), error) {
+func ProviderHandlers(initial config.Initial, srv common.Services, validator *validator.Validate, set provider.AwareSet, cfg *common.Config, authCache *common.AuthCache, apiKeys *apikey.Registry, auditStore audit.Store, reportFiles reportfile.Store, redactor *redact.Redactor) (common.Handlers, func(), error) {
 	hSet := common.HandlerSet{
-		Services: srv,
-		Validate: validator,
-		AwareSet: set,
+		Services:  srv,
+		Validate:  validator,
+		AwareSet:  set,
+		AuthCache: authCache,
+		ApiKeys:   apiKeys,
+		Audit:     auditStore,
+
+		ReportFiles: reportFiles,
+		Redactor:    redactor,
 	}
 	copyCfg := *cfg
 
@@ -40,27 +53,67 @@ func ProviderHandlers(initial config.Initial, srv common.Services, validator *va
 		return nil, func() {}, err
 	}
 
-	awsCloudWatchLogs, err := common.NewCloudWatch(cfg.LogsSettings)
+	s3Reporter, err := objectstorage.NewS3Client(cfg.AwsAccessKeyIdReporter, cfg.AwsSecretAccessKeyReporter, cfg.AwsRegionReporter)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	reportStorage := objectstorage.NewS3(s3Reporter, cfg.AwsBucketReporter)
+
+	orderLogSource, err := common.NewOrderLogSource(cfg)
 
 	if err != nil {
 		return nil, func() {}, err
 	}
 
+	var inbox *callbackinbox.Inbox
+	cleanup := func() {}
+
+	if cfg.CallbackInbox.Enabled {
+		if inbox, err = callbackinbox.Open(&copyCfg.CallbackInbox); err != nil {
+			return nil, func() {}, err
+		}
+	}
+
+	paymentSystemWebHook, err := NewPaymentSystemWebHook(hSet, inbox, &copyCfg)
+
+	if err != nil {
+		if inbox != nil {
+			_ = inbox.Close()
+		}
+		return nil, func() {}, err
+	}
+
+	if inbox != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			paymentSystemWebHook.Retry(ctx)
+			close(done)
+		}()
+
+		cleanup = func() {
+			cancel()
+			<-done
+			_ = inbox.Close()
+		}
+	}
+
 	return []common.Handler{
-		NewCardPayWebHook(hSet, &copyCfg),
+		paymentSystemWebHook,
 		NewCountryApiV1(hSet, &copyCfg),
 		NewDashboardRoute(hSet, &copyCfg),
 		NewKeyRoute(hSet, &copyCfg),
 		NewKeyProductRoute(hSet, &copyCfg),
 		NewOnboardingRoute(hSet, initial, awsManagerAgreement, &copyCfg),
-		NewOrderRoute(hSet, awsCloudWatchLogs, &copyCfg),
+		NewOrderRoute(hSet, orderLogSource, &copyCfg),
 		NewPayLinkRoute(hSet, &copyCfg),
 		NewPaymentCostRoute(hSet, &copyCfg),
 		NewPaymentMethodApiV1(hSet, &copyCfg),
 		NewPriceGroupRoute(hSet, &copyCfg),
 		NewProductRoute(hSet, &copyCfg),
 		NewProjectRoute(hSet, &copyCfg),
-		NewReportFileRoute(hSet, awsManagerReporter, &copyCfg),
+		NewReportFileRoute(hSet, reportStorage, &copyCfg),
 		NewRoyaltyReportsRoute(hSet, &copyCfg),
 		NewTaxesRoute(hSet, &copyCfg),
 		NewTokenRoute(hSet, &copyCfg),
@@ -75,6 +128,8 @@ func ProviderHandlers(initial config.Initial, srv common.Services, validator *va
 		NewAdminUsersRoute(hSet, &copyCfg),
 		NewMerchantUsersRoute(hSet, &copyCfg),
 		NewUserRoute(hSet, &copyCfg),
-		NewWebHookRoute(hSet, &copyCfg),
-	}, func() {}, nil
+		NewWebHookRoute(hSet, awsManagerReporter, orderLogSource, &copyCfg),
+		NewApiKeysRoute(hSet, &copyCfg),
+		NewRecurringRoute(hSet, &copyCfg),
+	}, cleanup, nil
 }
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/paysuper/paysuper-proto/go/recurringpb"
+	"net/http"
+	"strings"
+)
+
+const (
+	savedInstrumentsPath      = "/projects/:project_id/customers/:customer_id/instruments"
+	savedInstrumentsIdPath    = "/projects/:project_id/customers/:customer_id/instruments/:instrument_id"
+	subscriptionsPath         = "/projects/:project_id/subscriptions"
+	subscriptionsIdPausePath  = "/projects/:project_id/subscriptions/:subscription_id/pause"
+	subscriptionsIdCancelPath = "/projects/:project_id/subscriptions/:subscription_id/cancel"
+
+	subscriptionsLimitDefault = 20
+	subscriptionsLimitMax     = 100
+)
+
+// SavedInstrument is the saved card of the customer, the card number is masked
+type SavedInstrument struct {
+	// The unique identifier for the saved card.
+	Id string `json:"id"`
+	// The unique identifier for the project.
+	ProjectId string `json:"project_id"`
+	// The unique identifier for the customer.
+	CustomerId string `json:"customer_id"`
+	// The masked card number. Only the first 6 and the last 4 digits are shown.
+	MaskedPan string `json:"masked_pan"`
+	// The cardholder name.
+	CardHolder string `json:"card_holder"`
+	// The month of the card expiration.
+	ExpireMonth string `json:"expire_month"`
+	// The year of the card expiration.
+	ExpireYear string `json:"expire_year"`
+}
+
+type SavedInstrumentsListResponse struct {
+	Items []*SavedInstrument `json:"items"`
+}
+
+// RecurringSubscription is the subscription of the customer, the card number is masked
+type RecurringSubscription struct {
+	// The unique identifier for the subscription.
+	Id string `json:"id"`
+	// The unique identifier for the project.
+	ProjectId string `json:"project_id"`
+	// The unique identifier for the customer.
+	CustomerId string `json:"customer_id"`
+	// The unique identifier for the order of the first payment.
+	OrderId string `json:"order_id"`
+	// The amount of the recurring payment.
+	Amount float64 `json:"amount"`
+	// The three-letter currency code in the ISO 4217 format.
+	Currency string `json:"currency"`
+	// The period of the recurring payments.
+	Period string `json:"period"`
+	// Has a true value if the recurring payments are charged, the paused subscription has a false value.
+	IsActive bool `json:"is_active"`
+	// The masked number of the card charged. Only the first 6 and the last 4 digits are shown.
+	MaskedPan string `json:"masked_pan"`
+}
+
+type ListSubscriptionsRequest struct {
+	// The unique identifier for the customer.
+	CustomerId string `query:"customer_id"`
+	// The number of subscriptions returned in one page. Default value is 20.
+	Limit int32 `query:"limit" validate:"omitempty,gt=0"`
+	// The ranking number of the first subscription on the page.
+	Offset int32 `query:"offset" validate:"omitempty,gte=0"`
+}
+
+type ListSubscriptionsResponse struct {
+	// The total number of the subscriptions.
+	Count int32 `json:"count"`
+	// The list of the subscriptions.
+	Items []*RecurringSubscription `json:"items"`
+}
+
+type RecurringRoute struct {
+	dispatch common.HandlerSet
+	cfg      common.Config
+	provider.LMT
+}
+
+func NewRecurringRoute(set common.HandlerSet, cfg *common.Config) *RecurringRoute {
+	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "RecurringRoute"})
+	return &RecurringRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      *cfg,
+	}
+}
+
+func (h *RecurringRoute) Route(groups *common.Groups) {
+	groups.AuthUser.GET(savedInstrumentsPath, h.listSavedInstruments)
+	groups.AuthUser.DELETE(savedInstrumentsIdPath, h.deleteSavedInstrument)
+	groups.AuthUser.GET(subscriptionsPath, h.listSubscriptions)
+	groups.AuthUser.POST(subscriptionsIdPausePath, h.pauseSubscription)
+	groups.AuthUser.POST(subscriptionsIdCancelPath, h.cancelSubscription)
+}
+
+// @summary Get the list of the saved payment instruments
+// @desc Get the list of the cards saved by the customer in the project of the authorized merchant
+// @id savedInstrumentsPathListSavedInstruments
+// @tag Recurring
+// @accept application/json
+// @produce application/json
+// @success 200 {object} SavedInstrumentsListResponse Returns the list of the saved cards
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project_id path {string} true The unique identifier for the project.
+// @param customer_id path {string} true The unique identifier for the customer.
+// @router /admin/api/v1/projects/{project_id}/customers/{customer_id}/instruments [get]
+func (h *RecurringRoute) listSavedInstruments(ctx echo.Context) error {
+	authUser := common.ExtractUserContext(ctx)
+	projectId := ctx.Param(common.RequestParameterProjectId)
+	req := &recurringpb.SavedCardRequest{Token: ctx.Param(common.RequestParameterCustomerId)}
+	res, err := h.dispatch.Services.Repository.FindSavedCards(ctx.Request().Context(), req)
+
+	if err != nil {
+		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	items := []*SavedInstrument{}
+
+	for _, card := range res.SavedCards {
+		// the customer identifier is unique for the project only
+		if card.MerchantId != authUser.MerchantId || card.ProjectId != projectId {
+			continue
+		}
+
+		items = append(items, newSavedInstrument(card))
+	}
+
+	return ctx.JSON(http.StatusOK, &SavedInstrumentsListResponse{Items: items})
+}
+
+// @summary Delete the saved payment instrument
+// @desc Delete the card saved by the customer, the recurring payments can't be charged from the deleted card
+// @id savedInstrumentsIdPathDeleteSavedInstrument
+// @tag Recurring
+// @accept application/json
+// @produce application/json
+// @success 204 {string} html "OK"
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The saved card not found
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project_id path {string} true The unique identifier for the project.
+// @param customer_id path {string} true The unique identifier for the customer.
+// @param instrument_id path {string} true The unique identifier for the saved card.
+// @router /admin/api/v1/projects/{project_id}/customers/{customer_id}/instruments/{instrument_id} [delete]
+func (h *RecurringRoute) deleteSavedInstrument(ctx echo.Context) error {
+	authUser := common.ExtractUserContext(ctx)
+	card, err := h.dispatch.Services.Repository.FindSavedCardById(
+		ctx.Request().Context(),
+		&recurringpb.FindByStringValue{Value: ctx.Param(common.RequestParameterInstrumentId)},
+	)
+
+	if err != nil {
+		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	if card.Id == "" || card.MerchantId != authUser.MerchantId || card.ProjectId != ctx.Param(common.RequestParameterProjectId) ||
+		card.Token != ctx.Param(common.RequestParameterCustomerId) {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorSavedInstrumentNotFound)
+	}
+
+	res, err := h.dispatch.Services.Repository.DeleteSavedCard(
+		ctx.Request().Context(),
+		&recurringpb.DeleteSavedCardRequest{Id: card.Id, Token: card.Token},
+	)
+
+	if err != nil {
+		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	if res.Status == billingpb.ResponseStatusNotFound {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorSavedInstrumentNotFound)
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// @summary Get the list of the subscriptions
+// @desc Get the list of the recurring subscriptions in the project of the authorized merchant
+// @id subscriptionsPathListSubscriptions
+// @tag Recurring
+// @accept application/json
+// @produce application/json
+// @success 200 {object} ListSubscriptionsResponse Returns the list of the subscriptions
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project_id path {string} true The unique identifier for the project.
+// @param customer_id query {string} false The unique identifier for the customer.
+// @param limit query {integer} false The number of subscriptions returned in one page. Default value is 20.
+// @param offset query {integer} false The ranking number of the first subscription on the page.
+// @router /admin/api/v1/projects/{project_id}/subscriptions [get]
+func (h *RecurringRoute) listSubscriptions(ctx echo.Context) error {
+	req := &ListSubscriptionsRequest{}
+
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = subscriptionsLimitDefault
+	}
+
+	if req.Limit > subscriptionsLimitMax {
+		req.Limit = subscriptionsLimitMax
+	}
+
+	authUser := common.ExtractUserContext(ctx)
+	res, err := h.dispatch.Services.Repository.FindSubscriptions(ctx.Request().Context(), &recurringpb.FindSubscriptionsRequest{
+		MerchantId: authUser.MerchantId,
+		ProjectId:  ctx.Param(common.RequestParameterProjectId),
+		CustomerId: req.CustomerId,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+
+	if err != nil {
+		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	items := []*RecurringSubscription{}
+
+	for _, subscription := range res.List {
+		// the repository filters by the merchant, its count is wrong for the page with the subscriptions of the other
+		// merchants, so the page isn't returned at all
+		if subscription.MerchantId != authUser.MerchantId {
+			h.L().Error(
+				"recurring subscription of the other merchant found",
+				logger.PairArgs("subscription_id", subscription.Id, "merchant_id", authUser.MerchantId),
+			)
+			return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		}
+
+		items = append(items, newRecurringSubscription(subscription))
+	}
+
+	return ctx.JSON(http.StatusOK, &ListSubscriptionsResponse{Count: res.Count, Items: items})
+}
+
+// @summary Pause the subscription
+// @desc Stop charging the recurring payments of the subscription, the paused subscription is kept
+// @id subscriptionsIdPausePathPauseSubscription
+// @tag Recurring
+// @accept application/json
+// @produce application/json
+// @success 200 {object} RecurringSubscription Returns the paused subscription
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The subscription not found
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project_id path {string} true The unique identifier for the project.
+// @param subscription_id path {string} true The unique identifier for the subscription.
+// @router /admin/api/v1/projects/{project_id}/subscriptions/{subscription_id}/pause [post]
+func (h *RecurringRoute) pauseSubscription(ctx echo.Context) error {
+	subscription, err := h.getSubscription(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if subscription.IsActive {
+		subscription.IsActive = false
+		res, err := h.dispatch.Services.Repository.UpdateSubscription(ctx.Request().Context(), subscription)
+
+		if err != nil {
+			h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+			return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+		}
+
+		if res.Status != billingpb.ResponseStatusOk {
+			return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, newRecurringSubscription(subscription))
+}
+
+// @summary Cancel the subscription
+// @desc Cancel the subscription, the recurring payments of the cancelled subscription can't be resumed
+// @id subscriptionsIdCancelPathCancelSubscription
+// @tag Recurring
+// @accept application/json
+// @produce application/json
+// @success 204 {string} html "OK"
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The subscription not found
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project_id path {string} true The unique identifier for the project.
+// @param subscription_id path {string} true The unique identifier for the subscription.
+// @router /admin/api/v1/projects/{project_id}/subscriptions/{subscription_id}/cancel [post]
+func (h *RecurringRoute) cancelSubscription(ctx echo.Context) error {
+	subscription, err := h.getSubscription(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := h.dispatch.Services.Repository.DeleteSubscription(ctx.Request().Context(), subscription)
+
+	if err != nil {
+		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	if res.Status == billingpb.ResponseStatusNotFound {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorRecurringSubscriptionNotFound)
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// getSubscription returns the subscription of the path if it belongs to the project of the authorized merchant
+func (h *RecurringRoute) getSubscription(ctx echo.Context) (*recurringpb.Subscription, error) {
+	res, err := h.dispatch.Services.Repository.GetSubscription(
+		ctx.Request().Context(),
+		&recurringpb.GetSubscriptionRequest{Id: ctx.Param(common.RequestParameterSubscriptionId)},
+	)
+
+	if err != nil {
+		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
+		return nil, common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	if res.Status == billingpb.ResponseStatusNotFound || (res.Status == billingpb.ResponseStatusOk && res.Subscription == nil) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, common.ErrorRecurringSubscriptionNotFound)
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	subscription := res.Subscription
+
+	if subscription.MerchantId != common.ExtractUserContext(ctx).MerchantId ||
+		subscription.ProjectId != ctx.Param(common.RequestParameterProjectId) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, common.ErrorRecurringSubscriptionNotFound)
+	}
+
+	return subscription, nil
+}
+
+func newSavedInstrument(card *recurringpb.SavedCard) *SavedInstrument {
+	instrument := &SavedInstrument{
+		Id:         card.Id,
+		ProjectId:  card.ProjectId,
+		CustomerId: card.Token,
+		MaskedPan:  maskPan(card.MaskedPan),
+		CardHolder: card.CardHolder,
+	}
+
+	if card.Expire != nil {
+		instrument.ExpireMonth = card.Expire.Month
+		instrument.ExpireYear = card.Expire.Year
+	}
+
+	return instrument
+}
+
+func newRecurringSubscription(subscription *recurringpb.Subscription) *RecurringSubscription {
+	return &RecurringSubscription{
+		Id:         subscription.Id,
+		ProjectId:  subscription.ProjectId,
+		CustomerId: subscription.CustomerId,
+		OrderId:    subscription.OrderId,
+		Amount:     subscription.Amount,
+		Currency:   subscription.Currency,
+		Period:     subscription.Period,
+		IsActive:   subscription.IsActive,
+		MaskedPan:  maskPan(subscription.MaskedPan),
+	}
+}
+
+// maskPan keeps the first 6 and the last 4 digits of the card number, the number saved by the payment system
+// can be masked partially or not at all
+func maskPan(pan string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, pan)
+
+	if len(digits) < 4 {
+		return ""
+	}
+
+	if len(digits) < 10 {
+		return "..." + digits[len(digits)-4:]
+	}
+
+	return digits[:6] + "..." + digits[len(digits)-4:]
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/test"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"github.com/paysuper/paysuper-proto/go/recurringpb"
+	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+const (
+	recurringTestMerchantId = "ffffffffffffffffffffffff"
+	recurringTestProjectId  = "5e96c1f4ff5d7c9a3c8d1b90"
+	recurringTestCustomerId = "5e96c1f4ff5d7c9a3c8d1b92"
+)
+
+type RecurringTestSuite struct {
+	suite.Suite
+	router     *RecurringRoute
+	caller     *test.EchoReqResCaller
+	repository *mock.RepositoryService
+}
+
+func Test_Recurring(t *testing.T) {
+	suite.Run(t, new(RecurringTestSuite))
+}
+
+func (suite *RecurringTestSuite) SetupTest() {
+	user := &common.AuthUser{
+		Id:         "ffffffffffffffffffffffff",
+		MerchantId: recurringTestMerchantId,
+	}
+
+	var e error
+	suite.repository = &mock.RepositoryService{}
+	settings := test.DefaultSettings()
+	srv := common.Services{
+		Billing:    mock.NewBillingServerOkMock(),
+		Repository: suite.repository,
+	}
+	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		mw.Pre(test.PreAuthUserMiddleware(user))
+		suite.router = NewRecurringRoute(set.HandlerSet, set.GlobalConfig)
+		return common.Handlers{
+			suite.router,
+		}
+	})
+	if e != nil {
+		panic(e)
+	}
+}
+
+func (suite *RecurringTestSuite) TearDownTest() {}
+
+func (suite *RecurringTestSuite) savedCard(id, merchantId string) *recurringpb.SavedCard {
+	return &recurringpb.SavedCard{
+		Id:          id,
+		Token:       recurringTestCustomerId,
+		ProjectId:   recurringTestProjectId,
+		MerchantId:  merchantId,
+		MaskedPan:   "4000001234560077",
+		CardHolder:  "CARDHOLDER",
+		RecurringId: "1243540",
+		Expire:      &recurringpb.CardExpire{Month: "02", Year: "2022"},
+	}
+}
+
+func (suite *RecurringTestSuite) subscription(merchantId string) *recurringpb.Subscription {
+	return &recurringpb.Subscription{
+		Id:         "5e96c1f4ff5d7c9a3c8d1b95",
+		MerchantId: merchantId,
+		ProjectId:  recurringTestProjectId,
+		CustomerId: recurringTestCustomerId,
+		Amount:     10.5,
+		Currency:   "USD",
+		IsActive:   true,
+		MaskedPan:  "400000******0077",
+	}
+}
+
+func (suite *RecurringTestSuite) TestRecurring_ListSavedInstruments_Ok() {
+	suite.repository.On("FindSavedCards", mock2.Anything, &recurringpb.SavedCardRequest{Token: recurringTestCustomerId}).
+		Return(&recurringpb.SavedCardList{
+			SavedCards: []*recurringpb.SavedCard{
+				suite.savedCard("5e96c1f4ff5d7c9a3c8d1b93", recurringTestMerchantId),
+				suite.savedCard("5e96c1f4ff5d7c9a3c8d1b94", "5e96c1f4ff5d7c9a3c8d1b99"),
+			},
+		}, nil)
+
+	res, err := suite.caller.Builder().
+		Path(common.AuthUserGroupPath+savedInstrumentsPath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterCustomerId, recurringTestCustomerId).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.NotContains(suite.T(), res.Body.String(), "1243540")
+	assert.NotContains(suite.T(), res.Body.String(), "4000001234560077")
+
+	rsp := &SavedInstrumentsListResponse{}
+	require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	require.Len(suite.T(), rsp.Items, 1)
+	assert.Equal(suite.T(), "5e96c1f4ff5d7c9a3c8d1b93", rsp.Items[0].Id)
+	assert.Equal(suite.T(), "400000...0077", rsp.Items[0].MaskedPan)
+	assert.Equal(suite.T(), "02", rsp.Items[0].ExpireMonth)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_ListSavedInstruments_RepositoryError() {
+	suite.repository.On("FindSavedCards", mock2.Anything, mock2.Anything).Return(nil, mock.SomeError)
+
+	_, err := suite.caller.Builder().
+		Path(common.AuthUserGroupPath+savedInstrumentsPath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterCustomerId, recurringTestCustomerId).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_DeleteSavedInstrument_Ok() {
+	card := suite.savedCard("5e96c1f4ff5d7c9a3c8d1b93", recurringTestMerchantId)
+	suite.repository.On("FindSavedCardById", mock2.Anything, &recurringpb.FindByStringValue{Value: card.Id}).Return(card, nil)
+	suite.repository.On("DeleteSavedCard", mock2.Anything, &recurringpb.DeleteSavedCardRequest{Id: card.Id, Token: card.Token}).
+		Return(&recurringpb.DeleteSavedCardResponse{Status: billingpb.ResponseStatusOk}, nil)
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodDelete).
+		Path(common.AuthUserGroupPath+savedInstrumentsIdPath).
+		Params(
+			":"+common.RequestParameterProjectId, recurringTestProjectId,
+			":"+common.RequestParameterCustomerId, recurringTestCustomerId,
+			":"+common.RequestParameterInstrumentId, card.Id,
+		).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+	suite.repository.AssertExpectations(suite.T())
+}
+
+func (suite *RecurringTestSuite) TestRecurring_DeleteSavedInstrument_OtherMerchant() {
+	card := suite.savedCard("5e96c1f4ff5d7c9a3c8d1b93", "5e96c1f4ff5d7c9a3c8d1b99")
+	suite.repository.On("FindSavedCardById", mock2.Anything, mock2.Anything).Return(card, nil)
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodDelete).
+		Path(common.AuthUserGroupPath+savedInstrumentsIdPath).
+		Params(
+			":"+common.RequestParameterProjectId, recurringTestProjectId,
+			":"+common.RequestParameterCustomerId, recurringTestCustomerId,
+			":"+common.RequestParameterInstrumentId, card.Id,
+		).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorSavedInstrumentNotFound, httpErr.Message)
+	suite.repository.AssertNotCalled(suite.T(), "DeleteSavedCard", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_ListSubscriptions_Ok() {
+	req := &recurringpb.FindSubscriptionsRequest{
+		MerchantId: recurringTestMerchantId,
+		ProjectId:  recurringTestProjectId,
+		CustomerId: recurringTestCustomerId,
+		Limit:      subscriptionsLimitMax,
+		Offset:     10,
+	}
+	suite.repository.On("FindSubscriptions", mock2.Anything, req).
+		Return(&recurringpb.FindSubscriptionsResponse{
+			List:  []*recurringpb.Subscription{suite.subscription(recurringTestMerchantId)},
+			Count: 11,
+		}, nil)
+
+	res, err := suite.caller.Builder().
+		Path(common.AuthUserGroupPath+subscriptionsPath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId).
+		SetQueryParam("customer_id", recurringTestCustomerId).
+		SetQueryParam("limit", "1000").
+		SetQueryParam("offset", "10").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	rsp := &ListSubscriptionsResponse{}
+	require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	assert.EqualValues(suite.T(), 11, rsp.Count)
+	require.Len(suite.T(), rsp.Items, 1)
+	assert.Equal(suite.T(), "400000...0077", rsp.Items[0].MaskedPan)
+	assert.True(suite.T(), rsp.Items[0].IsActive)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_ListSubscriptions_OtherMerchant() {
+	suite.repository.On("FindSubscriptions", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.FindSubscriptionsResponse{
+			List: []*recurringpb.Subscription{
+				suite.subscription(recurringTestMerchantId),
+				suite.subscription("5e96c1f4ff5d7c9a3c8d1b99"),
+			},
+			Count: 2,
+		}, nil)
+
+	_, err := suite.caller.Builder().
+		Path(common.AuthUserGroupPath+subscriptionsPath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorUnknown, httpErr.Message)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_PauseSubscription_Ok() {
+	subscription := suite.subscription(recurringTestMerchantId)
+	suite.repository.On("GetSubscription", mock2.Anything, &recurringpb.GetSubscriptionRequest{Id: subscription.Id}).
+		Return(&recurringpb.GetSubscriptionResponse{Status: billingpb.ResponseStatusOk, Subscription: subscription}, nil)
+	suite.repository.On("UpdateSubscription", mock2.Anything, mock2.MatchedBy(func(req *recurringpb.Subscription) bool {
+		return req.Id == subscription.Id && !req.IsActive
+	})).Return(&recurringpb.UpdateSubscriptionResponse{Status: billingpb.ResponseStatusOk}, nil)
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath+subscriptionsIdPausePath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterSubscriptionId, subscription.Id).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	rsp := &RecurringSubscription{}
+	require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	assert.False(suite.T(), rsp.IsActive)
+	suite.repository.AssertExpectations(suite.T())
+}
+
+func (suite *RecurringTestSuite) TestRecurring_PauseSubscription_NotFound() {
+	suite.repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{Status: billingpb.ResponseStatusNotFound}, nil)
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath+subscriptionsIdPausePath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterSubscriptionId, "5e96c1f4ff5d7c9a3c8d1b95").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorRecurringSubscriptionNotFound, httpErr.Message)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_PauseSubscription_Empty() {
+	suite.repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{Status: billingpb.ResponseStatusOk}, nil)
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath+subscriptionsIdPausePath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterSubscriptionId, "5e96c1f4ff5d7c9a3c8d1b95").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	suite.repository.AssertNotCalled(suite.T(), "UpdateSubscription", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_CancelSubscription_Ok() {
+	subscription := suite.subscription(recurringTestMerchantId)
+	suite.repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{Status: billingpb.ResponseStatusOk, Subscription: subscription}, nil)
+	suite.repository.On("DeleteSubscription", mock2.Anything, subscription).
+		Return(&recurringpb.DeleteSubscriptionResponse{Status: billingpb.ResponseStatusOk}, nil)
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath+subscriptionsIdCancelPath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterSubscriptionId, subscription.Id).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusNoContent, res.Code)
+	suite.repository.AssertExpectations(suite.T())
+}
+
+func (suite *RecurringTestSuite) TestRecurring_CancelSubscription_OtherMerchant() {
+	suite.repository.On("GetSubscription", mock2.Anything, mock2.Anything).
+		Return(&recurringpb.GetSubscriptionResponse{
+			Status:       billingpb.ResponseStatusOk,
+			Subscription: suite.subscription("5e96c1f4ff5d7c9a3c8d1b99"),
+		}, nil)
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath+subscriptionsIdCancelPath).
+		Params(":"+common.RequestParameterProjectId, recurringTestProjectId, ":"+common.RequestParameterSubscriptionId, "5e96c1f4ff5d7c9a3c8d1b95").
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	suite.repository.AssertNotCalled(suite.T(), "DeleteSubscription", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RecurringTestSuite) TestRecurring_MaskPan() {
+	assert.Equal(suite.T(), "400000...0077", maskPan("4000001234560077"))
+	assert.Equal(suite.T(), "400000...0077", maskPan("400000...0077"))
+	assert.Equal(suite.T(), "...0077", maskPan("****0077"))
+	assert.Equal(suite.T(), "", maskPan(""))
+}
@@ -4,83 +4,367 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/labstack/echo/v4"
-	awsWrapper "github.com/paysuper/paysuper-aws-manager"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/downloadtoken"
+	"github.com/paysuper/paysuper-management-api/internal/objectstorage"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	_ "github.com/paysuper/paysuper-proto/go/billingpb"
+	"mime"
 	"net/http"
-	"os"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	reportFileDownloadPath = "/report_file/download/:file"
+	reportFileDownloadPath      = "/report_file/download/:file"
+	reportFileDownloadTokenPath = "/report_file/download/:file/token"
 )
 
+// reportFileType is the content type and the disposition of the report file format
+type reportFileType struct {
+	contentType string
+	disposition string
+}
+
+var (
+	reportFileTypes = map[string]*reportFileType{
+		".pdf":  {contentType: "application/pdf", disposition: "inline"},
+		".csv":  {contentType: "text/csv", disposition: "attachment"},
+		".xlsx": {contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", disposition: "attachment"},
+	}
+	reportFileTypeDefault = &reportFileType{contentType: echo.MIMEOctetStream, disposition: "attachment"}
+)
+
+type ReportFileDownloadTokenResponse struct {
+	// The token to download the report file without the authorization.
+	Token string `json:"token"`
+	// The URL to download the report file with the token.
+	Url string `json:"url"`
+	// The date when the token expires.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type ReportFileRoute struct {
-	dispatch   common.HandlerSet
-	awsManager awsWrapper.AwsManagerInterface
-	cfg        common.Config
+	dispatch common.HandlerSet
+	storage  objectstorage.Storage
+	signer   *downloadtoken.Signer
+	cfg      common.Config
 	provider.LMT
 }
 
-func NewReportFileRoute(set common.HandlerSet, awsManager awsWrapper.AwsManagerInterface, cfg *common.Config) *ReportFileRoute {
+func NewReportFileRoute(set common.HandlerSet, storage objectstorage.Storage, cfg *common.Config) *ReportFileRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "ReportFileRoute"})
-	return &ReportFileRoute{
-		dispatch:   set,
-		LMT:        &set.AwareSet,
-		cfg:        *cfg,
-		awsManager: awsManager,
+	route := &ReportFileRoute{
+		dispatch: set,
+		LMT:      &set.AwareSet,
+		cfg:      *cfg,
+		storage:  storage,
 	}
+
+	if cfg.ReportFiles.TokenSecret != "" {
+		route.signer = downloadtoken.NewSigner(cfg.ReportFiles.TokenSecret)
+	}
+
+	return route
 }
 
 func (h *ReportFileRoute) Route(groups *common.Groups) {
 	groups.AuthUser.GET(reportFileDownloadPath, h.download)
 	groups.AuthProject.GET(reportFileDownloadPath, h.download)
+	groups.AuthUser.POST(reportFileDownloadTokenPath, h.createDownloadToken)
+	groups.AuthProject.POST(reportFileDownloadTokenPath, h.createDownloadToken)
+	groups.Common.GET(reportFileDownloadPath, h.downloadByToken)
 }
 
 // @summary Export the report file
-// @desc Export the report file into a PDF, CSV, XLSX
+// @desc Export the report file into a PDF, CSV, XLSX. The file is available to the user who requested it and to the users of its merchant
 // @id reportFileDownloadPathDownload
 // @tag Report file
 // @accept application/json
 // @produce application/pdf, text/csv, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @success 200 {string} Returns the report file
+// @success 206 {string} Returns the part of the report file requested with the Range header
+// @success 302 {string} Redirects to the presigned URL of the report file if the downloads are redirected
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data (unable to find the file, the file string is incorrect)
 // @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The report file not found or it belongs to the other user
+// @failure 416 {object} billingpb.ResponseErrorMessage The requested range of the report file is not satisfiable
 // @failure 500 {object} billingpb.ResponseErrorMessage Unable to download the file because of the internal server error
 // @param file_id path {string} true The unique identifier for the report file.
 // @param file_type path {string} true The supported file format (PDF, CSV, XLSX).
+// @param Range header {string} false The byte range of the report file part, e.g. bytes=0-1023.
 // @router /auth/api/v1/report_file/download/{file_id}.{file_type} [get]
 
 // @summary Export the report file
-// @desc Export the report file into a PDF, CSV, XLSX
+// @desc Export the report file into a PDF, CSV, XLSX. The file is available to the user who requested it and to the users of its merchant
 // @id reportFileDownloadPathDownloadByAdmin
 // @tag Report file
 // @accept application/json
 // @produce application/pdf, text/csv, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @success 200 {string} Returns the report file
+// @success 206 {string} Returns the part of the report file requested with the Range header
+// @success 302 {string} Redirects to the presigned URL of the report file if the downloads are redirected
 // @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data (unable to find the file, the file string is incorrect)
 // @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The report file not found or it belongs to the other user
+// @failure 416 {object} billingpb.ResponseErrorMessage The requested range of the report file is not satisfiable
 // @failure 500 {object} billingpb.ResponseErrorMessage Unable to download the file because of the internal server error
 // @param file_id path {string} true The unique identifier for the report file.
 // @param file_type path {string} true The supported file format (PDF, CSV, XLSX).
+// @param Range header {string} false The byte range of the report file part, e.g. bytes=0-1023.
 // @router /admin/api/v1/report_file/download/{file_id}.{file_type} [get]
 func (h *ReportFileRoute) download(ctx echo.Context) error {
+	fileName, err := h.fileName(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	user := common.ExtractUserContext(ctx)
+
+	return h.serve(ctx, fileName, user.Id, user.MerchantId)
+}
+
+// @summary Download the report file with the token
+// @desc Download the report file without the authorization using the token issued by the report_file/download/{file_id}.{file_type}/token method
+// @id reportFileDownloadPathDownloadByToken
+// @tag Report file
+// @accept application/json
+// @produce application/pdf, text/csv, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @success 200 {string} Returns the report file
+// @success 206 {string} Returns the part of the report file requested with the Range header
+// @success 302 {string} Redirects to the presigned URL of the report file if the downloads are redirected
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data (unable to find the file, the file string is incorrect)
+// @failure 401 {object} billingpb.ResponseErrorMessage The token is invalid, expired or issued for the other file
+// @failure 404 {object} billingpb.ResponseErrorMessage The report file not found or the download tokens are disabled
+// @failure 416 {object} billingpb.ResponseErrorMessage The requested range of the report file is not satisfiable
+// @failure 500 {object} billingpb.ResponseErrorMessage Unable to download the file because of the internal server error
+// @param file_id path {string} true The unique identifier for the report file.
+// @param file_type path {string} true The supported file format (PDF, CSV, XLSX).
+// @param token query {string} true The download token.
+// @param Range header {string} false The byte range of the report file part, e.g. bytes=0-1023.
+// @router /api/v1/report_file/download/{file_id}.{file_type} [get]
+func (h *ReportFileRoute) downloadByToken(ctx echo.Context) error {
+	if h.signer == nil {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorReportFileTokensDisabled)
+	}
+
+	fileName, err := h.fileName(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	claims, err := h.signer.Verify(ctx.QueryParam(common.RequestParameterToken))
+
+	if err == nil && claims.File != fileName {
+		err = downloadtoken.ErrInvalid
+	}
+
+	if err != nil {
+		h.denied(ctx, fileName, "", "", err.Error())
+		return echo.NewHTTPError(http.StatusUnauthorized, common.ErrorReportFileTokenInvalid)
+	}
+
+	return h.serve(ctx, fileName, claims.UserId, claims.MerchantId)
+}
+
+// @summary Create the download token of the report file
+// @desc Create the expiring token to download the report file without the authorization, e.g. by the browser link
+// @id reportFileDownloadTokenPathCreateDownloadToken
+// @tag Report file
+// @accept application/json
+// @produce application/json
+// @success 200 {object} ReportFileDownloadTokenResponse Returns the token and the download URL
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data (unable to find the file, the file string is incorrect)
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The report file not found, it belongs to the other user or the download tokens are disabled
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param file_id path {string} true The unique identifier for the report file.
+// @param file_type path {string} true The supported file format (PDF, CSV, XLSX).
+// @router /admin/api/v1/report_file/download/{file_id}.{file_type}/token [post]
+
+// @summary Create the download token of the report file
+// @desc Create the expiring token to download the report file without the authorization, e.g. by the browser link
+// @id reportFileDownloadTokenPathCreateDownloadTokenByProject
+// @tag Report file
+// @accept application/json
+// @produce application/json
+// @success 200 {object} ReportFileDownloadTokenResponse Returns the token and the download URL
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data (unable to find the file, the file string is incorrect)
+// @failure 401 {object} billingpb.ResponseErrorMessage Unauthorized request
+// @failure 404 {object} billingpb.ResponseErrorMessage The report file not found, it belongs to the other user or the download tokens are disabled
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param file_id path {string} true The unique identifier for the report file.
+// @param file_type path {string} true The supported file format (PDF, CSV, XLSX).
+// @router /auth/api/v1/report_file/download/{file_id}.{file_type}/token [post]
+func (h *ReportFileRoute) createDownloadToken(ctx echo.Context) error {
+	if h.signer == nil {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorReportFileTokensDisabled)
+	}
+
+	fileName, err := h.fileName(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	user := common.ExtractUserContext(ctx)
+
+	if err = h.checkOwner(ctx, fileName, user.Id, user.MerchantId); err != nil {
+		return err
+	}
+
+	if _, err = h.storage.Stat(ctx.Request().Context(), fileName); err != nil {
+		return h.storageError(fileName, err)
+	}
+
+	claims := &downloadtoken.Claims{File: fileName, UserId: user.Id, MerchantId: user.MerchantId}
+	token, expiresAt, err := h.signer.Sign(claims, h.cfg.ReportFiles.TokenTtl)
+
+	if err != nil {
+		h.L().Error("unable to sign the download token of the file "+fileName, logger.PairArgs("err", err.Error()))
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+	}
+
+	return ctx.JSON(http.StatusOK, &ReportFileDownloadTokenResponse{
+		Token:     token,
+		Url:       common.NoAuthGroupPath + "/report_file/download/" + url.PathEscape(fileName) + "?" + url.Values{common.RequestParameterToken: {token}}.Encode(),
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (h *ReportFileRoute) fileName(ctx echo.Context) (string, error) {
 	fileName := strings.TrimSpace(ctx.Param("file"))
 
 	if fileName == "" {
 		h.L().Error("unable to find the file")
-		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+		return "", echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	return fileName, nil
+}
+
+// serve streams the file or redirects to its presigned url if the file belongs to the user or to the merchant
+func (h *ReportFileRoute) serve(ctx echo.Context, fileName, userId, merchantId string) error {
+	if err := h.checkOwner(ctx, fileName, userId, merchantId); err != nil {
+		return err
+	}
+
+	fileType, ok := reportFileTypes[strings.ToLower(path.Ext(fileName))]
+
+	if !ok {
+		fileType = reportFileTypeDefault
+	}
+
+	disposition := mime.FormatMediaType(fileType.disposition, map[string]string{"filename": fileName})
+
+	if h.cfg.ReportFiles.Redirect {
+		if _, err := h.storage.Stat(ctx.Request().Context(), fileName); err != nil {
+			return h.storageError(fileName, err)
+		}
+
+		location, err := h.storage.PresignedUrl(fileName, fileType.contentType, disposition, h.cfg.ReportFiles.PresignTtl)
+
+		if err != nil {
+			h.L().Error("unable to presign the file "+fileName, logger.PairArgs("err", err.Error()))
+			return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessageDownloadReportFile)
+		}
+
+		return ctx.Redirect(http.StatusFound, location)
 	}
 
-	filePath := os.TempDir() + string(os.PathSeparator) + fileName
-	_, err := h.awsManager.Download(ctx.Request().Context(), filePath, &awsWrapper.DownloadInput{FileName: fileName})
+	object, err := h.storage.Open(ctx.Request().Context(), fileName, ctx.Request().Header.Get(common.HeaderRange))
 
 	if err != nil {
-		h.L().Error("unable to download the file " + fileName + " with message: " + err.Error())
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessageDownloadReportFile)
+		return h.storageError(fileName, err)
 	}
 
-	defer os.Remove(filePath)
-	return ctx.Inline(filePath, fileName)
+	defer object.Body.Close()
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, disposition)
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(object.ContentLength, 10))
+	header.Set(common.HeaderAcceptRanges, "bytes")
+
+	if object.ETag != "" {
+		header.Set(common.HeaderETag, object.ETag)
+	}
+
+	if !object.LastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, object.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	status := http.StatusOK
+
+	if object.ContentRange != "" {
+		header.Set(common.HeaderContentRange, object.ContentRange)
+		status = http.StatusPartialContent
+	}
+
+	return ctx.Stream(status, fileType.contentType, object.Body)
+}
+
+// checkOwner returns 404 if the file isn't requested by the user or for the merchant of the user, the owner is checked
+// before the file is read. The owner saved when the file was requested goes first, the files requested before it was
+// saved or after it expired are checked against the owner in the file metadata, the file of the unknown owner isn't
+// available to anyone
+func (h *ReportFileRoute) checkOwner(ctx echo.Context, fileName, userId, merchantId string) error {
+	owner, err := h.dispatch.ReportFiles.Get(ctx.Request().Context(), reportfile.FileId(fileName))
+
+	if err != nil {
+		h.L().Error("unable to get the owner of the file "+fileName, logger.PairArgs("err", err.Error()))
+	}
+
+	if owner == nil {
+		object, err := h.storage.Stat(ctx.Request().Context(), fileName)
+
+		if err != nil {
+			return h.storageError(fileName, err)
+		}
+
+		owner = reportfile.OwnerFromMetadata(object.Metadata)
+	}
+
+	if owner == nil {
+		h.denied(ctx, fileName, userId, merchantId, "file of the unknown owner")
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorReportFileNotFound)
+	}
+
+	if !owner.Owns(userId, merchantId) {
+		h.denied(ctx, fileName, userId, merchantId, "file of the other owner")
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorReportFileNotFound)
+	}
+
+	return nil
+}
+
+func (h *ReportFileRoute) storageError(fileName string, err error) error {
+	switch err {
+	case objectstorage.ErrNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorReportFileNotFound)
+	case objectstorage.ErrInvalidRange:
+		return echo.NewHTTPError(http.StatusRequestedRangeNotSatisfiable, common.ErrorReportFileRangeNotSatisfiable)
+	}
+
+	h.L().Error("unable to download the file " + fileName + " with message: " + err.Error())
+	return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessageDownloadReportFile)
+}
+
+// denied logs the download attempt of the file which isn't available to the user
+func (h *ReportFileRoute) denied(ctx echo.Context, fileName, userId, merchantId, reason string) {
+	h.L().Info(
+		"report file download denied",
+		logger.PairArgs(
+			"audit", "report_file_download_denied",
+			"file", fileName,
+			"user_id", userId,
+			"merchant_id", merchantId,
+			"ip", ctx.RealIP(),
+			"reason", reason,
+		),
+	)
 }
@@ -1,28 +1,35 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/labstack/echo/v4"
-	awsWrapper "github.com/paysuper/paysuper-aws-manager"
-	awsWrapperMocks "github.com/paysuper/paysuper-aws-manager/pkg/mocks"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/downloadtoken"
 	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/objectstorage"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"github.com/paysuper/paysuper-management-api/internal/test"
 	"github.com/stretchr/testify/assert"
 	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
-	"io"
+	"io/ioutil"
 	"net/http"
-	"os"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type ReportFileTestSuite struct {
 	suite.Suite
-	router *ReportFileRoute
-	caller *test.EchoReqResCaller
+	router  *ReportFileRoute
+	caller  *test.EchoReqResCaller
+	storage *mock.Storage
+	owners  reportfile.Store
+	content []byte
 }
 
 func Test_ReportFile(t *testing.T) {
@@ -41,49 +48,32 @@ func (suite *ReportFileTestSuite) SetupTest() {
 			Email:      "test@unit.test",
 			MerchantId: "ffffffffffffffffffffffff",
 		}))
-		downloadMockResultFn := func(
-			ctx context.Context,
-			filePath string,
-			in *awsWrapper.DownloadInput,
-			opts ...func(*s3manager.Downloader),
-		) int64 {
-			_, err := os.Stat(filePath)
-
-			if err == nil {
-				return 0
-			}
-
-			if !os.IsNotExist(err) {
-				return 0
-			}
-
-			src, err := os.Open(set.Initial.WorkDir + "/test/test_pdf.pdf")
-			if err != nil {
-				return 0
-			}
-			defer src.Close()
 
-			dst, err := os.Create(filePath)
-			if err != nil {
-				return 0
-			}
-			defer dst.Close()
-
-			nBytes, err := io.Copy(dst, src)
-
-			if err != nil {
-				return 0
-			}
+		content, err := ioutil.ReadFile(set.Initial.WorkDir + "/test/test_pdf.pdf")
+		if err != nil {
+			panic(err)
+		}
+		suite.content = content
 
-			return nBytes
+		suite.owners = set.HandlerSet.ReportFiles
+		for _, fileId := range []string{"test", "string", "royalty_report", "transactions", "vat_report(2020)", "unknown_format"} {
+			suite.saveOwner(fileId, &reportfile.Owner{MerchantId: "ffffffffffffffffffffffff"})
 		}
 
-		awsManagerMock := &awsWrapperMocks.AwsManagerInterface{}
-		awsManagerMock.On("Upload", mock2.Anything, mock2.Anything, mock2.Anything).Return(&s3manager.UploadOutput{}, nil)
-		awsManagerMock.On("Download", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything).
-			Return(downloadMockResultFn, nil)
+		suite.storage = &mock.Storage{}
+		suite.storage.On("Open", mock2.Anything, mock2.Anything, "").Return(
+			func(ctx context.Context, key, byteRange string) *objectstorage.Object {
+				return &objectstorage.Object{
+					Body:          ioutil.NopCloser(bytes.NewReader(suite.content)),
+					ContentLength: int64(len(suite.content)),
+					ETag:          `"e5b3f1"`,
+					LastModified:  time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC),
+				}
+			},
+			nil,
+		)
 
-		suite.router = NewReportFileRoute(set.HandlerSet, awsManagerMock, set.GlobalConfig)
+		suite.router = NewReportFileRoute(set.HandlerSet, suite.storage, set.GlobalConfig)
 		return common.Handlers{
 			suite.router,
 		}
@@ -108,12 +98,9 @@ func (suite *ReportFileTestSuite) TestReportFile_download_Error_ValidationFileEm
 }
 
 func (suite *ReportFileTestSuite) TestReportFile_download_Error_ValidationFileIncorrect() {
-	awsManagerMock := &awsWrapperMocks.AwsManagerInterface{}
-	awsManagerMock.On("Upload", mock2.Anything, mock2.Anything, mock2.Anything).Return(&s3manager.UploadOutput{}, nil)
-	awsManagerMock.On("Download", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything).
-		Return(int64(0), errors.New("Download_Error"))
-
-	suite.router.awsManager = awsManagerMock
+	storage := &mock.Storage{}
+	storage.On("Open", mock2.Anything, mock2.Anything, mock2.Anything).Return(nil, errors.New("Download_Error"))
+	suite.router.storage = storage
 
 	_, err := suite.caller.Builder().
 		Method(http.MethodGet).
@@ -129,13 +116,362 @@ func (suite *ReportFileTestSuite) TestReportFile_download_Error_ValidationFileIn
 }
 
 func (suite *ReportFileTestSuite) TestReportFile_download_Ok() {
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.csv").
+		Path(common.AuthProjectGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), suite.content, res.Body.Bytes())
+	assert.Equal(suite.T(), "text/csv", res.Header().Get(echo.HeaderContentType))
+	assert.Equal(suite.T(), `attachment; filename=string.csv`, res.Header().Get(echo.HeaderContentDisposition))
+	assert.Equal(suite.T(), strconv.Itoa(len(suite.content)), res.Header().Get(echo.HeaderContentLength))
+	assert.Equal(suite.T(), "bytes", res.Header().Get(common.HeaderAcceptRanges))
+	assert.Equal(suite.T(), `"e5b3f1"`, res.Header().Get(common.HeaderETag))
+	assert.Equal(suite.T(), "Wed, 15 Apr 2020 08:10:44 GMT", res.Header().Get(echo.HeaderLastModified))
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_ContentTypes() {
+	cases := map[string][2]string{
+		"royalty_report.pdf":   {"application/pdf", "inline; filename=royalty_report.pdf"},
+		"transactions.XLSX":    {reportFileTypes[".xlsx"].contentType, "attachment; filename=transactions.XLSX"},
+		"vat_report(2020).csv": {"text/csv", `attachment; filename="vat_report(2020).csv"`},
+		"unknown_format.json":  {echo.MIMEOctetStream, "attachment; filename=unknown_format.json"},
+	}
+
+	for fileName, headers := range cases {
+		res, err := suite.caller.Builder().
+			Method(http.MethodGet).
+			Params(":"+common.RequestParameterFile, fileName).
+			Path(common.AuthUserGroupPath + reportFileDownloadPath).
+			Init(test.ReqInitJSON()).
+			Exec(suite.T())
+
+		require.NoError(suite.T(), err, fileName)
+		assert.Equal(suite.T(), headers[0], res.Header().Get(echo.HeaderContentType), fileName)
+		assert.Equal(suite.T(), headers[1], res.Header().Get(echo.HeaderContentDisposition), fileName)
+	}
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Range() {
+	storage := &mock.Storage{}
+	storage.On("Open", mock2.Anything, "string.xlsx", "bytes=0-9").Return(&objectstorage.Object{
+		Body:          ioutil.NopCloser(bytes.NewReader(suite.content[:10])),
+		ContentLength: 10,
+		ContentRange:  "bytes 0-9/" + strconv.Itoa(len(suite.content)),
+	}, nil)
+	suite.router.storage = storage
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.xlsx").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(common.HeaderRange, "bytes=0-9")
+		}).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusPartialContent, res.Code)
+	assert.Equal(suite.T(), suite.content[:10], res.Body.Bytes())
+	assert.Equal(suite.T(), "10", res.Header().Get(echo.HeaderContentLength))
+	assert.Equal(suite.T(), "bytes 0-9/"+strconv.Itoa(len(suite.content)), res.Header().Get(common.HeaderContentRange))
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Error_RangeNotSatisfiable() {
+	storage := &mock.Storage{}
+	storage.On("Open", mock2.Anything, mock2.Anything, "bytes=100000000-").Return(nil, objectstorage.ErrInvalidRange)
+	suite.router.storage = storage
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.csv").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(common.HeaderRange, "bytes=100000000-")
+		}).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusRequestedRangeNotSatisfiable, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorReportFileRangeNotSatisfiable, httpErr.Message)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Error_NotFound() {
+	storage := &mock.Storage{}
+	storage.On("Open", mock2.Anything, mock2.Anything, mock2.Anything).Return(nil, objectstorage.ErrNotFound)
+	suite.router.storage = storage
 
 	_, err := suite.caller.Builder().
 		Method(http.MethodGet).
 		Params(":"+common.RequestParameterFile, "string.csv").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorReportFileNotFound, httpErr.Message)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Redirect() {
+	suite.router.cfg.ReportFiles.Redirect = true
+	suite.router.cfg.ReportFiles.PresignTtl = time.Minute
+
+	storage := &mock.Storage{}
+	storage.On("Stat", mock2.Anything, "string.xlsx").
+		Return(&objectstorage.Object{}, nil)
+	storage.On("PresignedUrl", "string.xlsx", reportFileTypes[".xlsx"].contentType, "attachment; filename=string.xlsx", time.Minute).
+		Return("https://reports.s3.amazonaws.com/string.xlsx?X-Amz-Signature=signature", nil)
+	suite.router.storage = storage
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.xlsx").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusFound, res.Code)
+	assert.Equal(suite.T(), "https://reports.s3.amazonaws.com/string.xlsx?X-Amz-Signature=signature", res.Header().Get(echo.HeaderLocation))
+	storage.AssertNotCalled(suite.T(), "Open", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+// saveOwner saves the owner of the file as the report file request does
+func (suite *ReportFileTestSuite) saveOwner(fileId string, owner *reportfile.Owner) {
+	if err := suite.owners.Save(context.Background(), fileId, owner); err != nil {
+		panic(err)
+	}
+}
+
+// setUpOwner replaces the owner of the string file and the storage with the one returning the file
+func (suite *ReportFileTestSuite) setUpOwner(owner *reportfile.Owner) *mock.Storage {
+	suite.saveOwner("string", owner)
+
+	storage := &mock.Storage{}
+	storage.On("Stat", mock2.Anything, mock2.Anything).Return(&objectstorage.Object{}, nil)
+	storage.On("Open", mock2.Anything, mock2.Anything, mock2.Anything).Return(
+		func(ctx context.Context, key, byteRange string) *objectstorage.Object {
+			return &objectstorage.Object{
+				Body:          ioutil.NopCloser(bytes.NewReader(suite.content)),
+				ContentLength: int64(len(suite.content)),
+			}
+		},
+		nil,
+	)
+	suite.router.storage = storage
+	return storage
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Error_OtherMerchant() {
+	storage := suite.setUpOwner(&reportfile.Owner{
+		UserId:     "5e96c1f4ff5d7c9a3c8d1b97",
+		MerchantId: "5e96c1f4ff5d7c9a3c8d1b99",
+	})
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.csv").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorReportFileNotFound, httpErr.Message)
+	assert.Empty(suite.T(), res.Body.Bytes())
+	storage.AssertNotCalled(suite.T(), "Open", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Error_OwnerUnknown() {
+	storage := suite.setUpOwner(&reportfile.Owner{MerchantId: "ffffffffffffffffffffffff"})
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "unknown.csv").
 		Path(common.AuthProjectGroupPath + reportFileDownloadPath).
 		Init(test.ReqInitJSON()).
 		Exec(suite.T())
 
-	assert.NoError(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	storage.AssertNotCalled(suite.T(), "Open", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_OwnerFromMetadata() {
+	storage := &mock.Storage{}
+	storage.On("Stat", mock2.Anything, "requested_before.csv").Return(&objectstorage.Object{
+		Metadata: map[string]string{reportfile.MetadataMerchantId: "ffffffffffffffffffffffff"},
+	}, nil)
+	storage.On("Open", mock2.Anything, "requested_before.csv", "").Return(&objectstorage.Object{
+		Body:          ioutil.NopCloser(bytes.NewReader(suite.content)),
+		ContentLength: int64(len(suite.content)),
+	}, nil)
+	suite.router.storage = storage
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "requested_before.csv").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), suite.content, res.Body.Bytes())
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Error_OtherMerchantInMetadata() {
+	storage := &mock.Storage{}
+	storage.On("Stat", mock2.Anything, "requested_before.csv").Return(&objectstorage.Object{
+		Metadata: map[string]string{
+			reportfile.MetadataUserId:     "5e96c1f4ff5d7c9a3c8d1b97",
+			reportfile.MetadataMerchantId: "5e96c1f4ff5d7c9a3c8d1b99",
+		},
+	}, nil)
+	suite.router.storage = storage
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "requested_before.csv").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorReportFileNotFound, httpErr.Message)
+	storage.AssertNotCalled(suite.T(), "Open", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_download_Redirect_OtherMerchant() {
+	suite.router.cfg.ReportFiles.Redirect = true
+	storage := suite.setUpOwner(&reportfile.Owner{MerchantId: "5e96c1f4ff5d7c9a3c8d1b99"})
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.csv").
+		Path(common.AuthUserGroupPath + reportFileDownloadPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	storage.AssertNotCalled(suite.T(), "PresignedUrl", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_downloadByToken_Ok() {
+	suite.router.signer = downloadtoken.NewSigner("secret")
+	suite.router.cfg.ReportFiles.TokenTtl = time.Minute
+	suite.setUpOwner(&reportfile.Owner{UserId: "ffffffffffffffffffffffff"})
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterFile, "string.pdf").
+		Path(common.AuthUserGroupPath + reportFileDownloadTokenPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+	rsp := &ReportFileDownloadTokenResponse{}
+	require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), rsp))
+	assert.NotEmpty(suite.T(), rsp.Token)
+	assert.Equal(suite.T(), common.NoAuthGroupPath+"/report_file/download/string.pdf?token="+rsp.Token, rsp.Url)
+	assert.True(suite.T(), rsp.ExpiresAt.After(time.Now()))
+
+	res, err = suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.pdf").
+		Path(common.NoAuthGroupPath+reportFileDownloadPath).
+		SetQueryParam(common.RequestParameterToken, rsp.Token).
+		Exec(suite.T())
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, res.Code)
+	assert.Equal(suite.T(), suite.content, res.Body.Bytes())
+	assert.Equal(suite.T(), "application/pdf", res.Header().Get(echo.HeaderContentType))
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_downloadByToken_Error_TokenInvalid() {
+	suite.router.signer = downloadtoken.NewSigner("secret")
+	storage := suite.setUpOwner(&reportfile.Owner{UserId: "ffffffffffffffffffffffff"})
+
+	other, _, err := suite.router.signer.Sign(&downloadtoken.Claims{File: "other.pdf", UserId: "ffffffffffffffffffffffff"}, time.Minute)
+	require.NoError(suite.T(), err)
+	expired, _, err := suite.router.signer.Sign(&downloadtoken.Claims{File: "string.pdf", UserId: "ffffffffffffffffffffffff"}, -time.Minute)
+	require.NoError(suite.T(), err)
+	forged, _, err := downloadtoken.NewSigner("forged").Sign(&downloadtoken.Claims{File: "string.pdf"}, time.Minute)
+	require.NoError(suite.T(), err)
+
+	for _, token := range []string{"", "token", other, expired, forged} {
+		_, err := suite.caller.Builder().
+			Method(http.MethodGet).
+			Params(":"+common.RequestParameterFile, "string.pdf").
+			Path(common.NoAuthGroupPath+reportFileDownloadPath).
+			SetQueryParam(common.RequestParameterToken, token).
+			Exec(suite.T())
+
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(suite.T(), ok, token)
+		assert.Equal(suite.T(), http.StatusUnauthorized, httpErr.Code, token)
+		assert.Equal(suite.T(), common.ErrorReportFileTokenInvalid, httpErr.Message, token)
+	}
+
+	storage.AssertNotCalled(suite.T(), "Open", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_createDownloadToken_Error_OtherMerchant() {
+	suite.router.signer = downloadtoken.NewSigner("secret")
+	suite.setUpOwner(&reportfile.Owner{MerchantId: "5e96c1f4ff5d7c9a3c8d1b99"})
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterFile, "string.pdf").
+		Path(common.AuthUserGroupPath + reportFileDownloadTokenPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorReportFileNotFound, httpErr.Message)
+}
+
+func (suite *ReportFileTestSuite) TestReportFile_Tokens_Disabled() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":"+common.RequestParameterFile, "string.pdf").
+		Path(common.AuthUserGroupPath + reportFileDownloadTokenPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorReportFileTokensDisabled, httpErr.Message)
+
+	_, err = suite.caller.Builder().
+		Method(http.MethodGet).
+		Params(":"+common.RequestParameterFile, "string.pdf").
+		Path(common.NoAuthGroupPath+reportFileDownloadPath).
+		SetQueryParam(common.RequestParameterToken, "token").
+		Exec(suite.T())
+
+	httpErr, ok = err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), common.ErrorReportFileTokensDisabled, httpErr.Message)
 }
@@ -59,7 +59,7 @@ func (h *TaxesRoute) getTaxes(ctx echo.Context) error {
 
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	return ctx.JSON(http.StatusOK, res.Rates)
@@ -131,7 +131,7 @@ func (h *TaxesRoute) setTax(ctx echo.Context) error {
 	res, err := h.dispatch.Services.Tax.CreateOrUpdate(ctx.Request().Context(), req)
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
@@ -162,7 +162,7 @@ func (h *TaxesRoute) deleteTax(ctx echo.Context) error {
 	res, err := h.dispatch.Services.Tax.DeleteRateById(ctx.Request().Context(), &taxpb.DeleteRateRequest{Id: uint32(value)})
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	return ctx.JSON(http.StatusOK, res)
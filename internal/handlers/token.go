@@ -63,7 +63,7 @@ func (h *TokenRoute) createToken(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	err = common.CheckProjectAuthRequestSignature(h.dispatch, ctx, req.Settings.ProjectId)
@@ -76,7 +76,7 @@ func (h *TokenRoute) createToken(ctx echo.Context) error {
 
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"github.com/globalsign/mgo/bson"
 	"github.com/labstack/echo/v4"
+	billMock "github.com/paysuper/paysuper-proto/go/billingpb/mocks"
+	mock2 "github.com/stretchr/testify/mock"
+	"strings"
 
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-management-api/internal/mock"
@@ -411,3 +414,101 @@ func (suite *TokenTestSuite) TestToken_CreateToken_ChangeCustomer_ResultError()
 	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
 	assert.Equal(suite.T(), mock.SomeError, httpErr.Message)
 }
+
+func (suite *TokenTestSuite) TestToken_CreateToken_RawBodySigned_Ok() {
+	b, err := json.Marshal(&billingpb.TokenRequest{
+		User: &billingpb.TokenUser{
+			Id: bson.NewObjectId().Hex(),
+			Email: &billingpb.TokenUserEmailValue{
+				Value: "test@unit.test",
+			},
+			Ip: &billingpb.TokenUserIpValue{
+				Value: "127.0.0.1",
+			},
+			Locale: &billingpb.TokenUserLocaleValue{
+				Value: "ru-RU",
+			},
+		},
+		Settings: &billingpb.TokenSettings{
+			ProjectId:   bson.NewObjectId().Hex(),
+			Currency:    "RUB",
+			Amount:      100,
+			Description: "test payment",
+			Type:        "simple",
+		},
+	})
+	assert.NoError(suite.T(), err)
+	body := string(b)
+
+	billingService := &billMock.BillingService{}
+	billingService.
+		On("CheckProjectRequestSignature", mock2.Anything, mock2.MatchedBy(func(req *billingpb.CheckProjectRequestSignatureRequest) bool {
+			return req.Body == body && req.Signature == "signature"
+		})).
+		Return(&billingpb.CheckProjectRequestSignatureResponse{Status: billingpb.ResponseStatusBadData, Message: mock.SomeError}, nil)
+	suite.router.dispatch.Services.Billing = billingService
+
+	_, err = suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + tokenPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			request.Header.Set(common.HeaderXApiSignatureHeader, "signature")
+		}).
+		BodyString(body).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	billingService.AssertExpectations(suite.T())
+}
+
+func (suite *TokenTestSuite) TestToken_CreateToken_BodyTooLarge_Error() {
+	settings := test.DefaultSettings()
+	settings["dispatcher"].(map[string]interface{})["bodyLimit"] = map[string]interface{}{
+		"groups": map[string]interface{}{"common": 1024},
+	}
+
+	caller, err := test.SetUp(settings, common.Services{Billing: mock.NewBillingServerOkMock()}, func(set *test.TestSet, mw test.Middleware) common.Handlers {
+		return common.Handlers{
+			NewTokenRoute(set.HandlerSet, set.GlobalConfig),
+		}
+	})
+	assert.NoError(suite.T(), err)
+
+	body := `{"settings": {"description": "` + strings.Repeat("a", 2048) + `"}}`
+	reqInit := func(request *http.Request, middleware test.Middleware) {
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request.Header.Set(common.HeaderXApiSignatureHeader, "signature")
+	}
+
+	_, err = caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + tokenPath).
+		Init(reqInit).
+		BodyString(body).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorRequestBodyTooLarge, httpErr.Message)
+
+	// the body of the unknown size is checked while it's read
+	_, err = caller.Builder().
+		Method(http.MethodPost).
+		Path(common.NoAuthGroupPath + tokenPath).
+		Init(func(request *http.Request, middleware test.Middleware) {
+			reqInit(request, middleware)
+			request.ContentLength = -1
+		}).
+		BodyString(body).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+
+	httpErr, ok = err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, httpErr.Code)
+}
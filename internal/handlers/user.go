@@ -62,13 +62,13 @@ func (h *UserRoute) checkInvite(ctx echo.Context) error {
 
 	err := h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CheckInviteToken(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "CheckInviteToken", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessageUnableToCheckInviteToken)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessageUnableToCheckInviteToken)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -103,13 +103,13 @@ func (h *UserRoute) approveInvite(ctx echo.Context) error {
 
 	err := h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.AcceptInvite(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "AcceptInvite", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorMessageUnableToAcceptInvite)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorMessageUnableToAcceptInvite)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -139,7 +139,7 @@ func (h *UserRoute) getMerchants(ctx echo.Context) error {
 	res, err := h.dispatch.Services.Billing.GetMerchantsForUser(ctx.Request().Context(), req)
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetMerchantsForUser", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -67,7 +67,8 @@ func (h *UserProfileRoute) Route(groups *common.Groups) {
 // @failure 403 {object} billingpb.ResponseErrorMessage Access denied
 // @failure 404 {object} billingpb.ResponseErrorMessage The user not found
 // @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
-// @router /system/api/v1/user/profile [get]
+// @param id path {string} true The unique identifier for the user.
+// @router /system/api/v1/user/profile/{id} [get]
 func (h *UserProfileRoute) getUserProfile(ctx echo.Context) error {
 	authUser := common.ExtractUserContext(ctx)
 	req := &billingpb.GetUserProfileRequest{
@@ -77,14 +78,14 @@ func (h *UserProfileRoute) getUserProfile(ctx echo.Context) error {
 	err := h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetUserProfile(ctx.Request().Context(), req)
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetUserProfile", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -159,14 +160,14 @@ func (h *UserProfileRoute) setUserProfile(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreateOrUpdateUserProfile(ctx.Request().Context(), req)
 
 	if err != nil {
 		h.L().Error(common.InternalErrorTemplate, logger.WithFields(logger.Fields{"err": err.Error()}))
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != http.StatusOK {
@@ -215,7 +216,7 @@ func (h *UserProfileRoute) confirmEmail(ctx echo.Context) error {
 	}
 
 	if err = h.dispatch.Validate.Struct(req2); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res2, err := h.dispatch.Services.Billing.ChangeMerchant(ctx.Request().Context(), req2)
@@ -261,13 +262,13 @@ func (h *UserProfileRoute) createFeedback(ctx echo.Context) error {
 	err = h.dispatch.Validate.Struct(req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.CreatePageReview(ctx.Request().Context(), req)
 
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorUnknown)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
 	}
 
 	if res.Status != http.StatusOK {
@@ -58,7 +58,7 @@ func (h *VatReportsRoute) getVatReportsDashboard(ctx echo.Context) error {
 
 	res, err := h.dispatch.Services.Billing.GetVatReportsDashboard(ctx.Request().Context(), &billingpb.EmptyRequest{})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return common.SrvCallError(err, http.StatusInternalServerError, err)
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -92,12 +92,12 @@ func (h *VatReportsRoute) getVatReportsForCountry(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetVatReportsForCountry(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return common.SrvCallError(err, http.StatusInternalServerError, err)
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -160,12 +160,12 @@ func (h *VatReportsRoute) getVatReportTransactions(ctx echo.Context) error {
 
 	err = h.dispatch.Validate.Struct(req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.GetVatReportTransactions(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return common.SrvCallError(err, http.StatusInternalServerError, err)
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -225,12 +225,12 @@ func (h *VatReportsRoute) updateVatReportStatus(ctx echo.Context) error {
 	req.Id = ctx.Param(common.RequestParameterId)
 
 	if err = h.dispatch.Validate.Struct(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, common.GetValidationError(err))
+		return common.NewValidationHTTPError(err)
 	}
 
 	res, err := h.dispatch.Services.Billing.UpdateVatReportStatus(ctx.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err)
+		return common.SrvCallError(err, http.StatusInternalServerError, err)
 	}
 	if res.Status != http.StatusOK {
 		return echo.NewHTTPError(int(res.Status), res.Message)
@@ -1,33 +1,184 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	awsWrapper "github.com/paysuper/paysuper-aws-manager"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
+	"github.com/paysuper/paysuper-management-api/internal/textpdf"
 	"github.com/paysuper/paysuper-proto/go/billingpb"
 	"net/http"
+	"strings"
+	"time"
 )
 
-const testMerchantWebhook = "/projects/:id/webhook/testing"
+const (
+	testMerchantWebhook      = "/projects/:id/webhook/testing"
+	testMerchantWebhookSuite = "/projects/:id/webhook/testing/suite"
+	resendWebhookPath        = "/order/:order_id/webhook/resend"
+	resendWebhooksPath       = "/order/webhook/resend"
+
+	// webhookResendOrdersMax is the maximum number of the orders matched by the filter of the bulk resend, the orders
+	// are resent within the request
+	webhookResendOrdersMax = 50
+
+	webhookNotifierServiceName = "webhook-notifier"
+	auditActionWebhookResend   = "webhook_resend"
+
+	webhookTestingOrderTypeSimple  = "simple"
+	webhookTestingOrderTypeProduct = "product"
+	webhookTestingOrderTypeKey     = "key"
+
+	webhookTestingCaseCorrectPayment   = "correct_payment"
+	webhookTestingCaseNonExistingUser  = "non_existing_user"
+	webhookTestingCaseExistingUser     = "existing_user"
+	webhookTestingCaseInvalidSignature = "invalid_signature"
+
+	// webhookTestingFilePrefix is the name prefix of the JSON and PDF files of the webhook testing reports in the
+	// reports bucket
+	webhookTestingFilePrefix   = "webhook_testing"
+	webhookTestingFileTypeJson = "json"
+	webhookTestingFileTypePdf  = "pdf"
+
+	// webhookTestingDeliveriesLimit is the maximum number of the delivery attempts of all projects read from the logs
+	// of the webhook notifier by each poll of the test suite
+	webhookTestingDeliveriesLimit = 1000
+)
+
+var (
+	// webhookResendStatuses are the order statuses notified to the projects
+	webhookResendStatuses = map[string]bool{
+		"processed":  true,
+		"canceled":   true,
+		"rejected":   true,
+		"refunded":   true,
+		"chargeback": true,
+	}
+)
+
+var (
+	// webhookTestingCases are the testing cases of each sales type with the HTTP statuses expected from the project
+	webhookTestingCases = []struct {
+		name     string
+		expected int
+	}{
+		{name: webhookTestingCaseCorrectPayment, expected: http.StatusOK},
+		{name: webhookTestingCaseNonExistingUser, expected: http.StatusBadRequest},
+		{name: webhookTestingCaseExistingUser, expected: http.StatusOK},
+		{name: webhookTestingCaseInvalidSignature, expected: http.StatusBadRequest},
+	}
+)
+
+type WebhookTestSuiteRequest struct {
+	// The unique identifier for the project.
+	ProjectId string `json:"-" param:"id" validate:"required,hexadecimal,len=24"`
+	// The order of the simple checkout test notifications. The simple type isn't tested if it's empty.
+	Simple *billingpb.OrderCreateRequest `json:"simple"`
+	// The order of the products test notifications. The product type isn't tested if it's empty.
+	Product *billingpb.OrderCreateRequest `json:"product"`
+	// The order of the key products test notifications. The key type isn't tested if it's empty.
+	Key *billingpb.OrderCreateRequest `json:"key"`
+}
+
+type WebhookTestCaseResult struct {
+	// The sales type. Available values: simple, product, key.
+	Type string `json:"type"`
+	// The webhook testing case. Available values: correct_payment, non_existing_user, existing_user, invalid_signature.
+	TestingCase string `json:"testing_case"`
+	// The unique identifier for the test order.
+	OrderId string `json:"order_id"`
+	// The HTTP status code expected from the project's webhook.
+	ExpectedStatus int `json:"expected_status"`
+	// The HTTP status code returned by the project's webhook. It's 0 if the notification wasn't delivered.
+	ActualStatus int `json:"actual_status"`
+	// The response time of the project's webhook in milliseconds.
+	ResponseTime int64 `json:"response_time"`
+	// The beginning of the project's webhook response body.
+	ResponseBody string `json:"response_body"`
+	// Has a true value if the project's webhook returned the expected HTTP status code.
+	Passed bool `json:"passed"`
+	// The hint on the cause of the failure.
+	Hint string `json:"hint,omitempty"`
+}
+
+type WebhookTestSummary struct {
+	// The number of the testing cases.
+	Total int `json:"total"`
+	// The number of the passed testing cases.
+	Passed int `json:"passed"`
+	// The number of the failed testing cases.
+	Failed int `json:"failed"`
+}
+
+type WebhookTestReport struct {
+	// The unique identifier for the report.
+	Id string `json:"id"`
+	// The unique identifier for the project.
+	ProjectId string `json:"project_id"`
+	// The date of the test run.
+	CreatedAt time.Time `json:"created_at"`
+	// The results of the testing cases.
+	Cases []*WebhookTestCaseResult `json:"cases"`
+	// The pass and fail summary.
+	Summary *WebhookTestSummary `json:"summary"`
+	// The name of the JSON report file to download with the report_file/download method.
+	File string `json:"file"`
+	// The name of the PDF report file to download with the report_file/download method.
+	PdfFile string `json:"pdf_file"`
+}
+
+type WebhookResendResponse struct {
+	// The list of the unique identifiers for the orders which notifications were resent.
+	Resent []string `json:"resent"`
+	// The list of the unique identifiers for the orders skipped because their statuses aren't notified to the project.
+	Skipped []string `json:"skipped"`
+	// The list of the unique identifiers for the orders which notifications failed to resend.
+	Failed []string `json:"failed"`
+}
 
 type WebHookRoute struct {
-	dispatch common.HandlerSet
-	cfg      common.Config
+	dispatch   common.HandlerSet
+	cfg        common.Config
+	awsManager awsWrapper.AwsManagerInterface
+	// deliveries are the logs of the webhook notifier with the delivery attempts of the test notifications
+	deliveries     string
+	orderLogSource orderlog.Source
 	provider.LMT
 }
 
-func NewWebHookRoute(set common.HandlerSet, cfg *common.Config) *WebHookRoute {
+func NewWebHookRoute(
+	set common.HandlerSet,
+	awsManager awsWrapper.AwsManagerInterface,
+	orderLogSource orderlog.Source,
+	cfg *common.Config,
+) *WebHookRoute {
 	set.AwareSet.Logger = set.AwareSet.Logger.WithFields(logger.Fields{"router": "WebHookRoute"})
 	return &WebHookRoute{
-		dispatch: set,
-		cfg:      *cfg,
-		LMT:      &set.AwareSet,
+		dispatch:       set,
+		cfg:            *cfg,
+		awsManager:     awsManager,
+		deliveries:     cfg.OrderLogStreams().WebhookNotifier,
+		orderLogSource: orderLogSource,
+		LMT:            &set.AwareSet,
 	}
 }
 
 func (h *WebHookRoute) Route(groups *common.Groups) {
 	groups.AuthUser.POST(testMerchantWebhook, h.sendWebhookTest)
+	groups.AuthUser.POST(testMerchantWebhookSuite, h.runWebhookTestSuite)
+	groups.AuthUser.POST(resendWebhookPath, h.resendWebhook)
+	groups.AuthUser.POST(resendWebhooksPath, h.resendWebhooks)
 }
 
 // @summary Test the project's webhook settings
@@ -60,7 +211,7 @@ func (h *WebHookRoute) sendWebhookTest(ctx echo.Context) error {
 
 	if err != nil {
 		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SendWebhookToMerchant", req)
-		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
 	}
 
 	if res.Status != billingpb.ResponseStatusOk {
@@ -69,3 +220,485 @@ func (h *WebHookRoute) sendWebhookTest(ctx echo.Context) error {
 
 	return ctx.JSON(http.StatusOK, res)
 }
+
+// @summary Run the project's webhook test suite
+// @desc Send the notifications of all testing cases for each sales type and check the responses of the project's webhook. The request is held open until the delivery attempts of all notifications are found in the webhook notifier logs, which are polled every second, or for 30 seconds at most (the WEBHOOK_TESTING_TIMEOUT and WEBHOOK_TESTING_POLL_INTERVAL settings). The report is saved as the JSON and PDF files.
+// @id testMerchantWebhookSuitePathRunWebhookTestSuite
+// @tag Project
+// @accept application/json
+// @produce application/json
+// @body WebhookTestSuiteRequest
+// @success 200 {object} WebhookTestReport Returns the webhook testing report
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 404 {object} billingpb.ResponseErrorMessage The project not found or it belongs to the other merchant
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param project_id path {string} true The unique identifier for the Project found in the merchant account in the PaySuper Dashboard.
+// @router /admin/api/v1/projects/{project_id}/webhook/testing/suite [post]
+func (h *WebHookRoute) runWebhookTestSuite(ctx echo.Context) error {
+	req := &WebhookTestSuiteRequest{}
+
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	orders := []struct {
+		orderType string
+		order     *billingpb.OrderCreateRequest
+	}{
+		{orderType: webhookTestingOrderTypeSimple, order: req.Simple},
+		{orderType: webhookTestingOrderTypeProduct, order: req.Product},
+		{orderType: webhookTestingOrderTypeKey, order: req.Key},
+	}
+	report := &WebhookTestReport{
+		Id:        uuid.New().String(),
+		ProjectId: req.ProjectId,
+		CreatedAt: time.Now().UTC(),
+		Cases:     []*WebhookTestCaseResult{},
+		Summary:   &WebhookTestSummary{},
+	}
+
+	templates := make(map[string]*billingpb.OrderCreateRequest)
+
+	for _, val := range orders {
+		if val.order == nil {
+			continue
+		}
+
+		val.order.ProjectId = req.ProjectId
+		val.order.Type = val.orderType
+		templates[val.orderType] = val.order
+
+		for _, testingCase := range webhookTestingCases {
+			report.Cases = append(report.Cases, &WebhookTestCaseResult{
+				Type:           val.orderType,
+				TestingCase:    testingCase.name,
+				ExpectedStatus: testingCase.expected,
+			})
+		}
+	}
+
+	if len(report.Cases) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorWebhookTestSuiteEmpty)
+	}
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	if err := h.checkProject(ctx, req.ProjectId); err != nil {
+		return err
+	}
+
+	reqCtx := ctx.Request().Context()
+	start := time.Now().Add(-time.Second)
+
+	for _, result := range report.Cases {
+		order := proto.Clone(templates[result.Type]).(*billingpb.OrderCreateRequest)
+		order.TestingCase = result.TestingCase
+		res, err := h.dispatch.Services.Billing.SendWebhookToMerchant(reqCtx, order)
+
+		if err != nil {
+			common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "SendWebhookToMerchant", order)
+			result.Hint = "the test notification wasn't sent because of the internal server error"
+			continue
+		}
+
+		if res.Status != billingpb.ResponseStatusOk {
+			result.Hint = "the test notification wasn't sent"
+
+			if res.Message != nil {
+				result.Hint += ": " + res.Message.Message
+			}
+
+			continue
+		}
+
+		result.OrderId = res.OrderId
+	}
+
+	if err := h.waitWebhookDeliveries(reqCtx, start, report.Cases); err != nil {
+		h.L().Error("wait of the webhook deliveries failed", logger.WithPrettyFields(logger.Fields{"err": err}))
+	}
+
+	for _, result := range report.Cases {
+		result.Passed = result.ActualStatus == result.ExpectedStatus
+
+		if result.Hint == "" {
+			result.Hint = webhookTestingHint(result)
+		}
+
+		report.Summary.Total++
+
+		if result.Passed {
+			report.Summary.Passed++
+		} else {
+			report.Summary.Failed++
+		}
+	}
+
+	if err := h.saveWebhookTestReport(ctx, report); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
+
+// checkProject returns 404 if the project doesn't belong to the merchant of the user
+func (h *WebHookRoute) checkProject(ctx echo.Context, projectId string) error {
+	merchantId := common.ExtractUserContext(ctx).MerchantId
+	req := &billingpb.GetProjectRequest{ProjectId: projectId, MerchantId: merchantId}
+	res, err := h.dispatch.Services.Billing.GetProject(ctx.Request().Context(), req)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(req, err, billingpb.ServiceName, "GetProject")
+	}
+
+	if res.Status == billingpb.ResponseStatusNotFound ||
+		(res.Status == billingpb.ResponseStatusOk && (res.Item == nil || res.Item.MerchantId != merchantId)) {
+		return echo.NewHTTPError(http.StatusNotFound, common.ErrorWebhookTestSuiteProjectNotFound)
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	return nil
+}
+
+// waitWebhookDeliveries polls the logs of the webhook notifier for the first delivery attempts of the test notifications
+// until all of them are found or the timeout is exceeded, each poll reads the delivery attempts of all projects since
+// the previous one with a single query and matches them to the pending cases
+func (h *WebHookRoute) waitWebhookDeliveries(ctx context.Context, start time.Time, cases []*WebhookTestCaseResult) error {
+	pending := make(map[string]*WebhookTestCaseResult)
+
+	for _, result := range cases {
+		if result.OrderId != "" {
+			pending[result.OrderId] = result
+		}
+	}
+
+	timeout := time.NewTimer(h.cfg.WebhookTesting.Timeout)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(h.cfg.WebhookTesting.PollInterval)
+	defer ticker.Stop()
+
+	for len(pending) > 0 {
+		query := &orderlog.Query{
+			Stream: h.deliveries,
+			Terms:  []string{"delivery_try"},
+			Start:  start,
+			End:    time.Now().Add(time.Second),
+			Limit:  webhookTestingDeliveriesLimit,
+		}
+		page, err := h.orderLogSource.Filter(ctx, query)
+
+		if err != nil {
+			return err
+		}
+
+		for _, event := range page.Events {
+			// the start is inclusive, so the events of the last second are read again by the next poll
+			if event.Timestamp.After(start) {
+				start = event.Timestamp
+			}
+
+			for orderId, result := range pending {
+				if !strings.Contains(event.Message, orderId) {
+					continue
+				}
+
+				delivery, ok := newWebhookDelivery(&billingpb.OrderViewPublic{Uuid: orderId}, event)
+
+				if !ok {
+					continue
+				}
+
+				result.ActualStatus = delivery.StatusCode
+				result.ResponseTime = delivery.Latency
+				result.ResponseBody = delivery.ResponseBody
+				delete(pending, orderId)
+			}
+		}
+
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// saveWebhookTestReport stores the JSON and PDF files of the report in the reports bucket, the files are downloaded by
+// the user or the merchant of the test run through the report file download
+func (h *WebHookRoute) saveWebhookTestReport(ctx echo.Context, report *WebhookTestReport) error {
+	fileId := webhookTestingFilePrefix + "_" + report.Id
+	report.File = fileId + "." + webhookTestingFileTypeJson
+	report.PdfFile = fileId + "." + webhookTestingFileTypePdf
+	b, err := json.Marshal(report)
+
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorInternal)
+	}
+
+	user := common.ExtractUserContext(ctx)
+	owner := &reportfile.Owner{UserId: user.Id, MerchantId: user.MerchantId}
+
+	if err = h.dispatch.ReportFiles.Save(ctx.Request().Context(), fileId, owner); err != nil {
+		h.L().Error("unable to save the owner of the file "+fileId, logger.WithPrettyFields(logger.Fields{"err": err}))
+		return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorWebhookTestSuiteSave)
+	}
+
+	files := []struct {
+		name string
+		body []byte
+	}{
+		{name: report.File, body: b},
+		{name: report.PdfFile, body: renderWebhookTestReport(report)},
+	}
+
+	for _, file := range files {
+		input := &awsWrapper.UploadInput{Body: bytes.NewReader(file.body), FileName: file.name}
+
+		if _, err := h.awsManager.Upload(ctx.Request().Context(), input); err != nil {
+			h.L().Error("unable to upload the file "+file.name, logger.WithPrettyFields(logger.Fields{"err": err}))
+			return echo.NewHTTPError(http.StatusInternalServerError, common.ErrorWebhookTestSuiteSave)
+		}
+	}
+
+	return nil
+}
+
+// renderWebhookTestReport returns the PDF document of the report with the summary and the result of each testing case
+func renderWebhookTestReport(report *WebhookTestReport) []byte {
+	lines := []string{
+		"Report: " + report.Id,
+		"Project: " + report.ProjectId,
+		"Date: " + report.CreatedAt.Format(time.RFC3339),
+		fmt.Sprintf("Total: %d, passed: %d, failed: %d", report.Summary.Total, report.Summary.Passed, report.Summary.Failed),
+	}
+
+	for _, result := range report.Cases {
+		status := "FAILED"
+
+		if result.Passed {
+			status = "PASSED"
+		}
+
+		lines = append(
+			lines,
+			"",
+			fmt.Sprintf("%s: %s %s", status, result.Type, result.TestingCase),
+			"Order: "+result.OrderId,
+			fmt.Sprintf(
+				"Expected status: %d, actual status: %d, response time: %d ms",
+				result.ExpectedStatus, result.ActualStatus, result.ResponseTime,
+			),
+		)
+
+		if result.ResponseBody != "" {
+			lines = append(lines, "Response: "+result.ResponseBody)
+		}
+
+		if result.Hint != "" {
+			lines = append(lines, "Hint: "+result.Hint)
+		}
+	}
+
+	return textpdf.Render("Webhook testing report", lines)
+}
+
+// webhookTestingHint returns the hint on the likely cause of the failed testing case
+func webhookTestingHint(result *WebhookTestCaseResult) string {
+	switch {
+	case result.Passed:
+		return ""
+	case result.ActualStatus == 0:
+		return "the notification wasn't delivered in time, check the project's webhook url is reachable"
+	case result.ActualStatus >= http.StatusInternalServerError:
+		return "the project's webhook failed to process the notification"
+	case result.TestingCase == webhookTestingCaseInvalidSignature:
+		return "the notification with the invalid signature was accepted, check the signature of the notifications is verified"
+	case result.TestingCase == webhookTestingCaseNonExistingUser:
+		return "the notification of the unknown user was accepted, check the user is looked up by the external identifier"
+	case result.ActualStatus == http.StatusBadRequest || result.ActualStatus == http.StatusUnauthorized ||
+		result.ActualStatus == http.StatusForbidden:
+		return "the valid notification was rejected, check the signature is calculated over the raw request body with the project's secret key"
+	}
+
+	return fmt.Sprintf("the project's webhook is expected to return the %d status", result.ExpectedStatus)
+}
+
+// @summary Resend the order's notification
+// @desc Resend the notification about the order's current status to the project's webhook
+// @id resendWebhookPathResendWebhook
+// @tag Order
+// @accept application/json
+// @produce application/json
+// @success 200 {object} WebhookResendResponse Returns the unique identifier for the order
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 404 {object} billingpb.ResponseErrorMessage Not found
+// @failure 429 {object} billingpb.ResponseErrorMessage Too many requests
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @param order_id path {string} true The unique identifier for the order.
+// @router /admin/api/v1/order/{order_id}/webhook/resend [post]
+func (h *WebHookRoute) resendWebhook(ctx echo.Context) error {
+	req := &billingpb.GetOrderRequest{}
+
+	if err := h.dispatch.BindAndValidate(req, ctx); err != nil {
+		return err
+	}
+
+	// the merchant binder scopes the order to the merchant of the user
+	rsp, err := h.dispatch.Services.Billing.GetOrderPublic(ctx.Request().Context(), req)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(req, err, billingpb.ServiceName, "GetOrderPublic")
+	}
+
+	if rsp.Status != billingpb.ResponseStatusOk {
+		return echo.NewHTTPError(int(rsp.Status), rsp.Message)
+	}
+
+	if !webhookResendStatuses[rsp.Item.Status] {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorWebhookResendOrderStatus)
+	}
+
+	if err := h.resend(ctx, req.MerchantId, rsp.Item); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, &WebhookResendResponse{
+		Resent:  []string{rsp.Item.Uuid},
+		Skipped: []string{},
+		Failed:  []string{},
+	})
+}
+
+// @summary Resend the notifications of the orders
+// @desc Resend the notifications about the current statuses of the orders matched by the filter to the projects' webhooks. The filter must match at most 50 orders.
+// @id resendWebhooksPathResendWebhooks
+// @tag Order
+// @accept application/json
+// @produce application/json
+// @body billingpb.ListOrdersRequest
+// @success 200 {object} WebhookResendResponse Returns the unique identifiers for the resent, skipped and failed orders
+// @failure 400 {object} billingpb.ResponseErrorMessage Invalid request data
+// @failure 429 {object} billingpb.ResponseErrorMessage Too many requests
+// @failure 500 {object} billingpb.ResponseErrorMessage Internal Server Error
+// @router /admin/api/v1/order/webhook/resend [post]
+func (h *WebHookRoute) resendWebhooks(ctx echo.Context) error {
+	req := &billingpb.ListOrdersRequest{}
+
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorRequestParamsIncorrect)
+	}
+
+	if len(req.Merchant) != 1 || req.Merchant[0] == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorIncorrectMerchantId)
+	}
+
+	req.Limit = webhookResendOrdersMax
+	req.Offset = 0
+
+	if err := h.dispatch.Validate.Struct(req); err != nil {
+		return common.NewValidationHTTPError(err)
+	}
+
+	res, err := h.dispatch.Services.Billing.FindAllOrdersPublic(ctx.Request().Context(), req)
+
+	if err != nil {
+		return h.dispatch.SrvCallHandler(req, err, billingpb.ServiceName, "FindAllOrdersPublic")
+	}
+
+	if res.Status != billingpb.ResponseStatusOk {
+		return echo.NewHTTPError(int(res.Status), res.Message)
+	}
+
+	result := &WebhookResendResponse{Resent: []string{}, Skipped: []string{}, Failed: []string{}}
+
+	if res.Item == nil {
+		return ctx.JSON(http.StatusOK, result)
+	}
+
+	if res.Item.Count > webhookResendOrdersMax {
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorWebhookResendTooManyOrders)
+	}
+
+	for _, order := range res.Item.Items {
+		switch {
+		case !webhookResendStatuses[order.Status]:
+			result.Skipped = append(result.Skipped, order.Uuid)
+		case h.resend(ctx, req.Merchant[0], order) != nil:
+			result.Failed = append(result.Failed, order.Uuid)
+		default:
+			result.Resent = append(result.Resent, order.Uuid)
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// resend publishes the current state of the order to the webhook notifier and writes the audit record of the attempt
+func (h *WebHookRoute) resend(ctx echo.Context, merchantId string, view *billingpb.OrderViewPublic) error {
+	err := h.publishOrder(ctx, merchantId, view)
+	record := &audit.Record{
+		Action:     auditActionWebhookResend,
+		UserId:     common.ExtractUserContext(ctx).Id,
+		MerchantId: merchantId,
+		ObjectId:   view.Uuid,
+		Result:     audit.ResultSuccess,
+		Details:    map[string]string{"order_status": view.Status},
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err != nil {
+		record.Result = audit.ResultFailed
+	}
+
+	if e := h.dispatch.Audit.Write(ctx.Request().Context(), record); e != nil {
+		h.L().Error(
+			"unable to write the audit record",
+			logger.PairArgs("action", record.Action, "merchant_id", merchantId, "order_id", view.Uuid),
+			logger.WithPrettyFields(logger.Fields{"err": e}),
+		)
+	}
+
+	if err != nil {
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorInternal)
+	}
+
+	return nil
+}
+
+// publishOrder reads the current state of the order and publishes it to the webhook notifier
+func (h *WebHookRoute) publishOrder(ctx echo.Context, merchantId string, view *billingpb.OrderViewPublic) error {
+	req := &billingpb.GetOrderRequest{OrderId: view.Uuid, MerchantId: merchantId}
+	order, err := h.dispatch.Services.Billing.GetOrder(ctx.Request().Context(), req)
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, billingpb.ServiceName, "GetOrder", req)
+		return err
+	}
+
+	if h.dispatch.Services.WebhookNotifier == nil {
+		err = errors.New("webhook notifier isn't configured")
+	} else {
+		err = h.dispatch.Services.WebhookNotifier.Publish(ctx.Request().Context(), order)
+	}
+
+	if err != nil {
+		common.LogSrvCallFailedGRPC(h.L(), err, webhookNotifierServiceName, "Publish", req)
+		return err
+	}
+
+	return nil
+}
@@ -1,19 +1,41 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/micro/go-micro/client"
+	awsWrapperMocks "github.com/paysuper/paysuper-aws-manager/pkg/mocks"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
 	"github.com/paysuper/paysuper-management-api/internal/mock"
+	"github.com/paysuper/paysuper-management-api/internal/orderlog"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"github.com/paysuper/paysuper-management-api/internal/test"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	billMock "github.com/paysuper/paysuper-proto/go/billingpb/mocks"
 	"github.com/stretchr/testify/assert"
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 type WebhookReportsTestSuite struct {
 	suite.Suite
-	router *WebHookRoute
-	caller *test.EchoReqResCaller
+	router     *WebHookRoute
+	caller     *test.EchoReqResCaller
+	awsManager *awsWrapperMocks.AwsManagerInterface
 }
 
 func Test_Webhook(t *testing.T) {
@@ -21,13 +43,22 @@ func Test_Webhook(t *testing.T) {
 }
 
 func (suite *WebhookReportsTestSuite) SetupTest() {
+	user := &common.AuthUser{
+		Id:         "ffffffffffffffffffffffff",
+		MerchantId: "ffffffffffffffffffffffff",
+	}
+
 	var e error
 	settings := test.DefaultSettings()
 	srv := common.Services{
 		Billing: mock.NewBillingServerOkMock(),
 	}
 	suite.caller, e = test.SetUp(settings, srv, func(set *test.TestSet, mw test.Middleware) common.Handlers {
-		suite.router = NewWebHookRoute(set.HandlerSet, set.GlobalConfig)
+		mw.Pre(test.PreAuthUserMiddleware(user))
+		suite.awsManager = &awsWrapperMocks.AwsManagerInterface{}
+		suite.awsManager.On("Upload", mock2.Anything, mock2.Anything, mock2.Anything).Return(&s3manager.UploadOutput{}, nil)
+		orderLogSource := orderlog.NewFile(&orderlog.FileConfig{TimestampField: "ts"})
+		suite.router = NewWebHookRoute(set.HandlerSet, suite.awsManager, orderLogSource, set.GlobalConfig)
 		return common.Handlers{
 			suite.router,
 		}
@@ -52,3 +83,315 @@ func (suite *WebhookReportsTestSuite) TestWebhook_sendTest_Ok() {
 		assert.NotEmpty(suite.T(), res.Body.String())
 	}
 }
+
+func (suite *WebhookReportsTestSuite) TestWebhook_Resend_Ok() {
+	order := &billingpb.OrderViewPublic{Uuid: uuid.New().String(), Status: "processed"}
+
+	billingMock := &billMock.BillingService{}
+	billingMock.On("GetOrderPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(&billingpb.GetOrderPublicResponse{Status: billingpb.ResponseStatusOk, Item: order}, nil)
+	billingMock.On("GetOrder", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(&billingpb.Order{Uuid: order.Uuid, Status: order.Status}, nil)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	publisher := &mock.Publisher{}
+	publisher.On("Publish", mock2.Anything, mock2.Anything).Return(nil)
+	suite.router.dispatch.Services.WebhookNotifier = publisher
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", order.Uuid).
+		Path(common.AuthUserGroupPath + resendWebhookPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+		result := new(WebhookResendResponse)
+		assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), result))
+		assert.Equal(suite.T(), []string{order.Uuid}, result.Resent)
+		publisher.AssertNumberOfCalls(suite.T(), "Publish", 1)
+	}
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_Resend_OrderStatusError() {
+	billingMock := &billMock.BillingService{}
+	billingMock.On("GetOrderPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(
+			&billingpb.GetOrderPublicResponse{
+				Status: billingpb.ResponseStatusOk,
+				Item:   &billingpb.OrderViewPublic{Uuid: uuid.New().String(), Status: "created"},
+			},
+			nil,
+		)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	publisher := &mock.Publisher{}
+	suite.router.dispatch.Services.WebhookNotifier = publisher
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", uuid.New().String()).
+		Path(common.AuthUserGroupPath + resendWebhookPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorWebhookResendOrderStatus, httpErr.Message)
+	publisher.AssertNotCalled(suite.T(), "Publish", mock2.Anything, mock2.Anything)
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_ResendBulk_Ok() {
+	orders := []*billingpb.OrderViewPublic{
+		{Uuid: uuid.New().String(), Status: "processed"},
+		{Uuid: uuid.New().String(), Status: "created"},
+		{Uuid: uuid.New().String(), Status: "refunded"},
+	}
+
+	var ordersReq *billingpb.ListOrdersRequest
+	billingMock := &billMock.BillingService{}
+	billingMock.On("FindAllOrdersPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Run(func(args mock2.Arguments) {
+			ordersReq = args.Get(1).(*billingpb.ListOrdersRequest)
+		}).
+		Return(
+			&billingpb.ListOrdersPublicResponse{
+				Status: billingpb.ResponseStatusOk,
+				Item:   &billingpb.ListOrdersPublicResponseItem{Count: 3, Items: orders},
+			},
+			nil,
+		)
+	orderReq := &billingpb.GetOrderRequest{OrderId: orders[0].Uuid, MerchantId: "ffffffffffffffffffffffff"}
+	billingMock.On("GetOrder", mock2.Anything, orderReq, mock2.Anything).
+		Return(&billingpb.Order{Uuid: orders[0].Uuid}, nil)
+	billingMock.On("GetOrder", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(nil, errors.New("some error"))
+	suite.router.dispatch.Services.Billing = billingMock
+
+	publisher := &mock.Publisher{}
+	publisher.On("Publish", mock2.Anything, mock2.Anything).Return(nil)
+	suite.router.dispatch.Services.WebhookNotifier = publisher
+
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath + resendWebhooksPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"status":["processed","created","refunded"],"offset":100}`).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+		result := new(WebhookResendResponse)
+		assert.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), result))
+		assert.Equal(suite.T(), []string{orders[0].Uuid}, result.Resent)
+		assert.Equal(suite.T(), []string{orders[1].Uuid}, result.Skipped)
+		assert.Equal(suite.T(), []string{orders[2].Uuid}, result.Failed)
+
+		assert.Equal(suite.T(), []string{"ffffffffffffffffffffffff"}, ordersReq.Merchant)
+		assert.EqualValues(suite.T(), webhookResendOrdersMax, ordersReq.Limit)
+		assert.Zero(suite.T(), ordersReq.Offset)
+		publisher.AssertNumberOfCalls(suite.T(), "Publish", 1)
+
+		records := suite.router.dispatch.Audit.(*audit.MemoryStore).Records("ffffffffffffffffffffffff")
+		require.Len(suite.T(), records, 2)
+		assert.Equal(suite.T(), auditActionWebhookResend, records[0].Action)
+		assert.Equal(suite.T(), "ffffffffffffffffffffffff", records[0].UserId)
+		assert.Equal(suite.T(), orders[0].Uuid, records[0].ObjectId)
+		assert.Equal(suite.T(), audit.ResultSuccess, records[0].Result)
+		assert.Equal(suite.T(), orders[2].Uuid, records[1].ObjectId)
+		assert.Equal(suite.T(), audit.ResultFailed, records[1].Result)
+	}
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_Resend_PublishError() {
+	order := &billingpb.OrderViewPublic{Uuid: uuid.New().String(), Status: "processed"}
+
+	billingMock := &billMock.BillingService{}
+	billingMock.On("GetOrderPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(&billingpb.GetOrderPublicResponse{Status: billingpb.ResponseStatusOk, Item: order}, nil)
+	billingMock.On("GetOrder", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(&billingpb.Order{Uuid: order.Uuid, Status: order.Status}, nil)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	publisher := &mock.Publisher{}
+	publisher.On("Publish", mock2.Anything, mock2.Anything).Return(errors.New("broker is unavailable"))
+	suite.router.dispatch.Services.WebhookNotifier = publisher
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":order_id", order.Uuid).
+		Path(common.AuthUserGroupPath + resendWebhookPath).
+		Init(test.ReqInitJSON()).
+		Exec(suite.T())
+
+	require.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusInternalServerError, httpErr.Code)
+
+	records := suite.router.dispatch.Audit.(*audit.MemoryStore).Records("ffffffffffffffffffffffff")
+	require.Len(suite.T(), records, 1)
+	assert.Equal(suite.T(), order.Uuid, records[0].ObjectId)
+	assert.Equal(suite.T(), audit.ResultFailed, records[0].Result)
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_ResendBulk_TooManyOrdersError() {
+	billingMock := &billMock.BillingService{}
+	billingMock.On("FindAllOrdersPublic", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(
+			&billingpb.ListOrdersPublicResponse{
+				Status: billingpb.ResponseStatusOk,
+				Item:   &billingpb.ListOrdersPublicResponseItem{Count: webhookResendOrdersMax + 1},
+			},
+			nil,
+		)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Path(common.AuthUserGroupPath + resendWebhooksPath).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"project":["` + strings.Repeat("a", 24) + `"]}`).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorWebhookResendTooManyOrders, httpErr.Message)
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_RunTestSuite_Ok() {
+	billingMock := &billMock.BillingService{}
+	billingMock.On("SendWebhookToMerchant", mock2.Anything, mock2.Anything, mock2.Anything).
+		Return(
+			func(_ context.Context, in *billingpb.OrderCreateRequest, _ ...client.CallOption) *billingpb.SendWebhookToMerchantResponse {
+				return &billingpb.SendWebhookToMerchantResponse{
+					Status:  billingpb.ResponseStatusOk,
+					OrderId: in.Type + "-" + in.TestingCase,
+				}
+			},
+			nil,
+		)
+	billingMock.On("GetProject", mock2.Anything, mock2.Anything, mock2.Anything).Return(&billingpb.ChangeProjectResponse{
+		Status: billingpb.ResponseStatusOk,
+		Item:   &billingpb.Project{MerchantId: "ffffffffffffffffffffffff"},
+	}, nil)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	record := func(testingCase string, status int) string {
+		return fmt.Sprintf(
+			`{"level":"info","ts":%d,"msg":"delivery_try","order_id":"simple-%s","url":"https://project.unit.test/webhook",`+
+				`"response_status":%d,"latency":"120ms","try":1,"response_body":"ok"}`,
+			time.Now().Unix(), testingCase, status,
+		)
+	}
+	lines := []string{
+		record(webhookTestingCaseCorrectPayment, http.StatusOK),
+		record(webhookTestingCaseNonExistingUser, http.StatusBadRequest),
+		record(webhookTestingCaseInvalidSignature, http.StatusOK),
+	}
+
+	dir, err := ioutil.TempDir("", "webhook_testing")
+	require.NoError(suite.T(), err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "webhook_notifier.log")
+	err = ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	require.NoError(suite.T(), err)
+
+	suite.router.deliveries = path
+	suite.router.cfg.WebhookTesting.Timeout = 100 * time.Millisecond
+	suite.router.cfg.WebhookTesting.PollInterval = 10 * time.Millisecond
+
+	projectId := strings.Repeat("a", 24)
+	res, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":id", projectId).
+		Path(common.AuthUserGroupPath + testMerchantWebhookSuite).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"simple":{"amount":10,"currency":"USD","user":{"external_id":"123"}}}`).
+		Exec(suite.T())
+
+	if assert.NoError(suite.T(), err) {
+		assert.Equal(suite.T(), http.StatusOK, res.Code)
+
+		report := new(WebhookTestReport)
+		require.NoError(suite.T(), json.Unmarshal(res.Body.Bytes(), report))
+		assert.Equal(suite.T(), projectId, report.ProjectId)
+		assert.Equal(suite.T(), &WebhookTestSummary{Total: 4, Passed: 2, Failed: 2}, report.Summary)
+		assert.Equal(suite.T(), "webhook_testing_"+report.Id+".json", report.File)
+		require.Len(suite.T(), report.Cases, 4)
+
+		cases := make(map[string]*WebhookTestCaseResult)
+
+		for _, result := range report.Cases {
+			assert.Equal(suite.T(), webhookTestingOrderTypeSimple, result.Type)
+			cases[result.TestingCase] = result
+		}
+
+		assert.True(suite.T(), cases[webhookTestingCaseCorrectPayment].Passed)
+		assert.EqualValues(suite.T(), 120, cases[webhookTestingCaseCorrectPayment].ResponseTime)
+		assert.True(suite.T(), cases[webhookTestingCaseNonExistingUser].Passed)
+		assert.False(suite.T(), cases[webhookTestingCaseExistingUser].Passed)
+		assert.Zero(suite.T(), cases[webhookTestingCaseExistingUser].ActualStatus)
+		assert.Contains(suite.T(), cases[webhookTestingCaseExistingUser].Hint, "wasn't delivered")
+		assert.False(suite.T(), cases[webhookTestingCaseInvalidSignature].Passed)
+		assert.Equal(suite.T(), http.StatusBadRequest, cases[webhookTestingCaseInvalidSignature].ExpectedStatus)
+		assert.Contains(suite.T(), cases[webhookTestingCaseInvalidSignature].Hint, "invalid signature")
+
+		billingMock.AssertNumberOfCalls(suite.T(), "SendWebhookToMerchant", 4)
+		suite.awsManager.AssertNumberOfCalls(suite.T(), "Upload", 2)
+		assert.Equal(suite.T(), "webhook_testing_"+report.Id+".json", report.File)
+		assert.Equal(suite.T(), "webhook_testing_"+report.Id+".pdf", report.PdfFile)
+
+		owner, err := suite.router.dispatch.ReportFiles.Get(context.Background(), "webhook_testing_"+report.Id)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), &reportfile.Owner{UserId: "ffffffffffffffffffffffff", MerchantId: "ffffffffffffffffffffffff"}, owner)
+	}
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_RunTestSuite_ProjectOfOtherMerchantError() {
+	billingMock := &billMock.BillingService{}
+	billingMock.On("GetProject", mock2.Anything, mock2.Anything, mock2.Anything).Return(&billingpb.ChangeProjectResponse{
+		Status: billingpb.ResponseStatusOk,
+		Item:   &billingpb.Project{MerchantId: "5e96c1f4ff5d7c9a3c8d1b99"},
+	}, nil)
+	suite.router.dispatch.Services.Billing = billingMock
+
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":id", strings.Repeat("a", 24)).
+		Path(common.AuthUserGroupPath + testMerchantWebhookSuite).
+		Init(test.ReqInitJSON()).
+		BodyString(`{"simple":{"amount":10,"currency":"USD","user":{"external_id":"123"}}}`).
+		Exec(suite.T())
+
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusNotFound, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorWebhookTestSuiteProjectNotFound, httpErr.Message)
+	billingMock.AssertNotCalled(suite.T(), "SendWebhookToMerchant", mock2.Anything, mock2.Anything, mock2.Anything)
+	suite.awsManager.AssertNotCalled(suite.T(), "Upload", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *WebhookReportsTestSuite) TestWebhook_RunTestSuite_EmptyError() {
+	_, err := suite.caller.Builder().
+		Method(http.MethodPost).
+		Params(":id", strings.Repeat("a", 24)).
+		Path(common.AuthUserGroupPath + testMerchantWebhookSuite).
+		Init(test.ReqInitJSON()).
+		BodyString(`{}`).
+		Exec(suite.T())
+
+	assert.Error(suite.T(), err)
+	httpErr, ok := err.(*echo.HTTPError)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), http.StatusBadRequest, httpErr.Code)
+	assert.Equal(suite.T(), common.ErrorWebhookTestSuiteEmpty, httpErr.Message)
+}
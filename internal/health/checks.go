@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/micro/go-micro/client"
+	"net"
+)
+
+// AwsCredentials
+type AwsCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	Region          string
+}
+
+func (c *AwsCredentials) session() (*session.Session, error) {
+	return session.NewSession(&aws.Config{
+		Region:      aws.String(c.Region),
+		Credentials: credentials.NewStaticCredentials(c.AccessKeyId, c.SecretAccessKey, ""),
+	})
+}
+
+// ServiceCheck resolves a node of the service with the client selector and opens a connection to it
+func ServiceCheck(c client.Client, service string) Check {
+	return func(ctx context.Context) error {
+		next, err := c.Options().Selector.Select(service)
+		if err != nil {
+			return err
+		}
+
+		node, err := next()
+		if err != nil {
+			return err
+		}
+
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", node.Address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// S3Check checks the bucket is reachable with the credentials
+func S3Check(creds *AwsCredentials, bucket string) (Check, error) {
+	sess, err := creds.session()
+	if err != nil {
+		return nil, err
+	}
+
+	svc := s3.New(sess)
+
+	return func(ctx context.Context) error {
+		_, err := svc.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+		return err
+	}, nil
+}
+
+// CloudWatchCheck checks the CloudWatch Logs API is reachable with the credentials
+func CloudWatchCheck(creds *AwsCredentials) (Check, error) {
+	sess, err := creds.session()
+	if err != nil {
+		return nil, err
+	}
+
+	svc := cloudwatchlogs.New(sess)
+
+	return func(ctx context.Context) error {
+		_, err := svc.DescribeLogGroupsWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{Limit: aws.Int64(1)})
+		return err
+	}, nil
+}
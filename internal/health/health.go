@@ -0,0 +1,200 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOk          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+	StatusFail        = "fail"
+)
+
+// Check returns error if the dependency isn't reachable
+type Check func(ctx context.Context) error
+
+// Config
+type Config struct {
+	Timeout time.Duration `default:"2s"`
+	// Critical names the dependencies which failures make the service unready, the failures of the others
+	// only degrade the report
+	Critical []string
+	// CacheTtl is the time the report is reused for, so the probes don't reach the dependencies on every request
+	CacheTtl time.Duration `default:"5s"`
+}
+
+// CheckResult
+type CheckResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Latency  int64  `json:"latency_ms"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report
+type Report struct {
+	Status    string                  `json:"status"`
+	CheckedAt time.Time               `json:"checked_at"`
+	Checks    map[string]*CheckResult `json:"checks"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// Ready returns false if any critical dependency failed
+func (r *Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the dependency checks concurrently, each check is limited by the timeout
+type Checker struct {
+	timeout  time.Duration
+	cacheTtl time.Duration
+	critical map[string]bool
+	checks   []namedCheck
+	register func(c *Checker) error
+	now      func() time.Time
+
+	mx        sync.Mutex
+	report    *Report
+	expiresAt time.Time
+}
+
+// NewChecker
+func NewChecker(cfg *Config) *Checker {
+	c := &Checker{
+		timeout:  cfg.Timeout,
+		cacheTtl: cfg.CacheTtl,
+		critical: make(map[string]bool, len(cfg.Critical)),
+		now:      time.Now,
+	}
+
+	for _, name := range cfg.Critical {
+		c.critical[strings.ToLower(name)] = true
+	}
+
+	return c
+}
+
+// Add
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+	return c
+}
+
+// Lazy sets the function adding the checks on the run, it's called on the following runs until it succeeds
+func (c *Checker) Lazy(register func(c *Checker) error) *Checker {
+	c.register = register
+	return c
+}
+
+// Run returns the cached report if it isn't expired yet, the concurrent callers wait for the running checks.
+// The checks aren't bound to the context of the caller, as the report is shared with the other callers
+func (c *Checker) Run() *Report {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.report != nil && c.now().Before(c.expiresAt) {
+		return c.report
+	}
+
+	c.report = c.run()
+	c.expiresAt = c.now().Add(c.cacheTtl)
+
+	return c.report
+}
+
+func (c *Checker) run() *Report {
+	report := &Report{
+		Status:    StatusOk,
+		CheckedAt: c.now().UTC(),
+		Checks:    make(map[string]*CheckResult, len(c.checks)),
+	}
+
+	if c.register != nil {
+		added := len(c.checks)
+
+		if err := c.register(c); err != nil {
+			// the checks added by the failed call are dropped
+			c.checks = c.checks[:added]
+			report.Status = StatusUnavailable
+			report.Error = err.Error()
+			return report
+		}
+
+		c.register = nil
+	}
+
+	results := make([]*CheckResult, len(c.checks))
+	wg := sync.WaitGroup{}
+
+	for i, item := range c.checks {
+		wg.Add(1)
+		go func(i int, item namedCheck) {
+			defer wg.Done()
+			results[i] = c.runCheck(item)
+		}(i, item)
+	}
+
+	wg.Wait()
+
+	for i, item := range c.checks {
+		res := results[i]
+		report.Checks[item.name] = res
+
+		if res.Status == StatusOk {
+			continue
+		}
+
+		if res.Critical {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOk {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) runCheck(item namedCheck) *CheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := waitCheck(ctx, item.check)
+	res := &CheckResult{
+		Status:   StatusOk,
+		Critical: c.critical[strings.ToLower(item.name)],
+		Latency:  time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+// waitCheck stops waiting for the check which ignores the context cancellation
+func waitCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
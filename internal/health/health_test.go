@@ -0,0 +1,257 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func okCheck(context.Context) error {
+	return nil
+}
+
+func failedCheck(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func Test_Checker_Run(t *testing.T) {
+	cases := []struct {
+		name     string
+		critical []string
+		checks   map[string]Check
+		status   string
+		ready    bool
+		failed   []string
+	}{
+		{
+			name:   "all ok",
+			checks: map[string]Check{"billing": okCheck, "redis": okCheck},
+			status: StatusOk,
+			ready:  true,
+		},
+		{
+			name:     "critical failed",
+			critical: []string{"billing"},
+			checks:   map[string]Check{"billing": failedCheck, "redis": okCheck},
+			status:   StatusUnavailable,
+			failed:   []string{"billing"},
+		},
+		{
+			name:     "not critical failed",
+			critical: []string{"billing"},
+			checks:   map[string]Check{"billing": okCheck, "redis": failedCheck},
+			status:   StatusDegraded,
+			ready:    true,
+			failed:   []string{"redis"},
+		},
+		{
+			name:     "critical and not critical failed",
+			critical: []string{"billing"},
+			checks:   map[string]Check{"billing": failedCheck, "redis": failedCheck},
+			status:   StatusUnavailable,
+			failed:   []string{"billing", "redis"},
+		},
+		{
+			name:     "critical name case ignored",
+			critical: []string{"BILLING"},
+			checks:   map[string]Check{"billing": failedCheck},
+			status:   StatusUnavailable,
+			failed:   []string{"billing"},
+		},
+		{
+			name:     "timeout",
+			critical: []string{"billing"},
+			checks: map[string]Check{"billing": func(context.Context) error {
+				// the check ignoring the context isn't waited for
+				time.Sleep(time.Second)
+				return nil
+			}},
+			status: StatusUnavailable,
+			failed: []string{"billing"},
+		},
+		{
+			name:   "without checks",
+			status: StatusOk,
+			ready:  true,
+		},
+	}
+
+	for _, c := range cases {
+		checker := NewChecker(&Config{Timeout: 50 * time.Millisecond, Critical: c.critical})
+
+		for name, check := range c.checks {
+			checker.Add(name, check)
+		}
+
+		report := checker.Run()
+		assert.Equal(t, c.status, report.Status, c.name)
+		assert.Equal(t, c.ready, report.Ready(), c.name)
+		require.Len(t, report.Checks, len(c.checks), c.name)
+
+		var failed []string
+
+		for _, name := range []string{"billing", "redis"} {
+			res, ok := report.Checks[name]
+
+			if !ok {
+				continue
+			}
+
+			assert.Equal(t, checker.critical[name], res.Critical, c.name)
+
+			if res.Status == StatusOk {
+				assert.Empty(t, res.Error, c.name)
+				continue
+			}
+
+			assert.Equal(t, StatusFail, res.Status, c.name)
+			assert.NotEmpty(t, res.Error, c.name)
+			failed = append(failed, name)
+		}
+
+		assert.Equal(t, c.failed, failed, c.name)
+	}
+}
+
+func Test_Checker_Run_DetachedContext(t *testing.T) {
+	var deadline time.Time
+
+	checker := NewChecker(&Config{Timeout: time.Minute}).Add("billing", func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	report := checker.Run()
+
+	assert.Equal(t, StatusOk, report.Status)
+	assert.WithinDuration(t, start.Add(time.Minute), deadline, time.Second)
+}
+
+func Test_Checker_Run_Cache(t *testing.T) {
+	cases := []struct {
+		name     string
+		forward  time.Duration
+		expected int
+	}{
+		{name: "cached", forward: 4 * time.Second, expected: 1},
+		{name: "expired", forward: 5 * time.Second, expected: 2},
+	}
+
+	for _, c := range cases {
+		now := time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+		calls := 0
+		checker := NewChecker(&Config{Timeout: time.Second, CacheTtl: 5 * time.Second}).Add("billing", func(context.Context) error {
+			calls++
+			return nil
+		})
+		checker.now = func() time.Time {
+			return now
+		}
+
+		first := checker.Run()
+		now = now.Add(c.forward)
+		second := checker.Run()
+
+		assert.Equal(t, c.expected, calls, c.name)
+		assert.Equal(t, c.expected == 1, first == second, c.name)
+	}
+}
+
+func Test_Checker_Run_Concurrent(t *testing.T) {
+	calls := 0
+	checker := NewChecker(&Config{Timeout: time.Second, CacheTtl: time.Minute}).Add("billing", func(context.Context) error {
+		calls++
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	count := 10
+	reports := make([]*Report, count)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = checker.Run()
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, calls)
+
+	for _, report := range reports {
+		assert.Same(t, reports[0], report)
+	}
+}
+
+func Test_Checker_Run_Lazy(t *testing.T) {
+	cases := []struct {
+		name    string
+		errors  []error
+		status  []string
+		calls   int
+		checks  int
+		message string
+	}{
+		{
+			name:   "registered",
+			errors: []error{nil},
+			status: []string{StatusOk, StatusOk, StatusOk},
+			calls:  1,
+			checks: 1,
+		},
+		{
+			name:    "registered on the retry",
+			errors:  []error{errors.New("micro client failed"), nil},
+			status:  []string{StatusUnavailable, StatusOk, StatusOk},
+			calls:   2,
+			checks:  1,
+			message: "micro client failed",
+		},
+		{
+			name:    "not registered",
+			errors:  []error{errors.New("micro client failed")},
+			status:  []string{StatusUnavailable, StatusUnavailable, StatusUnavailable},
+			calls:   3,
+			message: "micro client failed",
+		},
+	}
+
+	for _, c := range cases {
+		calls := 0
+		checker := NewChecker(&Config{Timeout: time.Second}).Lazy(func(checker *Checker) error {
+			err := c.errors[len(c.errors)-1]
+
+			if calls < len(c.errors) {
+				err = c.errors[calls]
+			}
+
+			calls++
+			// the checks added before the failure are dropped
+			checker.Add("billing", okCheck)
+			return err
+		})
+
+		var reports []*Report
+
+		for range c.status {
+			reports = append(reports, checker.Run())
+		}
+
+		assert.Equal(t, c.calls, calls, c.name)
+		assert.Len(t, checker.checks, c.checks, c.name)
+
+		for i, status := range c.status {
+			assert.Equal(t, status, reports[i].Status, c.name)
+		}
+
+		assert.Equal(t, c.message, reports[0].Error, c.name)
+	}
+}
@@ -0,0 +1,152 @@
+package i18n
+
+// deMessages are the translations of the management api error messages keyed by the code, the codes shared by
+// several messages are keyed by the code and the english message
+var deMessages = map[string]string{
+	"ma000001": "unbekannter Fehler, versuchen Sie es später erneut",
+	"ma000002": "Validierung fehlgeschlagen",
+	"ma000003": "interner Fehler",
+	"ma000004": "Zugriff verweigert",
+	"ma000005": "Kennung darf nicht leer sein",
+	"ma000006": "ungültige Händlerkennung",
+	"ma000007": "ungültige Benachrichtigungskennung",
+	"ma000008": "ungültige Bestellkennung",
+	"ma000009": "ungültige Produktkennung",
+	"ma000010": "ungültige Länderkennung",
+	"ma000011": "ungültige Währungskennung",
+	"ma000012": "Bestellungen nicht gefunden",
+	"ma000013": "Land nicht gefunden",
+	"ma000014": "Währung nicht gefunden",
+	"ma000015": "Benachrichtigung nicht gefunden",
+	"ma000020": "die Vereinbarung kann für ungeprüfte Händlerdaten nicht erstellt werden",
+	"ma000021": "die Händlervereinbarung wurde noch nicht erstellt",
+	"ma000022": "der Header mit der Anfragesignatur darf nicht leer sein",
+	"ma000023": "ungültige Anfrageparameter",
+	"ma000024": "ungültige E-Mail-Adresse",
+	"ma000026": "ungültige Anfragedaten",
+	"ma000027": "Fehler beim Abrufen der Länderliste",
+	"ma000028": "für den angegebenen Schlüssel existiert keine Datei",
+	"ma000029": "im Content-Type fehlt der Parameter multipart boundary",
+	"ma000030": "Hochladen fehlgeschlagen",
+	"ma000031": "ungültige Projektkennung",
+	"ma000032": "ungültige Zahlungsmethodenkennung",
+	"ma000033": "ungültige Zahlungslinkkennung",
+	"ma000034": "Autorisierungsheader nicht gefunden",
+	"ma000035": "Autorisierungstoken nicht gefunden",
+	"ma000036": "Informationen über den autorisierten Benutzer nicht gefunden",
+	"ma000037": "der Parameter status hat einen ungültigen Typ",
+	"ma000038": "Händlervereinbarung nicht gefunden",
+	"ma000039": "die maximale Upload-Größe des Vereinbarungsdokuments wurde überschritten",
+	"ma000040": "das Vereinbarungsdokument muss eine PDF-Datei sein",
+	"ma000041": "der Parameter Vereinbarungstyp hat einen ungültigen Typ",
+	"ma000042": "der Parameter Händlersignatur hat einen ungültigen Typ",
+	"ma000043": "der Parameter PaySuper-Signatur hat einen ungültigen Typ",
+	"ma000044": "der Parameter Vereinbarung per E-Mail gesendet hat einen ungültigen Typ",
+	"ma000045": "der Parameter Sendungsverfolgungslink hat einen ungültigen Typ",
+	"ma000046": "der Parameter name hat einen ungültigen Typ",
+	"ma000047": "der Parameter image hat einen ungültigen Typ",
+	"ma000048": "der Parameter Callback-Währung hat einen ungültigen Typ",
+	"ma000049": "der Parameter Callback-Protokoll hat einen ungültigen Typ",
+	"ma000050": "der Parameter erlaubte URLs für die Bestellerstellung hat einen ungültigen Typ",
+	"ma000051": "der Parameter dynamische Benachrichtigungs-URLs erlauben hat einen ungültigen Typ",
+	"ma000052": "der Parameter dynamische Weiterleitungs-URLs erlauben hat einen ungültigen Typ",
+	"ma000053": "der Parameter Limitwährung hat einen ungültigen Typ",
+	"ma000054": "der Parameter minimaler Zahlungsbetrag hat einen ungültigen Typ",
+	"ma000055": "der Parameter maximaler Zahlungsbetrag hat einen ungültigen Typ",
+	"ma000056": "der Parameter Benachrichtigungs-E-Mails hat einen ungültigen Typ",
+	"ma000057": "der Parameter Produkt-Checkout hat einen ungültigen Typ",
+	"ma000058": "der Parameter geheimer Schlüssel hat einen ungültigen Typ",
+	"ma000059": "der Parameter Signatur erforderlich hat einen ungültigen Typ",
+	"ma000060": "der Parameter Benachrichtigungs-E-Mail senden hat einen ungültigen Typ",
+	"ma000061": "der Parameter URL zur Kontoprüfung hat einen ungültigen Typ",
+	"ma000062": "der Parameter URL zur Zahlungsverarbeitung hat einen ungültigen Typ",
+	"ma000063": "der Parameter Weiterleitungs-URL bei Fehler hat einen ungültigen Typ",
+	"ma000064": "der Parameter Weiterleitungs-URL bei Erfolg hat einen ungültigen Typ",
+	"ma000065": "der Parameter URL für Rückbuchungen hat einen ungültigen Typ",
+	"ma000066": "der Parameter URL für Zahlungsstornierung hat einen ungültigen Typ",
+	"ma000067": "der Parameter URL für betrügerische Zahlungen hat einen ungültigen Typ",
+	"ma000068": "der Parameter URL für Zahlungserstattungen hat einen ungültigen Typ",
+	"ma000069": "Preisgruppe für das Land kann nicht abgerufen werden",
+	"ma000070": "Währungen der Preisgruppen können nicht abgerufen werden",
+	"ma000071": "Währung der Preisgruppe für die Region kann nicht abgerufen werden",
+	"ma000072|unable to get price group recommended prices": "empfohlene Preise der Preisgruppe können nicht abgerufen werden",
+	"ma000072|unable to get price of product":               "Produktpreis kann nicht abgerufen werden",
+	"ma000072|unable to update price of product":            "Produktpreis kann nicht aktualisiert werden",
+	"ma000073": "ungültige Postleitzahl",
+	"ma000074": "ungültige Anzahl der Mitarbeiter",
+	"ma000075": "ungültiger Jahresumsatz",
+	"ma000076": "ungültiger Firmenname",
+	"ma000077": "ungültige Position",
+	"ma000078": "ungültiger Vorname",
+	"ma000079": "ungültiger Nachname",
+	"ma000080": "ungültige Website",
+	"ma000081": "ungültige Tätigkeitsart",
+	"ma000082|review must be text with length lower than or equal 500 characters":                         "die Bewertung muss ein Text mit höchstens 500 Zeichen sein",
+	"ma000082|key product id is invalid":                                                                  "ungültige Kennung des Schlüsselprodukts",
+	"ma000083|review page identifier must be one of next values: primary_onboarding, merchant_onboarding": "die Kennung der Bewertungsseite muss einer der folgenden Werte sein: primary_onboarding, merchant_onboarding",
+	"ma000083|platform id is invalid":                                                                     "ungültige Plattformkennung",
+	"ma000084":                                                                                            "ungültige Marke",
+	"ma000085":                                                                                            "ungültiges Bundesland",
+	"ma000086":                                                                                            "ungültige Stadt",
+	"ma000087":                                                                                            "ungültige Adresse",
+	"ma000088":                                                                                            "die Angaben zur autorisierten Kontaktperson des Unternehmens sind erforderlich",
+	"ma000089":                                                                                            "die Angaben zur technischen Kontaktperson des Unternehmens sind erforderlich",
+	"ma000090":                                                                                            "ungültiger Name",
+	"ma000091":                                                                                            "ungültige Telefonnummer",
+	"ma000092":                                                                                            "ungültiger Bankname",
+	"ma000093":                                                                                            "ungültige Bankadresse",
+	"ma000094":                                                                                            "ungültige Kontonummer",
+	"ma000095":                                                                                            "ungültiger SWIFT-Code",
+	"ma000096":                                                                                            "ungültiges Korrespondenzkonto",
+	"ma000097":                                                                                            "die Datei mit den Schlüsseln wurde nicht angegeben",
+	"ma000098":                                                                                            "die Datei kann nicht gelesen werden",
+	"ma000099":                                                                                            "ungültiger Zeitraum",
+	"ma000100":                                                                                            "Händler nicht gefunden",
+	"ma000101":                                                                                            "Berichtsdatei kann nicht erstellt werden",
+	"ma000102":                                                                                            "Berichtsdatei kann nicht heruntergeladen werden",
+	"ma000103":                                                                                            "das lokalisierte Feld hat einen ungültigen Typ",
+	"ma000104":                                                                                            "das Titelbildfeld hat einen ungültigen Typ",
+	"ma000105":                                                                                            "Einladung kann nicht gesendet werden",
+	"ma000106":                                                                                            "Einladung kann nicht angenommen werden",
+	"ma000107":                                                                                            "Einladungstoken kann nicht geprüft werden",
+	"ma000108":                                                                                            "ungültiger Rollentyp",
+	"ma000109":                                                                                            "Benutzer kann nicht gelöscht werden",
+	"ma000110":                                                                                            "ungültiger Weiterleitungsmodus",
+	"ma000111":                                                                                            "ungültiger Datumsfilter",
+	"pr000111":                                                                                            "ungültiger Typ der Weiterleitungsnutzung",
+	"ma000112":                                                                                            "ungültiger Idempotenzschlüssel",
+	"ma000113":                                                                                            "der Idempotenzschlüssel wurde bereits mit einer anderen Anfrage verwendet",
+	"ma000114":                                                                                            "eine Anfrage mit demselben Idempotenzschlüssel wird noch verarbeitet",
+	"ma000115":                                                                                            "der Händler ist für den Benutzer nicht verfügbar",
+	"ma000116":                                                                                            "der API-Schlüssel ist ungültig, widerrufen oder abgelaufen",
+	"ma000117":                                                                                            "API-Schlüssel nicht gefunden",
+	"ma000118":                                                                                            "ungültige Berechtigungen des API-Schlüssels",
+	"ma000119":                                                                                            "die Route ist für den API-Schlüssel nicht erlaubt",
+	"ma000120":                                                                                            "zu viele Anfragen",
+	"ma000121":                                                                                            "der Dienst hat nicht rechtzeitig geantwortet, versuchen Sie es später erneut",
+	"ma000122":                                                                                            "der Dienst ist vorübergehend nicht verfügbar, versuchen Sie es später erneut",
+	"ma000123":                                                                                            "die Anfrage wurde vom Client abgebrochen",
+	"ma000124":                                                                                            "das angeforderte Objekt wurde nicht gefunden",
+	"ma000125":                                                                                            "der Anfragetext ist zu groß",
+	"ma000126":                                                                                            "der Zeitraum der Bestellprotokolle ist ungültig oder zu lang",
+	"ma000127":                                                                                            "das Seitentoken der Bestellprotokolle ist ungültig",
+	"ma000128":                                                                                            "für die Bestellung in diesem Status gibt es keine erneut zu sendende Benachrichtigung",
+	"ma000129":                                                                                            "zu viele Bestellungen für das erneute Senden der Benachrichtigungen, grenzen Sie den Filter ein",
+	"ma000130":                                                                                            "kein Verkaufstyp zum Testen, übergeben Sie den Betrag mit der Währung, die Produkte oder die Schlüsselprodukte",
+	"ma000131":                                                                                            "der Webhook-Testbericht konnte nicht gespeichert werden",
+	"ma000132":                                                                                            "Callbacks von dieser Adresse sind nicht erlaubt",
+	"ma000133":                                                                                            "die Callback-Signatur fehlt oder ist ungültig",
+	"ma000134":                                                                                            "unbekanntes Zahlungssystem oder Callback-Ereignis",
+	"ma000135":                                                                                            "der Callback-Eingang ist deaktiviert",
+	"ma000136":                                                                                            "Callback nicht gefunden",
+	"ma000137":                                                                                            "der Callback wurde bereits verarbeitet",
+	"ma000138":                                                                                            "der Callback wird gerade verarbeitet, versuchen Sie es später erneut",
+	"ma000139":                                                                                            "der Callback-Eingang ist nicht verfügbar",
+	"ma000140":                                                                                            "gespeichertes Zahlungsmittel nicht gefunden",
+	"ma000141":                                                                                            "Abonnement nicht gefunden",
+	"ma000142":                                                                                            "Berichtsdatei nicht gefunden",
+	"ma000143":                                                                                            "der angeforderte Bereich der Berichtsdatei ist nicht erfüllbar",
+	"ma000144":                                                                                            "Download-Token für Berichtsdateien sind deaktiviert",
+	"ma000145":                                                                                            "das Download-Token der Berichtsdatei ist ungültig oder abgelaufen",
+	"ma000146":                                                                                            "Projekt nicht gefunden",
+}
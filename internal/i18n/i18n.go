@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LocaleEn = "en"
+	LocaleRu = "ru"
+	LocaleDe = "de"
+	LocaleZh = "zh"
+
+	DefaultLocale = LocaleEn
+
+	keySeparator = "|"
+)
+
+// RequiredLocales must have the translations of all error messages
+var RequiredLocales = []string{LocaleRu, LocaleDe, LocaleZh}
+
+var catalogs = map[string]map[string]string{
+	LocaleRu: ruMessages,
+	LocaleDe: deMessages,
+	LocaleZh: zhMessages,
+}
+
+// validationMasks are the translations of the details mask of the failed field validation
+var validationMasks = map[string]string{
+	LocaleRu: "поле '%s' не прошло проверку '%s'",
+	LocaleDe: "Validierung des Feldes '%s' ist bei der Regel '%s' fehlgeschlagen",
+	LocaleZh: "字段 '%s' 未通过 '%s' 校验",
+}
+
+type languageRange struct {
+	locale  string
+	quality float64
+}
+
+// IsSupported
+func IsSupported(locale string) bool {
+	if locale == DefaultLocale {
+		return true
+	}
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// ParseAcceptLanguage returns the supported locale with the highest quality from the Accept-Language header,
+// the locales are matched by the primary subtag, e.g. de-AT is de, the default locale is returned if none is supported
+func ParseAcceptLanguage(header string) string {
+	var ranges []languageRange
+
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ";")
+		tag := strings.ToLower(strings.TrimSpace(parts[0]))
+
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				quality = q
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		locale := strings.SplitN(tag, "-", 2)[0]
+		if IsSupported(locale) {
+			ranges = append(ranges, languageRange{locale: locale, quality: quality})
+		}
+	}
+
+	if len(ranges) == 0 {
+		return DefaultLocale
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	return ranges[0].locale
+}
+
+// Key returns the catalog key of the message, the message is a part of the key only for the codes shared by
+// several messages
+func Key(code, msg string) string {
+	return code + keySeparator + msg
+}
+
+// Message returns the translation of the error message, false is returned if the message has no translation
+// to the locale and the english message must be used
+func Message(locale, code, msg string) (string, bool) {
+	catalog, ok := catalogs[locale]
+	if !ok || code == "" {
+		return msg, false
+	}
+
+	if val, ok := catalog[Key(code, msg)]; ok {
+		return val, true
+	}
+
+	if val, ok := catalog[code]; ok {
+		return val, true
+	}
+
+	return msg, false
+}
+
+// ValidationMask returns the translation of the details mask of the failed field validation
+func ValidationMask(locale string) (string, bool) {
+	mask, ok := validationMasks[locale]
+	return mask, ok
+}
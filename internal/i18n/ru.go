@@ -0,0 +1,152 @@
+package i18n
+
+// ruMessages are the translations of the management api error messages keyed by the code, the codes shared by
+// several messages are keyed by the code and the english message
+var ruMessages = map[string]string{
+	"ma000001": "неизвестная ошибка, повторите запрос позже",
+	"ma000002": "ошибка валидации",
+	"ma000003": "внутренняя ошибка",
+	"ma000004": "доступ запрещён",
+	"ma000005": "идентификатор не может быть пустым",
+	"ma000006": "некорректный идентификатор мерчанта",
+	"ma000007": "некорректный идентификатор уведомления",
+	"ma000008": "некорректный идентификатор заказа",
+	"ma000009": "некорректный идентификатор продукта",
+	"ma000010": "некорректный идентификатор страны",
+	"ma000011": "некорректный идентификатор валюты",
+	"ma000012": "заказы не найдены",
+	"ma000013": "страна не найдена",
+	"ma000014": "валюта не найдена",
+	"ma000015": "уведомление не найдено",
+	"ma000020": "договор не может быть сформирован для непроверенных данных мерчанта",
+	"ma000021": "договор мерчанта ещё не сформирован",
+	"ma000022": "заголовок с подписью запроса не может быть пустым",
+	"ma000023": "некорректные параметры запроса",
+	"ma000024": "некорректный адрес электронной почты",
+	"ma000026": "некорректные данные запроса",
+	"ma000027": "ошибка получения списка стран",
+	"ma000028": "файл для указанного ключа не существует",
+	"ma000029": "в Content-Type отсутствует параметр multipart boundary",
+	"ma000030": "ошибка загрузки",
+	"ma000031": "некорректный идентификатор проекта",
+	"ma000032": "некорректный идентификатор платёжного метода",
+	"ma000033": "некорректный идентификатор платёжной ссылки",
+	"ma000034": "заголовок авторизации не найден",
+	"ma000035": "токен авторизации не найден",
+	"ma000036": "информация об авторизованном пользователе не найдена",
+	"ma000037": "параметр status имеет некорректный тип",
+	"ma000038": "договор мерчанта не найден",
+	"ma000039": "превышен максимальный размер загружаемого документа договора",
+	"ma000040": "документ договора должен быть в формате PDF",
+	"ma000041": "параметр типа договора имеет некорректный тип",
+	"ma000042": "параметр подписи мерчанта имеет некорректный тип",
+	"ma000043": "параметр подписи PaySuper имеет некорректный тип",
+	"ma000044": "параметр отправки договора по email имеет некорректный тип",
+	"ma000045": "параметр ссылки отслеживания почты имеет некорректный тип",
+	"ma000046": "параметр name имеет некорректный тип",
+	"ma000047": "параметр image имеет некорректный тип",
+	"ma000048": "параметр валюты колбэка имеет некорректный тип",
+	"ma000049": "параметр протокола колбэка имеет некорректный тип",
+	"ma000050": "параметр разрешённых URL создания заказа имеет некорректный тип",
+	"ma000051": "параметр разрешения динамических URL уведомлений имеет некорректный тип",
+	"ma000052": "параметр разрешения динамических URL перенаправления имеет некорректный тип",
+	"ma000053": "параметр валюты лимитов имеет некорректный тип",
+	"ma000054": "параметр минимальной суммы платежа имеет некорректный тип",
+	"ma000055": "параметр максимальной суммы платежа имеет некорректный тип",
+	"ma000056": "параметр email для уведомлений имеет некорректный тип",
+	"ma000057": "параметр оформления заказа через продукты имеет некорректный тип",
+	"ma000058": "параметр секретного ключа имеет некорректный тип",
+	"ma000059": "параметр обязательности подписи имеет некорректный тип",
+	"ma000060": "параметр отправки email уведомлений имеет некорректный тип",
+	"ma000061": "параметр URL проверки аккаунта имеет некорректный тип",
+	"ma000062": "параметр URL обработки платежа имеет некорректный тип",
+	"ma000063": "параметр URL перенаправления при ошибке имеет некорректный тип",
+	"ma000064": "параметр URL перенаправления при успехе имеет некорректный тип",
+	"ma000065": "параметр URL чарджбэка платежа имеет некорректный тип",
+	"ma000066": "параметр URL отмены платежа имеет некорректный тип",
+	"ma000067": "параметр URL мошеннического платежа имеет некорректный тип",
+	"ma000068": "параметр URL возврата платежа имеет некорректный тип",
+	"ma000069": "не удалось получить ценовую группу по стране",
+	"ma000070": "не удалось получить валюты ценовых групп",
+	"ma000071": "не удалось получить валюту ценовой группы по региону",
+	"ma000072|unable to get price group recommended prices": "не удалось получить рекомендуемые цены ценовой группы",
+	"ma000072|unable to get price of product":               "не удалось получить цену продукта",
+	"ma000072|unable to update price of product":            "не удалось обновить цену продукта",
+	"ma000073": "некорректный почтовый индекс",
+	"ma000074": "некорректное количество сотрудников",
+	"ma000075": "некорректное значение годового дохода",
+	"ma000076": "некорректное название компании",
+	"ma000077": "некорректная должность",
+	"ma000078": "некорректное имя",
+	"ma000079": "некорректная фамилия",
+	"ma000080": "некорректный веб-сайт",
+	"ma000081": "некорректный вид деятельности",
+	"ma000082|review must be text with length lower than or equal 500 characters":                         "отзыв должен быть текстом длиной не более 500 символов",
+	"ma000082|key product id is invalid":                                                                  "некорректный идентификатор продукта-ключа",
+	"ma000083|review page identifier must be one of next values: primary_onboarding, merchant_onboarding": "идентификатор страницы отзыва должен быть одним из значений: primary_onboarding, merchant_onboarding",
+	"ma000083|platform id is invalid":                                                                     "некорректный идентификатор платформы",
+	"ma000084":                                                                                            "некорректный бренд",
+	"ma000085":                                                                                            "некорректный штат",
+	"ma000086":                                                                                            "некорректный город",
+	"ma000087":                                                                                            "некорректный адрес",
+	"ma000088":                                                                                            "необходима информация об уполномоченном контактном лице компании",
+	"ma000089":                                                                                            "необходима информация о техническом контактном лице компании",
+	"ma000090":                                                                                            "некорректное имя",
+	"ma000091":                                                                                            "некорректный телефон",
+	"ma000092":                                                                                            "некорректное название банка",
+	"ma000093":                                                                                            "некорректный адрес банка",
+	"ma000094":                                                                                            "некорректный номер банковского счёта",
+	"ma000095":                                                                                            "некорректный SWIFT-код банка",
+	"ma000096":                                                                                            "некорректный корреспондентский счёт банка",
+	"ma000097":                                                                                            "файл с ключами не указан",
+	"ma000098":                                                                                            "не удалось прочитать файл",
+	"ma000099":                                                                                            "некорректный период",
+	"ma000100":                                                                                            "мерчант не найден",
+	"ma000101":                                                                                            "не удалось создать файл отчёта",
+	"ma000102":                                                                                            "не удалось скачать файл отчёта",
+	"ma000103":                                                                                            "локализованное поле имеет некорректный тип",
+	"ma000104":                                                                                            "поле обложки имеет некорректный тип",
+	"ma000105":                                                                                            "не удалось отправить приглашение",
+	"ma000106":                                                                                            "не удалось принять приглашение",
+	"ma000107":                                                                                            "не удалось проверить токен приглашения",
+	"ma000108":                                                                                            "некорректный тип роли",
+	"ma000109":                                                                                            "не удалось удалить пользователя",
+	"ma000110":                                                                                            "некорректный режим перенаправления",
+	"ma000111":                                                                                            "некорректный фильтр по дате",
+	"pr000111":                                                                                            "некорректный тип использования перенаправления",
+	"ma000112":                                                                                            "некорректный ключ идемпотентности",
+	"ma000113":                                                                                            "ключ идемпотентности уже использован с другим запросом",
+	"ma000114":                                                                                            "запрос с таким же ключом идемпотентности ещё выполняется",
+	"ma000115":                                                                                            "мерчант недоступен пользователю",
+	"ma000116":                                                                                            "API-ключ недействителен, отозван или истёк",
+	"ma000117":                                                                                            "API-ключ не найден",
+	"ma000118":                                                                                            "некорректные области доступа API-ключа",
+	"ma000119":                                                                                            "маршрут недоступен для API-ключа",
+	"ma000120":                                                                                            "слишком много запросов",
+	"ma000121":                                                                                            "сервис не ответил вовремя, повторите запрос позже",
+	"ma000122":                                                                                            "сервис временно недоступен, повторите запрос позже",
+	"ma000123":                                                                                            "запрос отменён клиентом",
+	"ma000124":                                                                                            "запрошенный объект не найден",
+	"ma000125":                                                                                            "слишком большое тело запроса",
+	"ma000126":                                                                                            "период логов заказа некорректен или слишком длинный",
+	"ma000127":                                                                                            "некорректный токен страницы логов заказа",
+	"ma000128":                                                                                            "для заказа в этом статусе нет уведомления для повторной отправки",
+	"ma000129":                                                                                            "слишком много заказов для повторной отправки уведомлений, уточните фильтр",
+	"ma000130":                                                                                            "нет типа продаж для тестирования, передайте сумму с валютой, продукты или ключевые продукты",
+	"ma000131":                                                                                            "не удалось сохранить отчёт о тестировании вебхука",
+	"ma000132":                                                                                            "обратные вызовы с этого адреса не разрешены",
+	"ma000133":                                                                                            "подпись обратного вызова отсутствует или некорректна",
+	"ma000134":                                                                                            "неизвестная платёжная система или событие обратного вызова",
+	"ma000135":                                                                                            "входящая очередь обратных вызовов отключена",
+	"ma000136":                                                                                            "обратный вызов не найден",
+	"ma000137":                                                                                            "обратный вызов уже обработан",
+	"ma000138":                                                                                            "обратный вызов обрабатывается, повторите попытку позже",
+	"ma000139":                                                                                            "входящая очередь обратных вызовов недоступна",
+	"ma000140":                                                                                            "сохранённое платёжное средство не найдено",
+	"ma000141":                                                                                            "подписка не найдена",
+	"ma000142":                                                                                            "файл отчёта не найден",
+	"ma000143":                                                                                            "запрошенный диапазон файла отчёта недопустим",
+	"ma000144":                                                                                            "токены скачивания файлов отчётов отключены",
+	"ma000145":                                                                                            "токен скачивания файла отчёта недействителен или истёк",
+	"ma000146":                                                                                            "проект не найден",
+}
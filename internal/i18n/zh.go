@@ -0,0 +1,152 @@
+package i18n
+
+// zhMessages are the translations of the management api error messages keyed by the code, the codes shared by
+// several messages are keyed by the code and the english message
+var zhMessages = map[string]string{
+	"ma000001": "未知错误，请稍后重试",
+	"ma000002": "验证失败",
+	"ma000003": "内部错误",
+	"ma000004": "拒绝访问",
+	"ma000005": "标识符不能为空",
+	"ma000006": "商户标识符不正确",
+	"ma000007": "通知标识符不正确",
+	"ma000008": "订单标识符不正确",
+	"ma000009": "产品标识符不正确",
+	"ma000010": "国家标识符不正确",
+	"ma000011": "货币标识符不正确",
+	"ma000012": "未找到订单",
+	"ma000013": "未找到国家",
+	"ma000014": "未找到货币",
+	"ma000015": "未找到通知",
+	"ma000020": "商户数据未经审核，无法生成协议",
+	"ma000021": "商户协议尚未生成",
+	"ma000022": "请求签名头不能为空",
+	"ma000023": "请求参数不正确",
+	"ma000024": "电子邮件地址不正确",
+	"ma000026": "请求数据无效",
+	"ma000027": "获取国家列表出错",
+	"ma000028": "指定键对应的文件不存在",
+	"ma000029": "Content-Type 中缺少 multipart boundary 参数",
+	"ma000030": "上传失败",
+	"ma000031": "项目标识符不正确",
+	"ma000032": "支付方式标识符不正确",
+	"ma000033": "支付链接标识符不正确",
+	"ma000034": "未找到授权头",
+	"ma000035": "未找到授权令牌",
+	"ma000036": "未找到已授权用户的信息",
+	"ma000037": "status 参数类型不正确",
+	"ma000038": "未找到商户协议",
+	"ma000039": "协议文件超过最大上传大小",
+	"ma000040": "协议文件必须为 PDF 格式",
+	"ma000041": "协议类型参数类型不正确",
+	"ma000042": "商户签名参数类型不正确",
+	"ma000043": "PaySuper 签名参数类型不正确",
+	"ma000044": "协议邮件发送参数类型不正确",
+	"ma000045": "邮件跟踪链接参数类型不正确",
+	"ma000046": "name 参数类型不正确",
+	"ma000047": "image 参数类型不正确",
+	"ma000048": "回调货币参数类型不正确",
+	"ma000049": "回调协议参数类型不正确",
+	"ma000050": "允许创建订单的 URL 参数类型不正确",
+	"ma000051": "允许动态通知 URL 参数类型不正确",
+	"ma000052": "允许动态重定向 URL 参数类型不正确",
+	"ma000053": "限额货币参数类型不正确",
+	"ma000054": "最低支付金额参数类型不正确",
+	"ma000055": "最高支付金额参数类型不正确",
+	"ma000056": "通知邮箱参数类型不正确",
+	"ma000057": "产品结账参数类型不正确",
+	"ma000058": "密钥参数类型不正确",
+	"ma000059": "签名必填参数类型不正确",
+	"ma000060": "发送通知邮件参数类型不正确",
+	"ma000061": "账户校验 URL 参数类型不正确",
+	"ma000062": "支付处理 URL 参数类型不正确",
+	"ma000063": "失败重定向 URL 参数类型不正确",
+	"ma000064": "成功重定向 URL 参数类型不正确",
+	"ma000065": "拒付 URL 参数类型不正确",
+	"ma000066": "取消支付 URL 参数类型不正确",
+	"ma000067": "欺诈支付 URL 参数类型不正确",
+	"ma000068": "退款 URL 参数类型不正确",
+	"ma000069": "无法按国家获取价格组",
+	"ma000070": "无法获取价格组货币",
+	"ma000071": "无法按地区获取价格组货币",
+	"ma000072|unable to get price group recommended prices": "无法获取价格组的建议价格",
+	"ma000072|unable to get price of product":               "无法获取产品价格",
+	"ma000072|unable to update price of product":            "无法更新产品价格",
+	"ma000073": "邮政编码不正确",
+	"ma000074": "员工人数不正确",
+	"ma000075": "年收入值不正确",
+	"ma000076": "公司名称不正确",
+	"ma000077": "职位不正确",
+	"ma000078": "名字不正确",
+	"ma000079": "姓氏不正确",
+	"ma000080": "网站不正确",
+	"ma000081": "业务类型不正确",
+	"ma000082|review must be text with length lower than or equal 500 characters":                         "评价必须是不超过 500 个字符的文本",
+	"ma000082|key product id is invalid":                                                                  "密钥产品标识符无效",
+	"ma000083|review page identifier must be one of next values: primary_onboarding, merchant_onboarding": "评价页面标识符必须为以下值之一：primary_onboarding、merchant_onboarding",
+	"ma000083|platform id is invalid":                                                                     "平台标识符无效",
+	"ma000084":                                                                                            "品牌不正确",
+	"ma000085":                                                                                            "州/省不正确",
+	"ma000086":                                                                                            "城市不正确",
+	"ma000087":                                                                                            "地址不正确",
+	"ma000088":                                                                                            "必须填写公司授权联系人信息",
+	"ma000089":                                                                                            "必须填写公司技术联系人信息",
+	"ma000090":                                                                                            "姓名不正确",
+	"ma000091":                                                                                            "电话号码不正确",
+	"ma000092":                                                                                            "银行名称不正确",
+	"ma000093":                                                                                            "银行地址不正确",
+	"ma000094":                                                                                            "银行账号不正确",
+	"ma000095":                                                                                            "银行 SWIFT 代码不正确",
+	"ma000096":                                                                                            "银行代理账户不正确",
+	"ma000097":                                                                                            "未指定密钥文件",
+	"ma000098":                                                                                            "无法读取文件",
+	"ma000099":                                                                                            "期间不正确",
+	"ma000100":                                                                                            "未找到商户",
+	"ma000101":                                                                                            "无法创建报表文件",
+	"ma000102":                                                                                            "无法下载报表文件",
+	"ma000103":                                                                                            "本地化字段类型无效",
+	"ma000104":                                                                                            "封面字段类型无效",
+	"ma000105":                                                                                            "无法发送邀请",
+	"ma000106":                                                                                            "无法接受邀请",
+	"ma000107":                                                                                            "无法校验邀请令牌",
+	"ma000108":                                                                                            "角色类型无效",
+	"ma000109":                                                                                            "无法删除用户",
+	"ma000110":                                                                                            "重定向模式不正确",
+	"ma000111":                                                                                            "日期筛选条件不正确",
+	"pr000111":                                                                                            "重定向使用类型不正确",
+	"ma000112":                                                                                            "幂等键不正确",
+	"ma000113":                                                                                            "幂等键已被其他请求使用",
+	"ma000114":                                                                                            "具有相同幂等键的请求正在处理中",
+	"ma000115":                                                                                            "该用户无法使用此商户",
+	"ma000116":                                                                                            "API 密钥无效、已撤销或已过期",
+	"ma000117":                                                                                            "未找到 API 密钥",
+	"ma000118":                                                                                            "API 密钥权限范围不正确",
+	"ma000119":                                                                                            "该 API 密钥不允许访问此路由",
+	"ma000120":                                                                                            "请求过多",
+	"ma000121":                                                                                            "服务未及时响应，请稍后重试",
+	"ma000122":                                                                                            "服务暂时不可用，请稍后重试",
+	"ma000123":                                                                                            "请求已被客户端取消",
+	"ma000124":                                                                                            "未找到请求的对象",
+	"ma000125":                                                                                            "请求体过大",
+	"ma000126":                                                                                            "订单日志的时间段无效或过长",
+	"ma000127":                                                                                            "订单日志分页令牌无效",
+	"ma000128":                                                                                            "该状态的订单没有可重新发送的通知",
+	"ma000129":                                                                                            "需要重新发送通知的订单过多，请缩小筛选范围",
+	"ma000130":                                                                                            "没有可测试的销售类型，请传入金额和货币、产品或密钥产品",
+	"ma000131":                                                                                            "无法保存 webhook 测试报告",
+	"ma000132":                                                                                            "不允许来自此地址的回调",
+	"ma000133":                                                                                            "回调签名缺失或无效",
+	"ma000134":                                                                                            "未知的支付系统或回调事件",
+	"ma000135":                                                                                            "回调收件箱已禁用",
+	"ma000136":                                                                                            "未找到回调",
+	"ma000137":                                                                                            "回调已处理",
+	"ma000138":                                                                                            "回调正在处理中，请稍后重试",
+	"ma000139":                                                                                            "回调收件箱不可用",
+	"ma000140":                                                                                            "未找到已保存的支付工具",
+	"ma000141":                                                                                            "未找到订阅",
+	"ma000142":                                                                                            "未找到报告文件",
+	"ma000143":                                                                                            "无法满足报告文件的请求范围",
+	"ma000144":                                                                                            "报告文件下载令牌已禁用",
+	"ma000145":                                                                                            "报告文件下载令牌无效或已过期",
+	"ma000146":                                                                                            "未找到项目",
+}
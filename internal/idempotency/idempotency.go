@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"time"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// Record
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// Store keeps the first response for every idempotency key
+type Store interface {
+	// Get returns nil record if the key is unknown
+	Get(ctx context.Context, key string) (*Record, error)
+	// Reserve saves the record only if the key is not present and reports whether it was saved
+	Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error)
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config
+type Config struct {
+	Store   string        `default:"memory"`
+	Ttl     time.Duration `default:"24h"`
+	LockTtl time.Duration `default:"1m"`
+	Routes  []string
+}
+
+// NewStore
+func NewStore(cfg *Config, client *redis.Client) (Store, error) {
+	switch cfg.Store {
+	case "", StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		if client == nil {
+			return nil, fmt.Errorf("idempotency store %q requires redis settings", cfg.Store)
+		}
+		return NewRedisStore(client), nil
+	}
+	return nil, fmt.Errorf("unknown idempotency store %q", cfg.Store)
+}
@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type memoryItem struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// MemoryStore
+type MemoryStore struct {
+	mx        sync.Mutex
+	items     map[string]*memoryItem
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items:     make(map[string]*memoryItem),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Get
+func (s *MemoryStore) Get(_ context.Context, key string) (*Record, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || s.now().After(item.expiresAt) {
+		return nil, nil
+	}
+	record := item.record
+	return &record, nil
+}
+
+// Reserve
+func (s *MemoryStore) Reserve(_ context.Context, key string, record *Record, ttl time.Duration) (bool, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if item, ok := s.items[key]; ok && now.Before(item.expiresAt) {
+		return false, nil
+	}
+	s.items[key] = &memoryItem{record: *record, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Save
+func (s *MemoryStore) Save(_ context.Context, key string, record *Record, ttl time.Duration) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.items[key] = &memoryItem{record: *record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// Delete
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	for key, item := range s.items {
+		if now.After(item.expiresAt) {
+			delete(s.items, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"time"
+)
+
+const redisKeyPrefix = "idempotency:"
+
+// RedisStore
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Get
+func (s *RedisStore) Get(ctx context.Context, key string) (*Record, error) {
+	b, err := s.client.Get(ctx, redisKeyPrefix+key)
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := &Record{}
+	if err = json.Unmarshal(b, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Reserve
+func (s *RedisStore) Reserve(ctx context.Context, key string, record *Record, ttl time.Duration) (bool, error) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	return s.client.SetNX(ctx, redisKeyPrefix+key, b, ttl)
+}
+
+// Save
+func (s *RedisStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, redisKeyPrefix+key, b, ttl)
+}
+
+// Delete
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, redisKeyPrefix+key)
+}
@@ -0,0 +1,264 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+	"time"
+)
+
+// StoreTestSuite runs the same cases against the memory and the redis stores, forward moves the clock of the store
+type StoreTestSuite struct {
+	suite.Suite
+	newStore func() (Store, func(time.Duration), func())
+	store    Store
+	forward  func(time.Duration)
+	close    func()
+}
+
+func Test_MemoryStore(t *testing.T) {
+	suite.Run(t, &StoreTestSuite{
+		newStore: func() (Store, func(time.Duration), func()) {
+			now := time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+			store := NewMemoryStore()
+			store.lastSweep = now
+			store.now = func() time.Time {
+				return now
+			}
+			return store, func(d time.Duration) { now = now.Add(d) }, func() {}
+		},
+	})
+}
+
+func Test_RedisStore(t *testing.T) {
+	suite.Run(t, &StoreTestSuite{
+		newStore: func() (Store, func(time.Duration), func()) {
+			server, err := miniredis.Run()
+			require.NoError(t, err)
+
+			client := redis.New(&redis.Config{Addr: server.Addr(), PoolSize: 10, Timeout: time.Second})
+			closeStore := func() {
+				_ = client.Close()
+				server.Close()
+			}
+
+			return NewRedisStore(client), server.FastForward, closeStore
+		},
+	})
+}
+
+func (suite *StoreTestSuite) SetupTest() {
+	suite.store, suite.forward, suite.close = suite.newStore()
+}
+
+func (suite *StoreTestSuite) TearDownTest() {
+	suite.close()
+}
+
+func (suite *StoreTestSuite) TestStore() {
+	ctx := context.Background()
+	completed := &Record{Fingerprint: "fingerprint", Completed: true, Status: 200, ContentType: "application/json", Body: []byte(`{"id":"1"}`)}
+
+	cases := []struct {
+		name     string
+		run      func(key string) (bool, error)
+		reserved bool
+		expected *Record
+	}{
+		{
+			name: "unknown key",
+			run: func(_ string) (bool, error) {
+				return false, nil
+			},
+		},
+		{
+			name:     "reserved",
+			reserved: true,
+			run: func(key string) (bool, error) {
+				return suite.store.Reserve(ctx, key, &Record{Fingerprint: "fingerprint"}, time.Minute)
+			},
+			expected: &Record{Fingerprint: "fingerprint"},
+		},
+		{
+			name: "reserved key isn't reserved again",
+			run: func(key string) (bool, error) {
+				if _, err := suite.store.Reserve(ctx, key, &Record{Fingerprint: "first"}, time.Minute); err != nil {
+					return false, err
+				}
+				return suite.store.Reserve(ctx, key, &Record{Fingerprint: "second"}, time.Minute)
+			},
+			expected: &Record{Fingerprint: "first"},
+		},
+		{
+			name: "saved over the reservation",
+			run: func(key string) (bool, error) {
+				if _, err := suite.store.Reserve(ctx, key, &Record{Fingerprint: "fingerprint"}, time.Minute); err != nil {
+					return false, err
+				}
+				return false, suite.store.Save(ctx, key, completed, time.Hour)
+			},
+			expected: completed,
+		},
+		{
+			name: "completed key isn't reserved",
+			run: func(key string) (bool, error) {
+				if err := suite.store.Save(ctx, key, completed, time.Hour); err != nil {
+					return false, err
+				}
+				return suite.store.Reserve(ctx, key, &Record{Fingerprint: "other"}, time.Minute)
+			},
+			expected: completed,
+		},
+		{
+			name:     "reserved after the reservation expired",
+			reserved: true,
+			run: func(key string) (bool, error) {
+				if _, err := suite.store.Reserve(ctx, key, &Record{Fingerprint: "first"}, time.Minute); err != nil {
+					return false, err
+				}
+				suite.forward(time.Minute + time.Second)
+				return suite.store.Reserve(ctx, key, &Record{Fingerprint: "second"}, time.Minute)
+			},
+			expected: &Record{Fingerprint: "second"},
+		},
+		{
+			name: "saved record expired",
+			run: func(key string) (bool, error) {
+				if err := suite.store.Save(ctx, key, completed, time.Hour); err != nil {
+					return false, err
+				}
+				suite.forward(time.Hour + time.Second)
+				return false, nil
+			},
+		},
+		{
+			name: "saved record not expired yet",
+			run: func(key string) (bool, error) {
+				if err := suite.store.Save(ctx, key, completed, time.Hour); err != nil {
+					return false, err
+				}
+				suite.forward(time.Hour - time.Second)
+				return false, nil
+			},
+			expected: completed,
+		},
+		{
+			name:     "reserved after the delete",
+			reserved: true,
+			run: func(key string) (bool, error) {
+				if _, err := suite.store.Reserve(ctx, key, &Record{Fingerprint: "first"}, time.Minute); err != nil {
+					return false, err
+				}
+				if err := suite.store.Delete(ctx, key); err != nil {
+					return false, err
+				}
+				return suite.store.Reserve(ctx, key, &Record{Fingerprint: "second"}, time.Minute)
+			},
+			expected: &Record{Fingerprint: "second"},
+		},
+		{
+			name: "deleted",
+			run: func(key string) (bool, error) {
+				if err := suite.store.Save(ctx, key, completed, time.Hour); err != nil {
+					return false, err
+				}
+				return false, suite.store.Delete(ctx, key)
+			},
+		},
+		{
+			name: "unknown key deleted",
+			run: func(key string) (bool, error) {
+				return false, suite.store.Delete(ctx, key)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		key := "key:" + c.name
+		ok, err := c.run(key)
+		require.NoError(suite.T(), err, c.name)
+		assert.Equal(suite.T(), c.reserved, ok, c.name)
+
+		record, err := suite.store.Get(ctx, key)
+		require.NoError(suite.T(), err, c.name)
+		assert.Equal(suite.T(), c.expected, record, c.name)
+	}
+}
+
+func (suite *StoreTestSuite) TestGet_Copy() {
+	ctx := context.Background()
+	require.NoError(suite.T(), suite.store.Save(ctx, "key", &Record{Fingerprint: "fingerprint"}, time.Hour))
+
+	record, err := suite.store.Get(ctx, "key")
+	require.NoError(suite.T(), err)
+	record.Fingerprint = "changed"
+
+	record, err = suite.store.Get(ctx, "key")
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "fingerprint", record.Fingerprint)
+}
+
+func (suite *StoreTestSuite) TestReserve_Concurrent() {
+	ctx := context.Background()
+	count := 20
+	results := make([]bool, count)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := suite.store.Reserve(ctx, "key", &Record{Fingerprint: "fingerprint"}, time.Minute)
+			assert.NoError(suite.T(), err)
+			results[i] = ok
+		}(i)
+	}
+
+	wg.Wait()
+
+	reserved := 0
+
+	for _, ok := range results {
+		if ok {
+			reserved++
+		}
+	}
+
+	assert.Equal(suite.T(), 1, reserved)
+}
+
+func Test_NewStore(t *testing.T) {
+	client := redis.New(&redis.Config{Addr: "127.0.0.1:0"})
+	defer client.Close()
+
+	cases := []struct {
+		name     string
+		cfg      *Config
+		client   *redis.Client
+		expected Store
+		err      bool
+	}{
+		{name: "default", cfg: &Config{}, expected: &MemoryStore{}},
+		{name: "memory", cfg: &Config{Store: StoreMemory}, expected: &MemoryStore{}},
+		{name: "redis", cfg: &Config{Store: StoreRedis}, client: client, expected: &RedisStore{}},
+		{name: "redis without settings", cfg: &Config{Store: StoreRedis}, err: true},
+		{name: "unknown", cfg: &Config{Store: "mongo"}, client: client, err: true},
+	}
+
+	for _, c := range cases {
+		store, err := NewStore(c.cfg, c.client)
+
+		if c.err {
+			assert.Error(t, err, c.name)
+			continue
+		}
+
+		require.NoError(t, err, c.name)
+		assert.IsType(t, c.expected, store, c.name)
+	}
+}
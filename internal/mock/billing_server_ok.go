@@ -1044,7 +1044,13 @@ func (s *BillingServerOkMock) GetOrderPrivate(
 	in *billingpb.GetOrderRequest,
 	opts ...client.CallOption,
 ) (*billingpb.GetOrderPrivateResponse, error) {
-	return &billingpb.GetOrderPrivateResponse{}, nil
+	return &billingpb.GetOrderPrivateResponse{
+		Status: billingpb.ResponseStatusOk,
+		Item: &billingpb.OrderViewPrivate{
+			Uuid:    in.OrderId,
+			Project: &billingpb.ProjectOrder{Id: "5e96c1f4ff5d7c9a3c8d1b94", MerchantId: "5e96c1f4ff5d7c9a3c8d1b93"},
+		},
+	}, nil
 }
 
 func (s *BillingServerOkMock) FindAllOrdersPublic(
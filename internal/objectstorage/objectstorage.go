@@ -0,0 +1,172 @@
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	errCodeInvalidRange = "InvalidRange"
+	errCodeNotFound     = "NotFound"
+)
+
+var (
+	ErrNotFound     = errors.New("object not found")
+	ErrInvalidRange = errors.New("requested range not satisfiable")
+)
+
+// Storage reads the objects of the bucket without saving them locally
+type Storage interface {
+	// Open returns the object or its part if the range is set, the range is the value of the Range header
+	Open(ctx context.Context, key, byteRange string) (*Object, error)
+	// Stat returns the object without the body
+	Stat(ctx context.Context, key string) (*Object, error)
+	// PresignedUrl returns the url to get the object without the credentials until the ttl expires, the content
+	// type and the disposition are the headers of the response to the url
+	PresignedUrl(key, contentType, disposition string, ttl time.Duration) (string, error)
+}
+
+// Object is the body of the object or of its part, the body must be closed
+type Object struct {
+	Body io.ReadCloser
+	// Metadata is the user metadata saved with the object, the keys are in the lower case
+	Metadata map[string]string
+	// ContentLength is the length of the body
+	ContentLength int64
+	// ContentRange is set if the part of the object is returned
+	ContentRange string
+	ETag         string
+	LastModified time.Time
+}
+
+// S3Client gets the objects of the amazon s3 bucket
+type S3Client interface {
+	GetObjectWithContext(ctx aws.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error)
+	GetObjectRequest(input *s3.GetObjectInput) (*request.Request, *s3.GetObjectOutput)
+	HeadObjectWithContext(ctx aws.Context, input *s3.HeadObjectInput, opts ...request.Option) (*s3.HeadObjectOutput, error)
+}
+
+type s3Storage struct {
+	client S3Client
+	bucket string
+}
+
+// NewS3 creates the storage of the amazon s3 bucket
+func NewS3(client S3Client, bucket string) Storage {
+	return &s3Storage{client: client, bucket: bucket}
+}
+
+// NewS3Client
+func NewS3Client(accessKeyId, secretAccessKey, region string) (S3Client, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: credentials.NewStaticCredentials(accessKeyId, secretAccessKey, ""),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s3.New(sess), nil
+}
+
+// Open
+func (s *s3Storage) Open(ctx context.Context, key, byteRange string) (*Object, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+
+	if byteRange != "" {
+		input.Range = aws.String(byteRange)
+	}
+
+	rsp, err := s.client.GetObjectWithContext(ctx, input)
+
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	return &Object{
+		Body:          rsp.Body,
+		Metadata:      metadata(rsp.Metadata),
+		ContentLength: aws.Int64Value(rsp.ContentLength),
+		ContentRange:  aws.StringValue(rsp.ContentRange),
+		ETag:          aws.StringValue(rsp.ETag),
+		LastModified:  aws.TimeValue(rsp.LastModified),
+	}, nil
+}
+
+// Stat
+func (s *s3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	rsp, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		return nil, s3Error(err)
+	}
+
+	return &Object{
+		Metadata:      metadata(rsp.Metadata),
+		ContentLength: aws.Int64Value(rsp.ContentLength),
+		ETag:          aws.StringValue(rsp.ETag),
+		LastModified:  aws.TimeValue(rsp.LastModified),
+	}, nil
+}
+
+// PresignedUrl
+func (s *s3Storage) PresignedUrl(key, contentType, disposition string, ttl time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(key),
+		ResponseContentType:        aws.String(contentType),
+		ResponseContentDisposition: aws.String(disposition),
+	})
+
+	return req.Presign(ttl)
+}
+
+func s3Error(err error) error {
+	if e, ok := err.(awserr.RequestFailure); ok {
+		switch e.StatusCode() {
+		case http.StatusNotFound:
+			return ErrNotFound
+		case http.StatusRequestedRangeNotSatisfiable:
+			return ErrInvalidRange
+		}
+	}
+
+	if e, ok := err.(awserr.Error); ok {
+		switch e.Code() {
+		case s3.ErrCodeNoSuchKey, errCodeNotFound:
+			return ErrNotFound
+		case errCodeInvalidRange:
+			return ErrInvalidRange
+		}
+	}
+
+	return err
+}
+
+// metadata of the object, the sdk returns the keys in the canonical header format
+func metadata(in map[string]*string) map[string]string {
+	out := make(map[string]string, len(in))
+
+	for key, value := range in {
+		out[strings.ToLower(key)] = aws.StringValue(value)
+	}
+
+	return out
+}
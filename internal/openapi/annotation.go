@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+const (
+	tagSummary = "@summary"
+	tagDesc    = "@desc"
+	tagId      = "@id"
+	tagTag     = "@tag"
+	tagAccept  = "@accept"
+	tagProduce = "@produce"
+	tagParam   = "@param"
+	tagBody    = "@body"
+	tagSuccess = "@success"
+	tagFailure = "@failure"
+	tagRouter  = "@router"
+	tagTitle   = "@title"
+	tagVer     = "@ver"
+	tagServer  = "@server"
+
+	kindObject = "object"
+	kindString = "string"
+)
+
+// Route
+type Route struct {
+	Method string
+	Path   string
+	Pos    token.Position
+}
+
+var pathParamRegex = regexp.MustCompile(`{[^}/]+}`)
+
+// String
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// Match compares the paths ignoring the names of the parameters, the parameter of the registered route may be
+// documented as several parameters of the same path segment, e.g. {file} is documented as {file_id}.{file_type}
+func (r Route) Match(annotated Route) bool {
+	if r.Method != annotated.Method {
+		return false
+	}
+	parts := pathParamRegex.Split(r.Path, -1)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	pattern := "^" + strings.Join(parts, "[^/]+") + "$"
+	ok, _ := regexp.MatchString(pattern, pathParamRegex.ReplaceAllString(annotated.Path, "p"))
+	return ok
+}
+
+// Param
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// Result is the response annotated with @success or @failure
+type Result struct {
+	Code        string
+	Kind        string
+	Type        string
+	Description string
+}
+
+// Annotation is the comment block describing the operation, the block is finished by one or more @router lines
+type Annotation struct {
+	Summary     string
+	Description string
+	Id          string
+	Tags        []string
+	Accept      []string
+	Produce     []string
+	Params      []Param
+	Body        string
+	Results     []Result
+	Routes      []Route
+	Pos         token.Position
+}
+
+// parseAnnotations returns the annotations of all comment blocks of the file containing @router
+func parseAnnotations(fset *token.FileSet, file *ast.File) ([]*Annotation, error) {
+	var list []*Annotation
+
+	for _, group := range file.Comments {
+		pos := fset.Position(group.Pos())
+		a := &Annotation{Pos: pos}
+
+		for _, line := range commentLines(group) {
+			if err := a.parseLine(line); err != nil {
+				return nil, fmt.Errorf("%s: %v", pos, err)
+			}
+		}
+
+		if len(a.Routes) == 0 {
+			continue
+		}
+
+		for i := range a.Routes {
+			a.Routes[i].Pos = pos
+		}
+
+		list = append(list, a)
+	}
+
+	return list, nil
+}
+
+func (a *Annotation) parseLine(line string) error {
+	tag, rest := splitTag(line)
+
+	switch tag {
+	case tagSummary:
+		a.Summary = rest
+	case tagDesc:
+		a.Description = rest
+	case tagId:
+		a.Id = rest
+	case tagTag:
+		a.Tags = append(a.Tags, rest)
+	case tagAccept:
+		a.Accept = splitList(rest)
+	case tagProduce:
+		a.Produce = splitList(rest)
+	case tagBody:
+		a.Body = rest
+	case tagParam:
+		fields := strings.Fields(rest)
+		if len(fields) < 4 {
+			return fmt.Errorf("%s must be `name in {type} required description`: %q", tag, rest)
+		}
+		a.Params = append(a.Params, Param{
+			Name:        fields[0],
+			In:          fields[1],
+			Type:        strings.Trim(fields[2], "{}"),
+			Required:    fields[3] == "true",
+			Description: strings.Join(fields[4:], " "),
+		})
+	case tagSuccess, tagFailure:
+		fields := strings.Fields(rest)
+		if len(fields) < 2 {
+			return fmt.Errorf("%s must be `code {kind} [type] description`: %q", tag, rest)
+		}
+		res := Result{Code: fields[0], Kind: strings.Trim(fields[1], "{}")}
+		fields = fields[2:]
+		if res.Kind == kindObject && len(fields) > 0 {
+			res.Type = fields[0]
+			fields = fields[1:]
+		}
+		res.Description = strings.Join(fields, " ")
+		a.Results = append(a.Results, res)
+	case tagRouter:
+		fields := strings.Fields(rest)
+		if len(fields) != 2 {
+			return fmt.Errorf("%s must be `path [method]`: %q", tag, rest)
+		}
+		a.Routes = append(a.Routes, Route{
+			Method: strings.ToUpper(strings.Trim(fields[1], "[]")),
+			Path:   fields[0],
+		})
+	}
+
+	return nil
+}
+
+// Info is the document information annotated in the main package
+type Info struct {
+	Title       string
+	Description string
+	Version     string
+	Servers     [][2]string
+}
+
+func parseInfo(file *ast.File) *Info {
+	info := &Info{}
+
+	for _, group := range file.Comments {
+		for _, line := range commentLines(group) {
+			tag, rest := splitTag(line)
+
+			switch tag {
+			case tagTitle:
+				info.Title = rest
+			case tagDesc:
+				info.Description = rest
+			case tagVer:
+				info.Version = rest
+			case tagServer:
+				parts := strings.SplitN(rest, " ", 2)
+				if len(parts) == 1 {
+					parts = append(parts, "")
+				}
+				info.Servers = append(info.Servers, [2]string{parts[0], parts[1]})
+			}
+		}
+	}
+
+	return info
+}
+
+func commentLines(group *ast.CommentGroup) []string {
+	var lines []string
+
+	for _, c := range group.List {
+		text := strings.TrimPrefix(c.Text, "//")
+		text = strings.TrimPrefix(strings.TrimSuffix(text, "*/"), "/*")
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	return lines
+}
+
+func splitTag(line string) (string, string) {
+	if !strings.HasPrefix(line, "@") {
+		return "", line
+	}
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
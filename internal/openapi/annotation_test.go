@@ -0,0 +1,283 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func Test_Annotation_parseLine(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		expected Annotation
+	}{
+		{
+			name:     "summary",
+			line:     "@summary Get the list of projects",
+			expected: Annotation{Summary: "Get the list of projects"},
+		},
+		{
+			name:     "description",
+			line:     "@desc  Get the list of projects for the authorized merchant ",
+			expected: Annotation{Description: "Get the list of projects for the authorized merchant"},
+		},
+		{
+			name:     "id",
+			line:     "@id projectsPathListProjects",
+			expected: Annotation{Id: "projectsPathListProjects"},
+		},
+		{
+			name:     "tag",
+			line:     "@tag Project",
+			expected: Annotation{Tags: []string{"Project"}},
+		},
+		{
+			name:     "accept list",
+			line:     "@accept application/json, ,text/csv",
+			expected: Annotation{Accept: []string{"application/json", "text/csv"}},
+		},
+		{
+			name:     "produce list",
+			line:     "@produce application/pdf, text/csv",
+			expected: Annotation{Produce: []string{"application/pdf", "text/csv"}},
+		},
+		{
+			name:     "body",
+			line:     "@body billingpb.Project",
+			expected: Annotation{Body: "billingpb.Project"},
+		},
+		{
+			name: "query param",
+			line: "@param limit query {integer} false The number of projects returned in one page.",
+			expected: Annotation{Params: []Param{
+				{Name: "limit", In: "query", Type: "integer", Description: "The number of projects returned in one page."},
+			}},
+		},
+		{
+			name: "required array param without description",
+			line: "@param statuses query {[]string} true",
+			expected: Annotation{Params: []Param{
+				{Name: "statuses", In: "query", Type: "[]string", Required: true},
+			}},
+		},
+		{
+			name: "success object",
+			line: "@success 200 {object} billingpb.ListProjectsResponse Returns the list of projects",
+			expected: Annotation{Results: []Result{
+				{Code: "200", Kind: "object", Type: "billingpb.ListProjectsResponse", Description: "Returns the list of projects"},
+			}},
+		},
+		{
+			name: "success string",
+			line: "@success 200 {string} Returns the report file",
+			expected: Annotation{Results: []Result{
+				{Code: "200", Kind: "string", Description: "Returns the report file"},
+			}},
+		},
+		{
+			name: "failure object without description",
+			line: "@failure 404 {object} billingpb.ResponseErrorMessage",
+			expected: Annotation{Results: []Result{
+				{Code: "404", Kind: "object", Type: "billingpb.ResponseErrorMessage"},
+			}},
+		},
+		{
+			name: "router",
+			line: "@router /admin/api/v1/projects [get]",
+			expected: Annotation{Routes: []Route{
+				{Method: "GET", Path: "/admin/api/v1/projects"},
+			}},
+		},
+		{
+			name:     "plain text",
+			line:     "getProjectsList returns the projects",
+			expected: Annotation{},
+		},
+		{
+			name:     "unknown tag",
+			line:     "@inject_tag: json:\"id\"",
+			expected: Annotation{},
+		},
+	}
+
+	for _, c := range cases {
+		a := Annotation{}
+		require.NoError(t, a.parseLine(c.line), c.name)
+		assert.Equal(t, c.expected, a, c.name)
+	}
+}
+
+func Test_Annotation_parseLine_Error(t *testing.T) {
+	cases := map[string]string{
+		"param without required":  "@param limit query {integer}",
+		"success without kind":    "@success 200",
+		"failure empty":           "@failure",
+		"router without method":   "@router /admin/api/v1/projects",
+		"router with extra field": "@router /admin/api/v1/projects [get] [post]",
+	}
+
+	for name, line := range cases {
+		a := Annotation{}
+		assert.Error(t, a.parseLine(line), name)
+	}
+}
+
+func Test_Annotation_parseLine_Accumulates(t *testing.T) {
+	a := Annotation{}
+
+	for _, line := range []string{
+		"@tag Report file",
+		"@tag Export",
+		"@param file_id path {string} true The unique identifier for the report file.",
+		"@param file_type path {string} true The supported file format.",
+		"@success 200 {string} Returns the report file",
+		"@failure 404 {object} billingpb.ResponseErrorMessage The report file not found",
+	} {
+		require.NoError(t, a.parseLine(line))
+	}
+
+	assert.Equal(t, []string{"Report file", "Export"}, a.Tags)
+	require.Len(t, a.Params, 2)
+	assert.Equal(t, "file_type", a.Params[1].Name)
+	require.Len(t, a.Results, 2)
+	assert.Equal(t, "404", a.Results[1].Code)
+}
+
+const annotatedSource = `package handlers
+
+// @summary Export the report file
+// @id reportFileDownloadPathDownload
+// @tag Report file
+// @success 200 {string} Returns the report file
+// @param file_id path {string} true The unique identifier for the report file.
+// @router /auth/api/v1/report_file/download/{file_id}.{file_type} [get]
+// @router /admin/api/v1/report_file/download/{file_id}.{file_type} [get]
+func download() {}
+
+// upload isn't annotated, the comment block without @router is skipped
+// @summary Upload the file
+func upload() {}
+
+/*
+@summary Get the project
+@router /admin/api/v1/projects/{project_id} [get]
+*/
+func getProject() {}
+`
+
+func Test_parseAnnotations(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "report_file.go", annotatedSource, parser.ParseComments)
+	require.NoError(t, err)
+
+	list, err := parseAnnotations(fset, file)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	a := list[0]
+	assert.Equal(t, "Export the report file", a.Summary)
+	assert.Equal(t, "reportFileDownloadPathDownload", a.Id)
+	assert.Equal(t, []string{"Report file"}, a.Tags)
+	require.Len(t, a.Routes, 2)
+	assert.Equal(t, "GET /auth/api/v1/report_file/download/{file_id}.{file_type}", a.Routes[0].String())
+	assert.Equal(t, "GET /admin/api/v1/report_file/download/{file_id}.{file_type}", a.Routes[1].String())
+	// the routes of the block share the position of the block
+	assert.Equal(t, 3, a.Routes[0].Pos.Line)
+	assert.Equal(t, a.Routes[0].Pos, a.Routes[1].Pos)
+	assert.Equal(t, a.Pos, a.Routes[0].Pos)
+
+	a = list[1]
+	assert.Equal(t, "Get the project", a.Summary)
+	require.Len(t, a.Routes, 1)
+	assert.Equal(t, "GET /admin/api/v1/projects/{project_id}", a.Routes[0].String())
+}
+
+func Test_parseAnnotations_Error(t *testing.T) {
+	src := "package handlers\n\n// @summary Get the project\n// @param project_id path\n// @router /admin/api/v1/projects/{project_id} [get]\nfunc getProject() {}\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "project.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	_, err = parseAnnotations(fset, file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project.go:3:1")
+}
+
+func Test_parseInfo(t *testing.T) {
+	src := `// @title PaySuper Management API
+// @desc The management API of the PaySuper merchants
+// @ver 1.0.0
+// @server https://api.pay.super.com Production
+// @server http://localhost:3001
+package main
+`
+
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	info := parseInfo(file)
+	assert.Equal(t, "PaySuper Management API", info.Title)
+	assert.Equal(t, "The management API of the PaySuper merchants", info.Description)
+	assert.Equal(t, "1.0.0", info.Version)
+	assert.Equal(t, [][2]string{{"https://api.pay.super.com", "Production"}, {"http://localhost:3001", ""}}, info.Servers)
+}
+
+func Test_Route_Match(t *testing.T) {
+	cases := []struct {
+		name       string
+		registered Route
+		annotated  Route
+		match      bool
+	}{
+		{
+			name:       "same path",
+			registered: Route{Method: "GET", Path: "/admin/api/v1/projects"},
+			annotated:  Route{Method: "GET", Path: "/admin/api/v1/projects"},
+			match:      true,
+		},
+		{
+			name:       "renamed parameter",
+			registered: Route{Method: "GET", Path: "/admin/api/v1/projects/{id}"},
+			annotated:  Route{Method: "GET", Path: "/admin/api/v1/projects/{project_id}"},
+			match:      true,
+		},
+		{
+			name:       "parameter split in one segment",
+			registered: Route{Method: "GET", Path: "/admin/api/v1/report_file/download/{file}"},
+			annotated:  Route{Method: "GET", Path: "/admin/api/v1/report_file/download/{file_id}.{file_type}"},
+			match:      true,
+		},
+		{
+			name:       "other method",
+			registered: Route{Method: "GET", Path: "/admin/api/v1/projects"},
+			annotated:  Route{Method: "POST", Path: "/admin/api/v1/projects"},
+			match:      false,
+		},
+		{
+			name:       "parameter doesn't span segments",
+			registered: Route{Method: "GET", Path: "/admin/api/v1/projects/{id}"},
+			annotated:  Route{Method: "GET", Path: "/admin/api/v1/projects/{project_id}/products"},
+			match:      false,
+		},
+		{
+			name:       "other group",
+			registered: Route{Method: "GET", Path: "/auth/api/v1/projects"},
+			annotated:  Route{Method: "GET", Path: "/admin/api/v1/projects"},
+			match:      false,
+		},
+		{
+			name:       "dot in the path is literal",
+			registered: Route{Method: "GET", Path: "/api/v1/file.json"},
+			annotated:  Route{Method: "GET", Path: "/api/v1/fileXjson"},
+			match:      false,
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.match, c.registered.Match(c.annotated), c.name)
+	}
+}
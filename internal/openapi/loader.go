@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type decl struct {
+	pkg     *pkg
+	imports map[string]string
+	expr    ast.Expr
+	doc     *ast.CommentGroup
+}
+
+type pkg struct {
+	path   string
+	dir    string
+	files  []*ast.File
+	types  map[string]*decl
+	consts map[string]*decl
+}
+
+// loader parses the packages on demand, the packages are located by go/build in the context of srcDir
+type loader struct {
+	fset   *token.FileSet
+	srcDir string
+	pkgs   map[string]*pkg
+}
+
+func newLoader(srcDir string) *loader {
+	return &loader{
+		fset:   token.NewFileSet(),
+		srcDir: srcDir,
+		pkgs:   make(map[string]*pkg),
+	}
+}
+
+// loadDir
+func (l *loader) loadDir(dir string) (*pkg, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	return l.parse(bp.ImportPath, bp.Dir, bp.GoFiles)
+}
+
+// load
+func (l *loader) load(path string) (*pkg, error) {
+	if p, ok := l.pkgs[path]; ok {
+		if p == nil {
+			return nil, fmt.Errorf("package %s can't be loaded", path)
+		}
+		return p, nil
+	}
+
+	bp, err := build.Import(path, l.srcDir, 0)
+	if err != nil {
+		l.pkgs[path] = nil
+		return nil, err
+	}
+
+	return l.parse(path, bp.Dir, bp.GoFiles)
+}
+
+func (l *loader) parse(path, dir string, names []string) (*pkg, error) {
+	p := &pkg{
+		path:   path,
+		dir:    dir,
+		types:  make(map[string]*decl),
+		consts: make(map[string]*decl),
+	}
+
+	for _, name := range names {
+		file, err := parser.ParseFile(l.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		imports := fileImports(file)
+		p.files = append(p.files, file)
+
+		for _, d := range file.Decls {
+			gen, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					doc := s.Doc
+					if doc == nil {
+						doc = gen.Doc
+					}
+					p.types[s.Name.Name] = &decl{pkg: p, imports: imports, expr: s.Type, doc: doc}
+				case *ast.ValueSpec:
+					if gen.Tok != token.CONST {
+						continue
+					}
+					for i, name := range s.Names {
+						if i < len(s.Values) {
+							p.consts[name.Name] = &decl{pkg: p, imports: imports, expr: s.Values[i]}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	l.pkgs[path] = p
+	return p, nil
+}
+
+// stringConst evaluates the string constant expression, the literals, constants and their concatenations are supported
+func (l *loader) stringConst(p *pkg, imports map[string]string, expr ast.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			return strconv.Unquote(e.Value)
+		}
+	case *ast.ParenExpr:
+		return l.stringConst(p, imports, e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			break
+		}
+		x, err := l.stringConst(p, imports, e.X)
+		if err != nil {
+			return "", err
+		}
+		y, err := l.stringConst(p, imports, e.Y)
+		if err != nil {
+			return "", err
+		}
+		return x + y, nil
+	case *ast.Ident:
+		if d, ok := p.consts[e.Name]; ok {
+			return l.stringConst(d.pkg, d.imports, d.expr)
+		}
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		path, ok := imports[x.Name]
+		if !ok {
+			break
+		}
+		ip, err := l.load(path)
+		if err != nil {
+			return "", err
+		}
+		if d, ok := ip.consts[e.Sel.Name]; ok {
+			return l.stringConst(d.pkg, d.imports, d.expr)
+		}
+	}
+
+	return "", fmt.Errorf("%s: unsupported string constant expression", l.fset.Position(expr.Pos()))
+}
+
+// fileImports maps the package names used in the file to the import paths, the package name
+// is assumed to be equal to the last element of the path if the import isn't named
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+
+		imports[name] = path
+	}
+
+	return imports
+}
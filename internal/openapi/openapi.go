@@ -0,0 +1,357 @@
+package openapi
+
+import (
+	"fmt"
+	"go/parser"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	Version = "3.0.2"
+
+	commonPackage = "github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+
+	mimeJson      = "application/json"
+	mimeTextPlain = "text/plain"
+)
+
+// Document
+type Document struct {
+	OpenApi    string                           `yaml:"openapi"`
+	Info       DocumentInfo                     `yaml:"info"`
+	Servers    []Server                         `yaml:"servers,omitempty"`
+	Paths      map[string]map[string]*Operation `yaml:"paths"`
+	Components Components                       `yaml:"components"`
+}
+
+// DocumentInfo
+type DocumentInfo struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Server
+type Server struct {
+	Url         string `yaml:"url"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Components
+type Components struct {
+	Schemas map[string]*Schema `yaml:"schemas"`
+}
+
+// Operation
+type Operation struct {
+	Summary     string               `yaml:"summary,omitempty"`
+	OperationId string               `yaml:"operationId,omitempty"`
+	Description string               `yaml:"description,omitempty"`
+	Tags        []string             `yaml:"tags,omitempty"`
+	Parameters  []*Parameter         `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+// Parameter
+type Parameter struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description,omitempty"`
+	In          string  `yaml:"in"`
+	Required    bool    `yaml:"required"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+// RequestBody
+type RequestBody struct {
+	Content map[string]*MediaType `yaml:"content"`
+}
+
+// Response
+type Response struct {
+	Description string                `yaml:"description"`
+	Content     map[string]*MediaType `yaml:"content,omitempty"`
+}
+
+// MediaType
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+// Report lists the problems found during the generation
+type Report struct {
+	// Missing are the routes registered in the Route methods without the annotation
+	Missing []Route
+	// Unregistered are the annotated routes which aren't registered by the handlers
+	Unregistered []Route
+	Errors       []string
+}
+
+// Failed
+func (r *Report) Failed() bool {
+	return len(r.Missing) > 0 || len(r.Errors) > 0
+}
+
+// String
+func (r *Report) String() string {
+	b := &strings.Builder{}
+
+	if len(r.Missing) > 0 {
+		b.WriteString("routes without annotation:\n")
+		for _, route := range r.Missing {
+			_, _ = fmt.Fprintf(b, "  %s (%s)\n", route, route.Pos)
+		}
+	}
+
+	if len(r.Unregistered) > 0 {
+		b.WriteString("annotated routes which aren't registered:\n")
+		for _, route := range r.Unregistered {
+			_, _ = fmt.Fprintf(b, "  %s (%s)\n", route, route.Pos)
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		b.WriteString("errors:\n")
+		for _, e := range r.Errors {
+			_, _ = fmt.Fprintf(b, "  %s\n", e)
+		}
+	}
+
+	return b.String()
+}
+
+// Generator builds the OpenAPI 3 document from the annotations of the handlers package
+type Generator struct {
+	loader        *loader
+	schemas       *schemas
+	groupPrefixes map[string]string
+	report        *Report
+	errors        map[string]bool
+}
+
+// NewGenerator
+func NewGenerator() *Generator {
+	return &Generator{report: &Report{}, errors: make(map[string]bool)}
+}
+
+// Generate parses the handlers package in handlersDir and the document info annotated in mainFile
+func (g *Generator) Generate(handlersDir, mainFile string) (*Document, *Report, error) {
+	dir, err := filepath.Abs(handlersDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.loader = newLoader(dir)
+	g.schemas = newSchemas(g.loader)
+
+	main, err := parser.ParseFile(g.loader.fset, mainFile, nil, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handlers, err := g.loader.loadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = g.initGroupPrefixes(); err != nil {
+		return nil, nil, err
+	}
+
+	info := parseInfo(main)
+	doc := &Document{
+		OpenApi: Version,
+		Info: DocumentInfo{
+			Title:       info.Title,
+			Version:     info.Version,
+			Description: info.Description,
+		},
+		Paths: make(map[string]map[string]*Operation),
+	}
+
+	for _, server := range info.Servers {
+		doc.Servers = append(doc.Servers, Server{Url: server[0], Description: server[1]})
+	}
+
+	annotated := make(map[string]Route)
+	ids := make(map[string]Route)
+
+	for _, file := range handlers.files {
+		annotations, err := parseAnnotations(g.loader.fset, file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		imports := fileImports(file)
+
+		for _, a := range annotations {
+			for _, route := range a.Routes {
+				if prev, ok := annotated[route.String()]; ok {
+					g.errorf("%s: route %s is already annotated at %s", route.Pos, route, prev.Pos)
+					continue
+				}
+				annotated[route.String()] = route
+
+				if a.Id != "" {
+					if prev, ok := ids[a.Id]; ok && prev.Pos != route.Pos {
+						g.errorf("%s: operation id %s is already used at %s", route.Pos, a.Id, prev.Pos)
+					}
+					ids[a.Id] = route
+				}
+
+				if doc.Paths[route.Path] == nil {
+					doc.Paths[route.Path] = make(map[string]*Operation)
+				}
+				doc.Paths[route.Path][strings.ToLower(route.Method)] = g.operation(handlers, imports, a)
+			}
+		}
+	}
+
+	routes, err := g.parseRoutes(handlers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registered := make(map[string]bool, len(annotated))
+
+	for _, route := range routes {
+		found := false
+		for key, a := range annotated {
+			if route.Match(a) {
+				registered[key] = true
+				found = true
+			}
+		}
+		if !found {
+			g.report.Missing = append(g.report.Missing, route)
+		}
+	}
+
+	for key, route := range annotated {
+		if !registered[key] {
+			g.report.Unregistered = append(g.report.Unregistered, route)
+		}
+	}
+
+	sortRoutes(g.report.Missing)
+	sortRoutes(g.report.Unregistered)
+
+	doc.Components.Schemas = g.schemas.components
+	return doc, g.report, nil
+}
+
+func (g *Generator) initGroupPrefixes() error {
+	common, err := g.loader.load(commonPackage)
+	if err != nil {
+		return err
+	}
+
+	g.groupPrefixes = make(map[string]string, len(groupPrefixes))
+
+	for field, name := range groupPrefixes {
+		d, ok := common.consts[name]
+		if !ok {
+			return fmt.Errorf("constant %s.%s not found", commonPackage, name)
+		}
+		if g.groupPrefixes[field], err = g.loader.stringConst(common, d.imports, d.expr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Generator) operation(p *pkg, imports map[string]string, a *Annotation) *Operation {
+	op := &Operation{
+		Summary:     a.Summary,
+		OperationId: a.Id,
+		Description: a.Description,
+		Tags:        a.Tags,
+		Responses:   make(map[string]*Response, len(a.Results)),
+	}
+
+	for _, param := range a.Params {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        param.Name,
+			Description: param.Description,
+			In:          param.In,
+			Required:    param.Required || param.In == "path",
+			Schema:      paramSchema(param.Type),
+		})
+	}
+
+	if a.Body != "" {
+		op.RequestBody = &RequestBody{Content: content(a.Accept, mimeJson, g.schema(p, imports, a, a.Body))}
+	}
+
+	for _, res := range a.Results {
+		response := &Response{Description: res.Description}
+
+		switch {
+		case res.Kind == kindObject && res.Type != "":
+			response.Content = content(a.Produce, mimeJson, g.schema(p, imports, a, res.Type))
+		case isJsonOnly(a.Produce):
+			response.Content = content(nil, mimeTextPlain, &Schema{Type: "string"})
+		default:
+			response.Content = content(a.Produce, mimeTextPlain, &Schema{Type: "string", Format: "binary"})
+		}
+
+		op.Responses[res.Code] = response
+	}
+
+	return op
+}
+
+// schema reports the unresolved type and describes it as a free-form object
+func (g *Generator) schema(p *pkg, imports map[string]string, a *Annotation, name string) *Schema {
+	schema, err := g.schemas.annotated(p, imports, name)
+	if err != nil {
+		g.errorf("%s: %v", name, err)
+		return &Schema{Type: "object"}
+	}
+	return schema
+}
+
+func (g *Generator) errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if !g.errors[msg] {
+		g.errors[msg] = true
+		g.report.Errors = append(g.report.Errors, msg)
+	}
+}
+
+func content(types []string, fallback string, schema *Schema) map[string]*MediaType {
+	if len(types) == 0 {
+		types = []string{fallback}
+	}
+
+	c := make(map[string]*MediaType, len(types))
+	for _, t := range types {
+		c[t] = &MediaType{Schema: schema}
+	}
+
+	return c
+}
+
+func isJsonOnly(types []string) bool {
+	return len(types) == 0 || (len(types) == 1 && types[0] == mimeJson)
+}
+
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+}
+
+// paramSchema returns the schema of the parameter type, the types are written in the OpenAPI notation, e.g. {[]integer}
+func paramSchema(t string) *Schema {
+	if strings.HasPrefix(t, "[]") {
+		return &Schema{Type: "array", Items: paramSchema(t[2:])}
+	}
+	return &Schema{Type: t}
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"go/ast"
+	"regexp"
+)
+
+const routeMethodName = "Route"
+
+// groupPrefixes maps the fields of common.Groups to the constants of the group paths
+var groupPrefixes = map[string]string{
+	"AuthProject": "AuthProjectGroupPath",
+	"AuthUser":    "AuthUserGroupPath",
+	"SystemUser":  "SystemUserGroupPath",
+	"WebHooks":    "WebHookGroupPath",
+	"Common":      "NoAuthGroupPath",
+}
+
+var (
+	httpMethods = map[string]bool{
+		"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true,
+	}
+	echoParamRegex = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+)
+
+// parseRoutes returns the routes registered by the Route methods of the handlers,
+// e.g. groups.AuthUser.GET(productsPath, h.getProductsList)
+func (g *Generator) parseRoutes(p *pkg) ([]Route, error) {
+	var routes []Route
+
+	for _, file := range p.files {
+		imports := fileImports(file)
+
+		for _, d := range file.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != routeMethodName || fn.Body == nil {
+				continue
+			}
+
+			var err error
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if err != nil {
+					return false
+				}
+
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) == 0 {
+					return true
+				}
+
+				method, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || !httpMethods[method.Sel.Name] {
+					return true
+				}
+
+				group, ok := method.X.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+
+				prefix, ok := g.groupPrefixes[group.Sel.Name]
+				if !ok {
+					return true
+				}
+
+				var path string
+				if path, err = g.loader.stringConst(p, imports, call.Args[0]); err != nil {
+					return false
+				}
+
+				routes = append(routes, Route{
+					Method: method.Sel.Name,
+					Path:   echoParamRegex.ReplaceAllString(prefix+path, "{$1}"),
+					Pos:    g.loader.fset.Position(call.Pos()),
+				})
+				return true
+			})
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return routes, nil
+}
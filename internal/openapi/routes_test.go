@@ -0,0 +1,181 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const routesSource = `package handlers
+
+const (
+	projectsPath   = "/projects"
+	projectsIdPath = projectsPath + "/:project_id"
+)
+
+type ProjectRoute struct{}
+
+func (h *ProjectRoute) Route(groups *common.Groups) {
+	groups.AuthUser.GET(projectsPath, h.list)
+	groups.AuthUser.POST(projectsPath, h.create)
+	groups.SystemUser.GET(projectsIdPath, h.get)
+	groups.Unknown.GET(projectsPath, h.list)
+	groups.AuthUser.Use(h.middleware)
+}
+
+// the other methods don't register the routes
+func (h *ProjectRoute) routes(groups *common.Groups) {
+	groups.AuthUser.DELETE(projectsIdPath, h.delete)
+}
+
+// @summary Get the list of projects
+// @id projectsPathList
+// @success 200 {object} ProjectsResponse Returns the list of projects
+// @failure 400 {string} Invalid request data
+// @router /admin/api/v1/projects [get]
+// @router /system/api/v1/projects [get]
+func (h *ProjectRoute) list() {}
+
+// ProjectsResponse
+type ProjectsResponse struct {
+	// The number of projects.
+	Count int32 ` + "`json:\"count\" validate:\"required\"`" + `
+	Items []*Project ` + "`json:\"items\"`" + `
+	internal string
+}
+
+// Project is the merchant's project
+type Project struct {
+	Id   string ` + "`json:\"id\"`" + `
+	Name map[string]string ` + "`json:\"name,omitempty\"`" + `
+	Skip string ` + "`json:\"-\"`" + `
+}
+`
+
+// newTestGenerator parses the source as the handlers package, the group prefixes are set without the common package
+func newTestGenerator(t *testing.T, src string) (*Generator, *pkg) {
+	dir, err := ioutil.TempDir("", "openapi")
+	require.NoError(t, err)
+	// the files are parsed right away, the directory isn't needed after it
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "project.go"), []byte(src), 0644))
+
+	g := NewGenerator()
+	g.loader = newLoader(dir)
+	g.schemas = newSchemas(g.loader)
+	g.groupPrefixes = map[string]string{
+		"AuthUser":   "/admin/api/v1",
+		"SystemUser": "/system/api/v1",
+	}
+
+	p, err := g.loader.parse("example.com/handlers", dir, []string{"project.go"})
+	require.NoError(t, err)
+
+	return g, p
+}
+
+func Test_Generator_parseRoutes(t *testing.T) {
+	g, p := newTestGenerator(t, routesSource)
+
+	routes, err := g.parseRoutes(p)
+	require.NoError(t, err)
+	require.Len(t, routes, 3)
+
+	cases := []struct {
+		method string
+		path   string
+	}{
+		{method: "GET", path: "/admin/api/v1/projects"},
+		{method: "POST", path: "/admin/api/v1/projects"},
+		{method: "GET", path: "/system/api/v1/projects/{project_id}"},
+	}
+
+	for i, c := range cases {
+		assert.Equal(t, c.method, routes[i].Method)
+		assert.Equal(t, c.path, routes[i].Path)
+		assert.NotZero(t, routes[i].Pos.Line)
+	}
+}
+
+func Test_Generator_parseRoutes_Error_PathNotConstant(t *testing.T) {
+	src := `package handlers
+
+func (h *ProjectRoute) Route(groups *common.Groups) {
+	groups.AuthUser.GET(path(), h.list)
+}
+`
+	g, p := newTestGenerator(t, src)
+
+	_, err := g.parseRoutes(p)
+	assert.Error(t, err)
+}
+
+func Test_Generator_operation(t *testing.T) {
+	g, p := newTestGenerator(t, routesSource)
+
+	annotations, err := parseAnnotations(g.loader.fset, p.files[0])
+	require.NoError(t, err)
+	require.Len(t, annotations, 1)
+
+	a := annotations[0]
+	a.Params = append(a.Params, Param{Name: "project_id", In: "path", Type: "string"})
+	a.Params = append(a.Params, Param{Name: "statuses", In: "query", Type: "[]integer"})
+
+	op := g.operation(p, fileImports(p.files[0]), a)
+
+	assert.Equal(t, "projectsPathList", op.OperationId)
+	require.Len(t, op.Parameters, 2)
+	// the path parameters are always required
+	assert.True(t, op.Parameters[0].Required)
+	assert.False(t, op.Parameters[1].Required)
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "integer"}}, op.Parameters[1].Schema)
+
+	require.Contains(t, op.Responses, "200")
+	assert.Equal(t, &Schema{Ref: componentsSchemasRef + "ProjectsResponse"}, op.Responses["200"].Content[mimeJson].Schema)
+	require.Contains(t, op.Responses, "400")
+	assert.Equal(t, &Schema{Type: "string"}, op.Responses["400"].Content[mimeTextPlain].Schema)
+	assert.Empty(t, g.report.Errors)
+
+	response := g.schemas.components["ProjectsResponse"]
+	require.NotNil(t, response)
+	assert.Equal(t, []string{"count"}, response.Required)
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32", Description: "The number of projects."}, response.Properties["count"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: componentsSchemasRef + "Project"}}, response.Properties["items"])
+	assert.NotContains(t, response.Properties, "internal")
+
+	project := g.schemas.components["Project"]
+	require.NotNil(t, project)
+	assert.Equal(t, "Project is the merchant's project", project.Description)
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, project.Properties["name"])
+	assert.NotContains(t, project.Properties, "Skip")
+	assert.Len(t, project.Properties, 2)
+}
+
+func Test_Generator_operation_UnknownType(t *testing.T) {
+	g, p := newTestGenerator(t, routesSource)
+
+	a := &Annotation{Results: []Result{{Code: "200", Kind: kindObject, Type: "billing.Unknown"}}}
+	op := g.operation(p, fileImports(p.files[0]), a)
+
+	// the unresolved type is reported and described as the free-form object
+	assert.Equal(t, &Schema{Type: "object"}, op.Responses["200"].Content[mimeJson].Schema)
+	require.Len(t, g.report.Errors, 1)
+	assert.Contains(t, g.report.Errors[0], "billing.Unknown")
+}
+
+func Test_paramSchema(t *testing.T) {
+	cases := map[string]*Schema{
+		"string":      {Type: "string"},
+		"integer":     {Type: "integer"},
+		"[]string":    {Type: "array", Items: &Schema{Type: "string"}},
+		"[][]integer": {Type: "array", Items: &Schema{Type: "array", Items: &Schema{Type: "integer"}}},
+	}
+
+	for typ, expected := range cases {
+		assert.Equal(t, expected, paramSchema(typ), typ)
+	}
+}
@@ -0,0 +1,306 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strings"
+)
+
+const componentsSchemasRef = "#/components/schemas/"
+
+// Schema
+type Schema struct {
+	Ref                  string             `yaml:"$ref,omitempty"`
+	Type                 string             `yaml:"type,omitempty"`
+	Format               string             `yaml:"format,omitempty"`
+	Description          string             `yaml:"description,omitempty"`
+	Items                *Schema            `yaml:"items,omitempty"`
+	Properties           map[string]*Schema `yaml:"properties,omitempty"`
+	AdditionalProperties *Schema            `yaml:"additionalProperties,omitempty"`
+	Required             []string           `yaml:"required,omitempty"`
+}
+
+// knownTypes are the types which are serialized to JSON in the specific way
+var knownTypes = map[string]*Schema{
+	"time.Time": {Type: "string", Format: "date-time"},
+	"github.com/golang/protobuf/ptypes/timestamp.Timestamp": {Type: "string", Format: "date-time"},
+	"github.com/golang/protobuf/ptypes/any.Any":             {Type: "object"},
+	"github.com/golang/protobuf/ptypes/struct.Struct":       {Type: "object"},
+}
+
+var basicTypes = map[string]*Schema{
+	"string":  {Type: "string"},
+	"bool":    {Type: "boolean"},
+	"int":     {Type: "integer"},
+	"int8":    {Type: "integer"},
+	"int16":   {Type: "integer"},
+	"int32":   {Type: "integer", Format: "int32"},
+	"int64":   {Type: "integer", Format: "int64"},
+	"uint":    {Type: "integer"},
+	"uint8":   {Type: "integer"},
+	"uint16":  {Type: "integer"},
+	"uint32":  {Type: "integer", Format: "int32"},
+	"uint64":  {Type: "integer", Format: "int64"},
+	"float32": {Type: "number", Format: "float"},
+	"float64": {Type: "number", Format: "double"},
+	"byte":    {Type: "integer"},
+	"rune":    {Type: "integer"},
+}
+
+// schemas derives the component schemas from the struct declarations and their json tags
+type schemas struct {
+	loader     *loader
+	components map[string]*Schema
+	names      map[string]string
+}
+
+func newSchemas(l *loader) *schemas {
+	return &schemas{
+		loader:     l,
+		components: make(map[string]*Schema),
+		names:      make(map[string]string),
+	}
+}
+
+// annotated resolves the type written in the annotation, e.g. billingpb.Product or []billingpb.ProductPrice
+func (s *schemas) annotated(p *pkg, imports map[string]string, name string) (*Schema, error) {
+	if strings.HasPrefix(name, "[]") {
+		items, err := s.annotated(p, imports, name[2:])
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	}
+
+	if basic, ok := basicTypes[name]; ok {
+		return copySchema(basic), nil
+	}
+
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		path, ok := imports[name[:i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown package of type %s", name)
+		}
+		return s.named(path, name[i+1:])
+	}
+
+	return s.named(p.path, name)
+}
+
+func (s *schemas) named(path, name string) (*Schema, error) {
+	if known, ok := knownTypes[path+"."+name]; ok {
+		return copySchema(known), nil
+	}
+
+	key := path + "." + name
+	if component, ok := s.names[key]; ok {
+		return &Schema{Ref: componentsSchemasRef + component}, nil
+	}
+
+	p, err := s.loader.load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", key)
+	}
+
+	st, ok := d.expr.(*ast.StructType)
+	if !ok {
+		return s.expr(d.pkg, d.imports, d.expr)
+	}
+
+	component := name
+	if _, ok := s.components[component]; ok {
+		component = path[strings.LastIndex(path, "/")+1:] + name
+	}
+
+	// registered before the fields to resolve the recursive types
+	schema := &Schema{Type: "object"}
+	if doc := docText(d.doc); doc != name {
+		schema.Description = doc
+	}
+	s.names[key] = component
+	s.components[component] = schema
+
+	if err = s.fields(d.pkg, d.imports, st, schema); err != nil {
+		return nil, err
+	}
+
+	return &Schema{Ref: componentsSchemasRef + component}, nil
+}
+
+func (s *schemas) fields(p *pkg, imports map[string]string, st *ast.StructType, schema *Schema) error {
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+
+		jsonName := strings.Split(tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			if err := s.embedded(p, imports, field.Type, schema); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			prop, err := s.expr(p, imports, field.Type)
+			if err != nil {
+				return err
+			}
+
+			if prop.Ref == "" {
+				doc := field.Doc
+				if doc == nil {
+					doc = field.Comment
+				}
+				prop.Description = docText(doc)
+			}
+
+			name := jsonName
+			if name == "" {
+				name = ident.Name
+			}
+
+			if schema.Properties == nil {
+				schema.Properties = make(map[string]*Schema)
+			}
+			schema.Properties[name] = prop
+
+			if isRequired(tag) {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// embedded inlines the fields of the embedded struct as encoding/json does
+func (s *schemas) embedded(p *pkg, imports map[string]string, expr ast.Expr, schema *Schema) error {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	path, name := p.path, ""
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		path, name = imports[x.Name], e.Sel.Name
+	default:
+		return nil
+	}
+
+	ep, err := s.loader.load(path)
+	if err != nil {
+		return err
+	}
+
+	d, ok := ep.types[name]
+	if !ok {
+		return nil
+	}
+
+	if st, ok := d.expr.(*ast.StructType); ok {
+		return s.fields(d.pkg, d.imports, st, schema)
+	}
+
+	return nil
+}
+
+func (s *schemas) expr(p *pkg, imports map[string]string, expr ast.Expr) (*Schema, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if basic, ok := basicTypes[e.Name]; ok {
+			return copySchema(basic), nil
+		}
+		return s.named(p.path, e.Name)
+	case *ast.StarExpr:
+		return s.expr(p, imports, e.X)
+	case *ast.ArrayType:
+		if ident, ok := e.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := s.expr(p, imports, e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := s.expr(p, imports, e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		path, ok := imports[x.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown package %s", x.Name)
+		}
+		return s.named(path, e.Sel.Name)
+	case *ast.StructType:
+		schema := &Schema{Type: "object"}
+		if err := s.fields(p, imports, e, schema); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	}
+
+	// interfaces (protobuf oneof) and the other types are described as free-form objects
+	return &Schema{Type: "object"}, nil
+}
+
+func isRequired(tag reflect.StructTag) bool {
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// docText skips the annotation lines like `@inject_tag:` of the generated protobuf types
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, " ")
+}
+
+func copySchema(s *Schema) *Schema {
+	c := *s
+	return &c
+}
@@ -0,0 +1,56 @@
+package orderlog
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"strings"
+)
+
+// CloudWatchClient filters the events of the amazon cloudwatch log groups
+type CloudWatchClient interface {
+	FilterLogEventsWithContext(ctx aws.Context, input *cloudwatchlogs.FilterLogEventsInput,
+		opts ...request.Option) (*cloudwatchlogs.FilterLogEventsOutput, error)
+}
+
+type cloudWatch struct {
+	client CloudWatchClient
+}
+
+// NewCloudWatch creates the source of the amazon cloudwatch log groups, the stream of the query is the log group
+func NewCloudWatch(client CloudWatchClient) Source {
+	return &cloudWatch{client: client}
+}
+
+// Filter
+func (s *cloudWatch) Filter(ctx context.Context, query *Query) (*Page, error) {
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		Limit:         aws.Int64(query.Limit),
+		LogGroupName:  aws.String(query.Stream),
+		StartTime:     aws.Int64(aws.TimeUnixMilli(query.Start)),
+		EndTime:       aws.Int64(aws.TimeUnixMilli(query.End) - 1),
+		FilterPattern: aws.String(strings.Join(query.Terms, " ")),
+	}
+
+	if query.Token != "" {
+		input.NextToken = aws.String(query.Token)
+	}
+
+	rsp, err := s.client.FilterLogEventsWithContext(ctx, input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{NextToken: aws.StringValue(rsp.NextToken)}
+
+	for _, event := range rsp.Events {
+		page.Events = append(page.Events, &Event{
+			Timestamp: aws.MillisecondsTimeValue(event.Timestamp),
+			Message:   aws.StringValue(event.Message),
+		})
+	}
+
+	return page, nil
+}
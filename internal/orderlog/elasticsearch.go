@@ -0,0 +1,209 @@
+package orderlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	esErrorBodyMaxSize = 512
+)
+
+// ElasticsearchConfig of the elasticsearch or opensearch compatible search api
+type ElasticsearchConfig struct {
+	Url      string `envconfig:"ORDER_LOGS_ELASTICSEARCH_URL"`
+	Username string `envconfig:"ORDER_LOGS_ELASTICSEARCH_USERNAME"`
+	Password string `envconfig:"ORDER_LOGS_ELASTICSEARCH_PASSWORD"`
+	// TimestampField is the date field of the log records
+	TimestampField string `envconfig:"ORDER_LOGS_ELASTICSEARCH_TIMESTAMP_FIELD" default:"@timestamp"`
+	// Fields are searched for the terms of the query, the default fields of the index are searched if it's empty
+	Fields  []string      `envconfig:"ORDER_LOGS_ELASTICSEARCH_FIELDS"`
+	Timeout time.Duration `envconfig:"ORDER_LOGS_ELASTICSEARCH_TIMEOUT" default:"10s"`
+}
+
+type elasticsearch struct {
+	cfg    *ElasticsearchConfig
+	client *http.Client
+}
+
+type esSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source json.RawMessage `json:"_source"`
+			Sort   []interface{}   `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// NewElasticsearch creates the source of the elasticsearch indices, the stream of the query is the index,
+// the pages are read by the sort values of the last event of the previous page
+func NewElasticsearch(cfg *ElasticsearchConfig, client *http.Client) (Source, error) {
+	if _, err := url.ParseRequestURI(cfg.Url); err != nil {
+		return nil, fmt.Errorf("invalid elasticsearch url %q: %v", cfg.Url, err)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return &elasticsearch{cfg: cfg, client: client}, nil
+}
+
+// Filter
+func (s *elasticsearch) Filter(ctx context.Context, query *Query) (*Page, error) {
+	body, err := s.searchBody(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := strings.TrimRight(s.cfg.Url, "/") + "/" + url.PathEscape(query.Stream) + "/_search"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.cfg.Username != "" {
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+
+	rsp, err := s.client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, esErrorBodyMaxSize))
+		return nil, fmt.Errorf("elasticsearch search failed with status %d: %s", rsp.StatusCode, msg)
+	}
+
+	result := new(esSearchResponse)
+	decoder := json.NewDecoder(rsp.Body)
+	decoder.UseNumber()
+
+	if err := decoder.Decode(result); err != nil {
+		return nil, err
+	}
+
+	page := &Page{}
+
+	for _, hit := range result.Hits.Hits {
+		if len(hit.Sort) == 0 {
+			continue
+		}
+
+		ms, err := strconv.ParseInt(fmt.Sprint(hit.Sort[0]), 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid elasticsearch timestamp %v: %v", hit.Sort[0], err)
+		}
+
+		page.Events = append(page.Events, &Event{
+			Timestamp: time.Unix(0, ms*int64(time.Millisecond)).UTC(),
+			Message:   string(hit.Source),
+		})
+	}
+
+	hits := result.Hits.Hits
+
+	if int64(len(hits)) == query.Limit && len(hits) > 0 {
+		last, err := json.Marshal(hits[len(hits)-1].Sort)
+
+		if err != nil {
+			return nil, err
+		}
+
+		page.NextToken = base64.RawURLEncoding.EncodeToString(last)
+	}
+
+	return page, nil
+}
+
+func (s *elasticsearch) searchBody(query *Query) ([]byte, error) {
+	terms := make([]string, len(query.Terms))
+
+	for i, term := range query.Terms {
+		terms[i] = strconv.Quote(term)
+	}
+
+	match := map[string]interface{}{
+		"query":            strings.Join(terms, " "),
+		"default_operator": "and",
+	}
+
+	if len(s.cfg.Fields) > 0 {
+		match["fields"] = s.cfg.Fields
+	}
+
+	search := map[string]interface{}{
+		"size": query.Limit,
+		// _doc breaks the ties of the events logged at the same millisecond
+		"sort": []interface{}{
+			map[string]interface{}{s.cfg.TimestampField: map[string]string{"order": "asc"}},
+			"_doc",
+		},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{
+						"range": map[string]interface{}{
+							s.cfg.TimestampField: map[string]interface{}{
+								"gte":    query.Start.UnixNano() / int64(time.Millisecond),
+								"lt":     query.End.UnixNano() / int64(time.Millisecond),
+								"format": "epoch_millis",
+							},
+						},
+					},
+					map[string]interface{}{"simple_query_string": match},
+				},
+			},
+		},
+	}
+
+	if query.Token != "" {
+		searchAfter, err := decodeSearchAfter(query.Token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		search["search_after"] = searchAfter
+	}
+
+	return json.Marshal(search)
+}
+
+func decodeSearchAfter(token string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return values, nil
+}
@@ -0,0 +1,191 @@
+package orderlog
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	esStart = time.Date(2020, 4, 14, 0, 0, 0, 0, time.UTC)
+	esEnd   = time.Date(2020, 4, 15, 0, 0, 0, 0, time.UTC)
+)
+
+func newElasticsearchServer(t *testing.T, status int, response string, requests *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/billing-server%2A/_search", r.URL.EscapedPath())
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		request := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(body, &request))
+
+		if requests != nil {
+			*requests = append(*requests, request)
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+}
+
+func newElasticsearch(t *testing.T, url string, fields []string) Source {
+	source, err := NewElasticsearch(&ElasticsearchConfig{
+		Url:            url + "/",
+		Username:       "user",
+		Password:       "secret",
+		TimestampField: "@timestamp",
+		Fields:         fields,
+		Timeout:        time.Second,
+	}, nil)
+	require.NoError(t, err)
+
+	return source
+}
+
+func Test_NewElasticsearch_Error(t *testing.T) {
+	_, err := NewElasticsearch(&ElasticsearchConfig{}, nil)
+	assert.Error(t, err)
+}
+
+func Test_Elasticsearch_Filter(t *testing.T) {
+	var requests []map[string]interface{}
+	server := newElasticsearchServer(t, http.StatusOK, `{"hits":{"hits":[
+		{"_source":{"msg":"first","order_id":"5e95b18d"},"sort":[1586868647054,10]},
+		{"_source":{"msg":"without sort"}},
+		{"_source":{"msg":"second","order_id":"5e95b18d"},"sort":[1586868647054,12]}
+	]}}`, &requests)
+	defer server.Close()
+
+	source := newElasticsearch(t, server.URL, []string{"message"})
+	query := &Query{Stream: "billing-server*", Terms: []string{"5e95b18d", `"quoted"`}, Start: esStart, End: esEnd, Limit: 3}
+	page, err := source.Filter(context.Background(), query)
+	require.NoError(t, err)
+
+	require.Len(t, page.Events, 2)
+	assert.Equal(t, time.Date(2020, 4, 14, 12, 50, 47, 54000000, time.UTC), page.Events[0].Timestamp)
+	assert.JSONEq(t, `{"msg":"first","order_id":"5e95b18d"}`, page.Events[0].Message)
+	assert.JSONEq(t, `{"msg":"second","order_id":"5e95b18d"}`, page.Events[1].Message)
+
+	// the next token is the sort values of the last hit
+	token, err := base64.RawURLEncoding.DecodeString(page.NextToken)
+	require.NoError(t, err)
+	assert.Equal(t, `[1586868647054,12]`, string(token))
+
+	expected := `{
+		"size": 3,
+		"sort": [{"@timestamp": {"order": "asc"}}, "_doc"],
+		"query": {"bool": {"filter": [
+			{"range": {"@timestamp": {"gte": 1586822400000, "lt": 1586908800000, "format": "epoch_millis"}}},
+			{"simple_query_string": {"query": "\"5e95b18d\" \"\\\"quoted\\\"\"", "default_operator": "and", "fields": ["message"]}}
+		]}}
+	}`
+	require.Len(t, requests, 1)
+	actual, err := json.Marshal(requests[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+
+	query.Token = page.NextToken
+	_, err = source.Filter(context.Background(), query)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []interface{}{float64(1586868647054), float64(12)}, requests[1]["search_after"])
+}
+
+func Test_Elasticsearch_Filter_LastPage(t *testing.T) {
+	var requests []map[string]interface{}
+	server := newElasticsearchServer(t, http.StatusOK, `{"hits":{"hits":[{"_source":{"msg":"first"},"sort":[1586868647054,10]}]}}`, &requests)
+	defer server.Close()
+
+	page, err := newElasticsearch(t, server.URL, nil).Filter(context.Background(), &Query{Stream: "billing-server*", Start: esStart, End: esEnd, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 1)
+	assert.Empty(t, page.NextToken)
+
+	// the default fields of the index are searched
+	require.Len(t, requests, 1)
+	match := requests[0]["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})[1]
+	assert.NotContains(t, match.(map[string]interface{})["simple_query_string"], "fields")
+	assert.NotContains(t, requests[0], "search_after")
+}
+
+func Test_Elasticsearch_Filter_Error(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		response string
+		expected string
+	}{
+		{
+			name:     "bad request",
+			status:   http.StatusBadRequest,
+			response: `{"error":"parsing_exception"}`,
+			expected: `elasticsearch search failed with status 400: {"error":"parsing_exception"}`,
+		},
+		{
+			name:     "error body cut",
+			status:   http.StatusServiceUnavailable,
+			response: strings.Repeat("e", esErrorBodyMaxSize+10),
+			expected: "elasticsearch search failed with status 503: " + strings.Repeat("e", esErrorBodyMaxSize),
+		},
+		{
+			name:     "invalid response",
+			status:   http.StatusOK,
+			response: `{"hits":`,
+			expected: "unexpected EOF",
+		},
+		{
+			name:     "invalid timestamp",
+			status:   http.StatusOK,
+			response: `{"hits":{"hits":[{"_source":{},"sort":["yesterday",1]}]}}`,
+			expected: "invalid elasticsearch timestamp yesterday",
+		},
+	}
+
+	for _, c := range cases {
+		server := newElasticsearchServer(t, c.status, c.response, nil)
+		_, err := newElasticsearch(t, server.URL, nil).Filter(context.Background(), &Query{Stream: "billing-server*", Start: esStart, End: esEnd, Limit: 10})
+		server.Close()
+
+		require.Error(t, err, c.name)
+		assert.Contains(t, err.Error(), c.expected, c.name)
+	}
+}
+
+func Test_Elasticsearch_Filter_InvalidToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the search isn't expected to be requested")
+	}))
+	defer server.Close()
+
+	source := newElasticsearch(t, server.URL, nil)
+	tokens := map[string]string{
+		"not base64":   "!!!",
+		"not json":     base64.RawURLEncoding.EncodeToString([]byte("sort")),
+		"not an array": base64.RawURLEncoding.EncodeToString([]byte(`{"sort":1}`)),
+		"empty array":  base64.RawURLEncoding.EncodeToString([]byte(`[]`)),
+		"padded":       base64.URLEncoding.EncodeToString([]byte(`[1586868647054,1]`)),
+	}
+
+	for name, token := range tokens {
+		_, err := source.Filter(context.Background(), &Query{Stream: "billing-server*", Start: esStart, End: esEnd, Limit: 10, Token: token})
+		assert.Equal(t, ErrInvalidToken, err, name)
+	}
+}
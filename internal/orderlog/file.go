@@ -0,0 +1,129 @@
+package orderlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// FileConfig of the local JSON lines files
+type FileConfig struct {
+	// TimestampField is the time of the log record, either the RFC 3339 string or the unix time in seconds
+	TimestampField string `envconfig:"ORDER_LOGS_FILE_TIMESTAMP_FIELD" default:"ts"`
+}
+
+type file struct {
+	cfg *FileConfig
+}
+
+// NewFile creates the source of the local JSON lines files, the stream of the query is the path of the file,
+// the pages are read from the offset of the line following the last event of the previous page
+func NewFile(cfg *FileConfig) Source {
+	return &file{cfg: cfg}
+}
+
+// Filter
+func (s *file) Filter(ctx context.Context, query *Query) (*Page, error) {
+	var offset int64
+
+	if query.Token != "" {
+		var err error
+		offset, err = strconv.ParseInt(query.Token, 10, 64)
+
+		if err != nil || offset < 0 {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	f, err := os.Open(query.Stream)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > info.Size() {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	page := &Page{}
+	reader := bufio.NewReader(f)
+
+	for int64(len(page.Events)) < query.Limit {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		line, err := reader.ReadBytes('\n')
+
+		// the last line without the line break may be still written, it's read by the next request
+		if err == io.EOF {
+			return page, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		offset += int64(len(line))
+		line = bytes.TrimSpace(line)
+
+		if len(line) == 0 || !containsTerms(string(line), query.Terms) {
+			continue
+		}
+
+		ts, ok := s.timestamp(line)
+
+		if !ok || ts.Before(query.Start) || !ts.Before(query.End) {
+			continue
+		}
+
+		page.Events = append(page.Events, &Event{Timestamp: ts, Message: string(line)})
+	}
+
+	page.NextToken = strconv.FormatInt(offset, 10)
+
+	return page, nil
+}
+
+func (s *file) timestamp(line []byte) (time.Time, bool) {
+	record := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&record); err != nil {
+		return time.Time{}, false
+	}
+
+	switch value := record[s.cfg.TimestampField].(type) {
+	case json.Number:
+		seconds, err := value.Float64()
+
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), true
+	case string:
+		ts, err := time.Parse(time.RFC3339Nano, value)
+		return ts, err == nil
+	}
+
+	return time.Time{}, false
+}
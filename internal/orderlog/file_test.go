@@ -0,0 +1,225 @@
+package orderlog
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var (
+	fileStart = time.Date(2020, 4, 14, 12, 0, 0, 0, time.UTC)
+	fileEnd   = time.Date(2020, 4, 14, 13, 0, 0, 0, time.UTC)
+)
+
+const fileLog = `{"ts":"2020-04-14T11:59:59Z","order_id":"5e95b18d","msg":"before the period"}
+{"ts":"2020-04-14T12:00:00Z","order_id":"5e95b18d","msg":"first"}
+
+{"ts":1586866500.5,"order_id":"5e95b18d","msg":"unix time"}
+{"ts":"2020-04-14T12:20:00Z","order_id":"5e95b18e","msg":"other order"}
+not a json 5e95b18d
+{"order_id":"5e95b18d","msg":"without the time"}
+{"ts":"yesterday","order_id":"5e95b18d","msg":"invalid time"}
+{"ts":"2020-04-14T12:40:00.123+02:00","order_id":"5e95b18d","msg":"time zone"}
+	{"ts":"2020-04-14T12:50:00Z","order_id":"5e95b18d","msg":"spaces"}  
+{"ts":"2020-04-14T13:00:00Z","order_id":"5e95b18d","msg":"end of the period"}
+{"ts":"2020-04-14T12:59:59Z","order_id":"5e95b18d","msg":"still written"}`
+
+func newLogFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "orderlog")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+
+	return f.Name()
+}
+
+func Test_File_Filter(t *testing.T) {
+	path := newLogFile(t, fileLog)
+	defer os.Remove(path)
+
+	cases := []struct {
+		name     string
+		terms    []string
+		start    time.Time
+		end      time.Time
+		expected []string
+	}{
+		{
+			name:     "order",
+			terms:    []string{`"order_id":"5e95b18d"`},
+			expected: []string{"first", "unix time", "spaces"},
+		},
+		{
+			name:     "all terms",
+			terms:    []string{"5e95b18d", `"msg":"first"`},
+			expected: []string{"first"},
+		},
+		{
+			name:     "other order",
+			terms:    []string{"5e95b18e"},
+			expected: []string{"other order"},
+		},
+		{
+			name:     "without terms",
+			expected: []string{"first", "unix time", "other order", "spaces"},
+		},
+		{
+			name:     "time zone and the end of the period excluded",
+			terms:    []string{"5e95b18d"},
+			start:    fileStart.Add(-2 * time.Hour),
+			end:      fileStart,
+			expected: []string{"before the period", "time zone"},
+		},
+		{
+			name:  "unknown term",
+			terms: []string{"5e95b18f"},
+		},
+	}
+
+	source := NewFile(&FileConfig{TimestampField: "ts"})
+
+	for _, c := range cases {
+		query := &Query{Stream: path, Terms: c.terms, Start: fileStart, End: fileEnd, Limit: 100}
+
+		if !c.start.IsZero() {
+			query.Start, query.End = c.start, c.end
+		}
+
+		page, err := source.Filter(context.Background(), query)
+		require.NoError(t, err, c.name)
+		assert.Empty(t, page.NextToken, c.name)
+		assert.Equal(t, c.expected, messages(t, page), c.name)
+	}
+}
+
+func Test_File_Filter_Event(t *testing.T) {
+	path := newLogFile(t, fileLog)
+	defer os.Remove(path)
+
+	page, err := NewFile(&FileConfig{TimestampField: "ts"}).Filter(context.Background(), &Query{
+		Stream: path,
+		Terms:  []string{"5e95b18d"},
+		Start:  fileStart,
+		End:    fileEnd,
+		Limit:  100,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 3)
+
+	assert.Equal(t, fileStart, page.Events[0].Timestamp)
+	assert.Equal(t, `{"ts":"2020-04-14T12:00:00Z","order_id":"5e95b18d","msg":"first"}`, page.Events[0].Message)
+	assert.Equal(t, time.Date(2020, 4, 14, 12, 15, 0, 500000000, time.UTC), page.Events[1].Timestamp)
+	// the spaces around the line are trimmed
+	assert.Equal(t, `{"ts":"2020-04-14T12:50:00Z","order_id":"5e95b18d","msg":"spaces"}`, page.Events[2].Message)
+}
+
+func Test_File_Filter_Pages(t *testing.T) {
+	path := newLogFile(t, fileLog)
+	defer os.Remove(path)
+
+	source := NewFile(&FileConfig{TimestampField: "ts"})
+	query := &Query{Stream: path, Start: fileStart, End: fileEnd, Limit: 2}
+	var pages [][]string
+
+	for i := 0; i < 10; i++ {
+		page, err := source.Filter(context.Background(), query)
+		require.NoError(t, err)
+		pages = append(pages, messages(t, page))
+
+		if page.NextToken == "" {
+			break
+		}
+
+		query.Token = page.NextToken
+	}
+
+	assert.Equal(t, [][]string{{"first", "unix time"}, {"other order", "spaces"}, nil}, pages)
+}
+
+func Test_File_Filter_AppendedLine(t *testing.T) {
+	path := newLogFile(t, `{"ts":"2020-04-14T12:00:00Z","msg":"first"}`+"\n"+`{"ts":"2020-04-14T12:01:00Z","msg":"sec`)
+	defer os.Remove(path)
+
+	source := NewFile(&FileConfig{TimestampField: "ts"})
+	query := &Query{Stream: path, Start: fileStart, End: fileEnd, Limit: 1}
+
+	page, err := source.Filter(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first"}, messages(t, page))
+	require.NotEmpty(t, page.NextToken)
+
+	query.Token = page.NextToken
+	page, err = source.Filter(context.Background(), query)
+	require.NoError(t, err)
+	assert.Empty(t, page.Events)
+
+	// the line finished after the previous request is read from the same token
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`ond"}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	page, err = source.Filter(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second"}, messages(t, page))
+}
+
+func Test_File_Filter_Error(t *testing.T) {
+	path := newLogFile(t, fileLog)
+	defer os.Remove(path)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name  string
+		ctx   context.Context
+		query *Query
+		err   error
+	}{
+		{name: "token not a number", query: &Query{Stream: path, Token: "abc"}, err: ErrInvalidToken},
+		{name: "negative token", query: &Query{Stream: path, Token: "-1"}, err: ErrInvalidToken},
+		{name: "token after the end", query: &Query{Stream: path, Token: strconv.Itoa(len(fileLog) + 1)}, err: ErrInvalidToken},
+		{name: "missing file", query: &Query{Stream: path + ".missing"}},
+		{name: "canceled", ctx: canceled, query: &Query{Stream: path}, err: context.Canceled},
+	}
+
+	source := NewFile(&FileConfig{TimestampField: "ts"})
+
+	for _, c := range cases {
+		ctx := c.ctx
+
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		c.query.Start, c.query.End, c.query.Limit = fileStart, fileEnd, 10
+		_, err := source.Filter(ctx, c.query)
+		require.Error(t, err, c.name)
+
+		if c.err != nil {
+			assert.Equal(t, c.err, err, c.name)
+		}
+	}
+}
+
+func messages(t *testing.T, page *Page) []string {
+	var messages []string
+
+	for _, event := range page.Events {
+		record := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(event.Message), &record))
+		messages = append(messages, record["msg"].(string))
+	}
+
+	return messages
+}
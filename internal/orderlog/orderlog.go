@@ -0,0 +1,90 @@
+package orderlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	BackendCloudWatch    = "cloudwatch"
+	BackendElasticsearch = "elasticsearch"
+	BackendFile          = "file"
+)
+
+// ErrInvalidToken is returned by the source if the page token wasn't issued by it
+var ErrInvalidToken = errors.New("invalid order log page token")
+
+// Config of the order logs
+type Config struct {
+	// Backend is the source of the order logs: cloudwatch, elasticsearch or file
+	Backend string `envconfig:"ORDER_LOGS_BACKEND" default:"cloudwatch"`
+	// Limit is the default number of the events of each log on the page, LimitMax is the maximum one
+	Limit    int64 `envconfig:"ORDER_LOGS_LIMIT" default:"100"`
+	LimitMax int64 `envconfig:"ORDER_LOGS_LIMIT_MAX" default:"1000"`
+	// Window is the default period of the logs since the order creation, WindowMax is the maximum period
+	Window    time.Duration `envconfig:"ORDER_LOGS_WINDOW" default:"168h"`
+	WindowMax time.Duration `envconfig:"ORDER_LOGS_WINDOW_MAX" default:"744h"`
+	// Streams are the elasticsearch indices or the files of the service logs, the cloudwatch backend uses
+	// the log groups of the logs settings if they are empty
+	Streams       Streams
+	Elasticsearch ElasticsearchConfig
+	File          FileConfig
+}
+
+// Streams are the names of the logs written by the services
+type Streams struct {
+	BillingServer   string `envconfig:"ORDER_LOGS_STREAM_BILLING_SERVER"`
+	ManagementApi   string `envconfig:"ORDER_LOGS_STREAM_MANAGEMENT_API"`
+	WebhookNotifier string `envconfig:"ORDER_LOGS_STREAM_WEBHOOK_NOTIFIER"`
+}
+
+// Source reads the log events of the orders
+type Source interface {
+	// Filter returns the page of the events of the stream matched by the query in the chronological order
+	Filter(ctx context.Context, query *Query) (*Page, error)
+}
+
+// Query of the log events
+type Query struct {
+	// Stream is the log group, the index or the file of the events
+	Stream string
+	// Terms must all be found in the event
+	Terms []string
+	// Start and End of the period of the events, End is excluded
+	Start time.Time
+	End   time.Time
+	// Limit is the maximum number of the events on the page
+	Limit int64
+	// Token is the next page token of the previous page, the first page is returned if it's empty
+	Token string
+}
+
+// Event is the log record written by the service
+type Event struct {
+	Timestamp time.Time
+	// Message is the JSON log record
+	Message string
+}
+
+// Page of the log events
+type Page struct {
+	Events []*Event
+	// NextToken is empty on the last page
+	NextToken string
+}
+
+// IsCloudWatch reports if the amazon cloudwatch backend is used, it's the default one
+func (c *Config) IsCloudWatch() bool {
+	return c.Backend == BackendCloudWatch || c.Backend == ""
+}
+
+func containsTerms(message string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(message, term) {
+			return false
+		}
+	}
+	return true
+}
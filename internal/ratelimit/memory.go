@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type bucket struct {
+	key       string
+	tokens    float64
+	updatedAt time.Time
+	idleAfter time.Time
+}
+
+// MemoryStore keeps the buckets of a single replica, the buckets are ordered by the last use to drop
+// the least recently used one when the number of the buckets reaches the limit
+type MemoryStore struct {
+	mx         sync.Mutex
+	buckets    map[string]*list.Element
+	recent     *list.List
+	maxBuckets int
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+// NewMemoryStore creates the store of maxBuckets buckets at most, the number isn't limited if it's zero
+func NewMemoryStore(maxBuckets int) *MemoryStore {
+	return &MemoryStore{
+		buckets:    make(map[string]*list.Element),
+		recent:     list.New(),
+		maxBuckets: maxBuckets,
+		lastSweep:  time.Now(),
+		now:        time.Now,
+	}
+}
+
+// Take
+func (s *MemoryStore) Take(_ context.Context, key string, rule *Rule) (*Result, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	var b *bucket
+
+	if el, ok := s.buckets[key]; ok {
+		b = el.Value.(*bucket)
+		s.recent.MoveToFront(el)
+	} else {
+		if s.maxBuckets > 0 && len(s.buckets) >= s.maxBuckets {
+			s.remove(s.recent.Back())
+		}
+
+		b = &bucket{key: key, tokens: float64(rule.Requests), updatedAt: now}
+		s.buckets[key] = s.recent.PushFront(b)
+	}
+
+	tokens, res := tokenBucket(b.tokens, now.Sub(b.updatedAt), rule)
+	b.tokens = tokens
+	b.updatedAt = now
+	// the bucket which is full again is equal to the missing one
+	b.idleAfter = now.Add(res.Reset)
+
+	return res, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	for _, el := range s.buckets {
+		if now.After(el.Value.(*bucket).idleAfter) {
+			s.remove(el)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.recent.Remove(el)
+	delete(s.buckets, el.Value.(*bucket).key)
+}
@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type MemoryStoreTestSuite struct {
+	suite.Suite
+	store *MemoryStore
+	now   time.Time
+	rule  *Rule
+}
+
+func Test_MemoryStore(t *testing.T) {
+	suite.Run(t, new(MemoryStoreTestSuite))
+}
+
+func (suite *MemoryStoreTestSuite) SetupTest() {
+	suite.now = time.Date(2020, 4, 15, 8, 10, 44, 0, time.UTC)
+	suite.store = NewMemoryStore(3)
+	suite.store.lastSweep = suite.now
+	suite.store.now = func() time.Time {
+		return suite.now
+	}
+	suite.rule = &Rule{Requests: 2, Period: time.Second, Key: KeyIp}
+}
+
+func (suite *MemoryStoreTestSuite) TestTake_Burst() {
+	res := suite.take("key")
+	assert.True(suite.T(), res.Allowed)
+	assert.Equal(suite.T(), 2, res.Limit)
+	assert.Equal(suite.T(), 1, res.Remaining)
+	suite.assertDuration(500*time.Millisecond, res.Reset)
+
+	res = suite.take("key")
+	assert.True(suite.T(), res.Allowed)
+	assert.Equal(suite.T(), 0, res.Remaining)
+	suite.assertDuration(time.Second, res.Reset)
+
+	res = suite.take("key")
+	assert.False(suite.T(), res.Allowed)
+	assert.Equal(suite.T(), 0, res.Remaining)
+	suite.assertDuration(500*time.Millisecond, res.RetryAfter)
+}
+
+func (suite *MemoryStoreTestSuite) TestTake_Refill() {
+	suite.take("key")
+	suite.take("key")
+	assert.False(suite.T(), suite.take("key").Allowed)
+
+	suite.now = suite.now.Add(250 * time.Millisecond)
+	res := suite.take("key")
+	assert.False(suite.T(), res.Allowed)
+	suite.assertDuration(250*time.Millisecond, res.RetryAfter)
+
+	suite.now = suite.now.Add(250 * time.Millisecond)
+	res = suite.take("key")
+	assert.True(suite.T(), res.Allowed)
+	assert.Equal(suite.T(), 0, res.Remaining)
+	suite.assertDuration(time.Duration(0), res.RetryAfter)
+}
+
+func (suite *MemoryStoreTestSuite) TestTake_RefillUpToCapacity() {
+	suite.take("key")
+	suite.take("key")
+
+	suite.now = suite.now.Add(time.Hour)
+
+	assert.True(suite.T(), suite.take("key").Allowed)
+	assert.True(suite.T(), suite.take("key").Allowed)
+	assert.False(suite.T(), suite.take("key").Allowed)
+}
+
+func (suite *MemoryStoreTestSuite) TestTake_SeparateBuckets() {
+	suite.take("key")
+	suite.take("key")
+	assert.False(suite.T(), suite.take("key").Allowed)
+
+	res := suite.take("other_key")
+	assert.True(suite.T(), res.Allowed)
+	assert.Equal(suite.T(), 1, res.Remaining)
+}
+
+func (suite *MemoryStoreTestSuite) TestSweep_RemovesFullBuckets() {
+	suite.take("key")
+	suite.take("other_key")
+	suite.take("other_key")
+
+	suite.now = suite.now.Add(memorySweepInterval)
+	suite.take("third_key")
+
+	assert.Len(suite.T(), suite.store.buckets, 1)
+	assert.Contains(suite.T(), suite.store.buckets, "third_key")
+	assert.Equal(suite.T(), 1, suite.store.recent.Len())
+}
+
+func (suite *MemoryStoreTestSuite) TestTake_MaxBuckets() {
+	suite.take("first_key")
+	suite.take("second_key")
+	suite.take("third_key")
+	suite.take("first_key")
+	suite.take("first_key")
+	assert.False(suite.T(), suite.take("first_key").Allowed)
+
+	// the least recently used bucket is dropped
+	suite.take("fourth_key")
+
+	assert.Len(suite.T(), suite.store.buckets, 3)
+	assert.Equal(suite.T(), 3, suite.store.recent.Len())
+	assert.NotContains(suite.T(), suite.store.buckets, "second_key")
+
+	// the used bucket is kept with its tokens
+	assert.False(suite.T(), suite.take("first_key").Allowed)
+}
+
+func (suite *MemoryStoreTestSuite) TestTake_MaxBucketsNotLimited() {
+	suite.store.maxBuckets = 0
+
+	for i := 0; i < 10; i++ {
+		suite.take(strconv.Itoa(i))
+	}
+
+	assert.Len(suite.T(), suite.store.buckets, 10)
+}
+
+func (suite *MemoryStoreTestSuite) TestLimiter_Rule() {
+	limiter, err := NewLimiter(
+		&Config{
+			Groups: map[string]Rule{"AuthUser": {Requests: 10, Period: time.Second, Key: KeyMerchant}},
+			Routes: []RouteRule{{Method: "post", Path: "/admin/api/v1/order/:order_id/refunds", Requests: 1, Period: time.Second}},
+		},
+		suite.store,
+		[]RouteRule{{Path: "/api/v1/order", Requests: 5, Period: time.Second}},
+	)
+	require.NoError(suite.T(), err)
+
+	id, rule := limiter.Rule(GroupAuthUser, "POST", "/admin/api/v1/order/:order_id/refunds")
+	assert.Equal(suite.T(), "POST /admin/api/v1/order/:order_id/refunds", id)
+	assert.Equal(suite.T(), 1, rule.Requests)
+
+	id, rule = limiter.Rule(GroupCommon, "GET", "/api/v1/order")
+	assert.Equal(suite.T(), " /api/v1/order", id)
+	assert.Equal(suite.T(), 5, rule.Requests)
+
+	id, rule = limiter.Rule(GroupAuthUser, "GET", "/admin/api/v1/orders")
+	assert.Equal(suite.T(), GroupAuthUser, id)
+	assert.Equal(suite.T(), KeyMerchant, rule.Key)
+
+	_, rule = limiter.Rule(GroupWebHooks, "POST", "/webhook/cardpay")
+	assert.False(suite.T(), rule.Enabled())
+}
+
+func (suite *MemoryStoreTestSuite) TestLimiter_UnknownKey() {
+	_, err := NewLimiter(&Config{Groups: map[string]Rule{"common": {Requests: 1, Period: time.Second, Key: "session"}}}, suite.store, nil)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MemoryStoreTestSuite) take(key string) *Result {
+	res, err := suite.store.Take(context.Background(), key, suite.rule)
+	require.NoError(suite.T(), err)
+	return res
+}
+
+// assertDuration ignores the rounding of the token bucket math
+func (suite *MemoryStoreTestSuite) assertDuration(expected, actual time.Duration) {
+	assert.InDelta(suite.T(), float64(expected), float64(actual), float64(time.Microsecond))
+}
@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+
+	KeyMerchant = "merchant"
+	KeyUser     = "user"
+	KeyIp       = "ip"
+
+	// Group names are lower-cased because the config loader lower-cases the map keys
+	GroupAuthProject = "authproject"
+	GroupAuthUser    = "authuser"
+	GroupSystemUser  = "systemuser"
+	GroupCommon      = "common"
+	GroupWebHooks    = "webhooks"
+)
+
+// Rule allows Requests requests per Period with bursts up to Requests, the bucket is shared by
+// all requests having the same Key: merchant id, user id or client IP
+type Rule struct {
+	Requests int
+	Period   time.Duration
+	Key      string `default:"ip"`
+}
+
+// Enabled
+func (r *Rule) Enabled() bool {
+	return r != nil && r.Requests > 0 && r.Period > 0
+}
+
+// RouteRule overrides the group rule for the route template, all methods are matched if Method is empty
+type RouteRule struct {
+	Method   string
+	Path     string
+	Requests int
+	Period   time.Duration
+	Key      string `default:"ip"`
+}
+
+// Config
+type Config struct {
+	Store string `default:"memory"`
+	// MaxBuckets limits the number of the buckets kept by the memory store, the least recently used one is
+	// dropped for the new key if the limit is reached
+	MaxBuckets int `default:"100000"`
+	// TrustProxyHeaders allows the client IP of the X-Forwarded-For and X-Real-IP headers, it must be enabled only
+	// behind the proxy which overwrites them
+	TrustProxyHeaders bool
+	Groups            map[string]Rule
+	Routes            []RouteRule
+}
+
+// Result
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until the next token is available, it's zero for allowed requests
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps the token buckets
+type Store interface {
+	// Take removes a token from the bucket if there is one
+	Take(ctx context.Context, key string, rule *Rule) (*Result, error)
+}
+
+// NewStore
+func NewStore(cfg *Config, client *redis.Client) (Store, error) {
+	switch cfg.Store {
+	case "", StoreMemory:
+		return NewMemoryStore(cfg.MaxBuckets), nil
+	case StoreRedis:
+		if client == nil {
+			return nil, fmt.Errorf("rate limit store %q requires redis settings", cfg.Store)
+		}
+		return NewRedisStore(client), nil
+	}
+	return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+}
+
+// Limiter resolves the rule of the request and takes a token from the bucket of the rule
+type Limiter struct {
+	store  Store
+	groups map[string]*Rule
+	routes map[string]*Rule
+}
+
+// NewLimiter creates the limiter from the config, the config routes override the default routes
+func NewLimiter(cfg *Config, store Store, defaults []RouteRule) (*Limiter, error) {
+	l := &Limiter{
+		store:  store,
+		groups: make(map[string]*Rule, len(cfg.Groups)),
+		routes: make(map[string]*Rule, len(defaults)+len(cfg.Routes)),
+	}
+
+	for name, rule := range cfg.Groups {
+		r := rule
+		if err := validateKey(r.Key); err != nil {
+			return nil, err
+		}
+		l.groups[strings.ToLower(name)] = &r
+	}
+
+	for _, route := range defaults {
+		l.routes[routeId(route.Method, route.Path)] = &Rule{Requests: route.Requests, Period: route.Period, Key: route.Key}
+	}
+
+	for _, route := range cfg.Routes {
+		if err := validateKey(route.Key); err != nil {
+			return nil, err
+		}
+		l.routes[routeId(route.Method, route.Path)] = &Rule{Requests: route.Requests, Period: route.Period, Key: route.Key}
+	}
+
+	return l, nil
+}
+
+// Rule returns the route rule if any, the group rule otherwise, the returned id identifies the bucket set
+func (l *Limiter) Rule(group, method, path string) (string, *Rule) {
+	if rule, ok := l.routes[routeId(method, path)]; ok {
+		return routeId(method, path), rule
+	}
+	if rule, ok := l.routes[routeId("", path)]; ok {
+		return routeId("", path), rule
+	}
+	return group, l.groups[group]
+}
+
+// Take
+func (l *Limiter) Take(ctx context.Context, id, value string, rule *Rule) (*Result, error) {
+	return l.store.Take(ctx, id+"|"+rule.Key+"|"+value, rule)
+}
+
+// tokenBucket returns the new number of tokens and the result for the bucket state
+func tokenBucket(tokens float64, elapsed time.Duration, rule *Rule) (float64, *Result) {
+	capacity := float64(rule.Requests)
+	tokens = math.Min(capacity, tokens+float64(elapsed)*capacity/float64(rule.Period))
+	allowed := tokens >= 1
+
+	if allowed {
+		tokens--
+	}
+
+	return tokens, newResult(tokens, allowed, rule)
+}
+
+func newResult(tokens float64, allowed bool, rule *Rule) *Result {
+	capacity := float64(rule.Requests)
+	rate := capacity / float64(rule.Period)
+	res := &Result{
+		Allowed:   allowed,
+		Limit:     rule.Requests,
+		Remaining: int(tokens),
+		Reset:     time.Duration((capacity - tokens) / rate),
+	}
+
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / rate)
+	}
+
+	return res
+}
+
+func routeId(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+func validateKey(key string) error {
+	switch key {
+	case "", KeyIp, KeyMerchant, KeyUser:
+		return nil
+	}
+	return fmt.Errorf("unknown rate limit key %q", key)
+}
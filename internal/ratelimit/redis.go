@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"strconv"
+)
+
+const redisKeyPrefix = "ratelimit:"
+
+// KEYS[1] - bucket, ARGV[1] - capacity, ARGV[2] - period in ms, the current time is the redis server time
+// so the clocks of the replicas don't matter, the commands are replicated instead of the script because of it
+const redisTakeScript = `
+redis.replicate_commands()
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * capacity / period)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, tostring(tokens)}
+`
+
+// RedisStore shares the buckets between the replicas, the buckets are updated atomically by a lua script
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Take
+func (s *RedisStore) Take(ctx context.Context, key string, rule *Rule) (*Result, error) {
+	rep, err := s.client.Eval(
+		ctx,
+		redisTakeScript,
+		[]string{redisKeyPrefix + key},
+		rule.Requests,
+		rule.Period.Milliseconds(),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := rep.([]interface{})
+	if !ok || len(items) != 2 {
+		return nil, fmt.Errorf("redis: unexpected reply %v", rep)
+	}
+
+	allowed, _ := items[0].(int64)
+	raw, _ := items[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newResult(tokens, allowed == 1, rule), nil
+}
@@ -0,0 +1,327 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Mask replaces the redacted values
+	Mask = "[REDACTED]"
+
+	DetectorPan   = "pan"
+	DetectorEmail = "email"
+	DetectorIban  = "iban"
+
+	pathWildcard = "*"
+)
+
+var (
+	// DefaultPaths are the personal data of the payment system callbacks, the order identifiers are kept
+	// to find the logs of the order
+	DefaultPaths = []string{
+		"card_account.card",
+		"card_account.billing_address",
+		"card_account.holder",
+		"card_account.masked_pan",
+		"customer.email",
+		"customer.full_name",
+		"customer.ip",
+		"customer.phone",
+		"customer.login",
+		"recurring_data.filing.*",
+		"ewallet_account",
+	}
+
+	// DefaultHeaders are the credentials and the signatures
+	DefaultHeaders = []string{
+		"Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"Signature",
+		"X-Api-Signature",
+	}
+
+	// DefaultQueryParams are the credentials passed in the query string, e.g. the report file download tokens
+	DefaultQueryParams = []string{"token"}
+
+	// DefaultDetectors are applied to all string values
+	DefaultDetectors = []string{DetectorPan, DetectorEmail, DetectorIban}
+
+	detectors = map[string]*detector{
+		DetectorPan: {
+			regex: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b|\b\d{4,6}[*xX]{4,9}\d{4}\b|\b\d{6,8}\.{2,6}\d{4}\b`),
+			check: isPan,
+		},
+		DetectorEmail: {
+			regex: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+		},
+		DetectorIban: {
+			regex: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+			check: isIban,
+		},
+	}
+)
+
+// Config of the redaction, the default rules are used for the empty lists
+type Config struct {
+	// Paths of the redacted JSON values separated by dots, * matches any key, arrays are matched by the path
+	// of their elements, e.g. card_account.card or customer.*
+	Paths []string
+	// Headers are the names of the redacted headers
+	Headers []string
+	// QueryParams are the names of the redacted query parameters of the request uri
+	QueryParams []string
+	// Detectors are the names of the built-in detectors of the values: pan, email, iban
+	Detectors []string
+	// Patterns are the additional regular expressions of the redacted values
+	Patterns []string
+}
+
+type detector struct {
+	regex *regexp.Regexp
+	// check filters out the false positives of the regular expression, all matches are redacted if it's nil
+	check func(match string) bool
+}
+
+// Redactor masks the personal data and the secrets in the headers and the bodies before they are logged
+type Redactor struct {
+	paths       [][]string
+	headers     map[string]bool
+	queryParams map[string]bool
+	detectors   []*detector
+}
+
+// New
+func New(cfg *Config) (*Redactor, error) {
+	r := &Redactor{headers: make(map[string]bool), queryParams: make(map[string]bool)}
+
+	paths := cfg.Paths
+	if len(paths) == 0 {
+		paths = DefaultPaths
+	}
+	for _, path := range paths {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+		if path == "" {
+			continue
+		}
+		r.paths = append(r.paths, strings.Split(path, "."))
+	}
+
+	headers := cfg.Headers
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	for _, header := range headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	queryParams := cfg.QueryParams
+	if len(queryParams) == 0 {
+		queryParams = DefaultQueryParams
+	}
+	for _, param := range queryParams {
+		r.queryParams[param] = true
+	}
+
+	names := cfg.Detectors
+	if len(names) == 0 {
+		names = DefaultDetectors
+	}
+	for _, name := range names {
+		d, ok := detectors[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction detector %q", name)
+		}
+		r.detectors = append(r.detectors, d)
+	}
+
+	for _, pattern := range cfg.Patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		r.detectors = append(r.detectors, &detector{regex: regex})
+	}
+
+	return r, nil
+}
+
+// Headers returns the copy of the headers with the redacted values
+func (r *Redactor) Headers(headers http.Header) http.Header {
+	out := make(http.Header, len(headers))
+
+	for name, values := range headers {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			out[name] = []string{Mask}
+			continue
+		}
+		list := make([]string, len(values))
+		for i, value := range values {
+			list[i] = r.String(value)
+		}
+		out[name] = list
+	}
+
+	return out
+}
+
+// RequestUri returns the request uri with the values of the redacted query parameters masked, the order
+// and the encoding of the other parameters are kept
+func (r *Redactor) RequestUri(uri string) string {
+	i := strings.IndexByte(uri, '?')
+	if i < 0 {
+		return uri
+	}
+
+	params := strings.Split(uri[i+1:], "&")
+	redacted := false
+
+	for j, param := range params {
+		name := param
+		if k := strings.IndexByte(param, '='); k >= 0 {
+			name = param[:k]
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if r.queryParams[name] {
+			params[j] = param[:strings.IndexByte(param+"=", '=')] + "=" + Mask
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return uri
+	}
+
+	return uri[:i+1] + strings.Join(params, "&")
+}
+
+// Body returns the redacted body, the values of the JSON body are redacted by the paths and the detectors,
+// other bodies are redacted by the detectors only
+func (r *Redactor) Body(body []byte) string {
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return r.String(string(body))
+	}
+
+	for _, path := range r.paths {
+		value = redactPath(value, path)
+	}
+
+	value = r.values(value)
+	out, err := json.Marshal(value)
+
+	if err != nil {
+		return r.String(string(body))
+	}
+
+	return string(out)
+}
+
+// String returns the string with the values found by the detectors redacted
+func (r *Redactor) String(s string) string {
+	for _, d := range r.detectors {
+		s = d.regex.ReplaceAllStringFunc(s, func(match string) string {
+			if d.check != nil && !d.check(match) {
+				return match
+			}
+			return Mask
+		})
+	}
+	return s
+}
+
+func (r *Redactor) values(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = r.values(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.values(item)
+		}
+	case string:
+		return r.String(v)
+	}
+	return value
+}
+
+func redactPath(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return Mask
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if path[0] == pathWildcard || path[0] == key {
+				v[key] = redactPath(item, path[1:])
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactPath(item, path)
+		}
+	}
+
+	return value
+}
+
+// isPan checks the Luhn checksum of the card number, the masked numbers (400000******0077, 400000...0077)
+// are always redacted
+func isPan(match string) bool {
+	if strings.ContainsAny(match, "*xX.") {
+		return true
+	}
+
+	sum, double := 0, false
+
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// isIban checks the mod 97 checksum of the account number
+func isIban(match string) bool {
+	iban := strings.Replace(match, " ", "", -1)
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+
+	for _, c := range rearranged {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+
+	return remainder == 1
+}
@@ -0,0 +1,201 @@
+package redact
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func Test_Redactor_String(t *testing.T) {
+	r, err := New(&Config{})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "pan", value: "card 4111111111111111 used", expected: "card " + Mask + " used"},
+		{name: "pan with spaces", value: "4000 0000 0000 0002", expected: Mask},
+		{name: "pan with dashes", value: "4000-0000-0000-0002", expected: Mask},
+		{name: "pan masked with asterisks", value: "400000******0077", expected: Mask},
+		{name: "pan masked with x", value: "400000XXXXXX0077", expected: Mask},
+		{name: "pan masked with dots", value: "400000...0002", expected: Mask},
+		{name: "timestamp in milliseconds", value: "ts 1586868647054", expected: "ts 1586868647054"},
+		{name: "date time", value: "20200414125046", expected: "20200414125046"},
+		{name: "numeric order id", value: "order 12345678901234567", expected: "order 12345678901234567"},
+		{name: "hexadecimal order id", value: "5e95b18d455b51545379c11a", expected: "5e95b18d455b51545379c11a"},
+		{name: "short number", value: "3549175", expected: "3549175"},
+		{name: "email", value: "customer: customer@unit.test.", expected: "customer: " + Mask + "."},
+		{name: "iban", value: "GB82WEST12345698765432", expected: Mask},
+		{name: "iban with spaces", value: "iban GB82 WEST 1234 5698 7654 32", expected: "iban " + Mask},
+		{name: "iban invalid checksum", value: "GB00WEST12345698765432", expected: "GB00WEST12345698765432"},
+		{name: "plain text", value: "Payment by order # 5e95b18d", expected: "Payment by order # 5e95b18d"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, r.String(c.value), c.name)
+	}
+}
+
+func Test_Redactor_Body(t *testing.T) {
+	cases := []struct {
+		name     string
+		paths    []string
+		body     string
+		expected string
+	}{
+		{
+			name:     "default paths",
+			body:     `{"payment_data":{"id":"3549175"},"card_account":{"masked_pan":"400000...0002","holder":"TEST HOLDER"},"customer":{"email":"customer@unit.test","ip":"127.0.0.1","id":"1CWutDD6"}}`,
+			expected: `{"card_account":{"holder":"[REDACTED]","masked_pan":"[REDACTED]"},"customer":{"email":"[REDACTED]","id":"1CWutDD6","ip":"[REDACTED]"},"payment_data":{"id":"3549175"}}`,
+		},
+		{
+			name:     "object path",
+			body:     `{"card_account":{"card":{"pan":"400000******0077","expiration":"02/2022"}}}`,
+			expected: `{"card_account":{"card":"[REDACTED]"}}`,
+		},
+		{
+			name:     "wildcard path",
+			paths:    []string{"customer.*"},
+			body:     `{"customer":{"name":"Test","phone":"123"},"order":"1"}`,
+			expected: `{"customer":{"name":"[REDACTED]","phone":"[REDACTED]"},"order":"1"}`,
+		},
+		{
+			name:     "wildcard in the middle",
+			paths:    []string{"*.holder"},
+			body:     `{"card_account":{"holder":"Test","id":"1"},"ewallet":{"holder":"Test"}}`,
+			expected: `{"card_account":{"holder":"[REDACTED]","id":"1"},"ewallet":{"holder":"[REDACTED]"}}`,
+		},
+		{
+			name:     "array elements",
+			paths:    []string{"items.holder"},
+			body:     `{"items":[{"holder":"First","id":"1"},{"holder":"Second","id":"2"}]}`,
+			expected: `{"items":[{"holder":"[REDACTED]","id":"1"},{"holder":"[REDACTED]","id":"2"}]}`,
+		},
+		{
+			name:     "root array",
+			paths:    []string{"holder"},
+			body:     `[{"holder":"First"},{"holder":"Second"}]`,
+			expected: `[{"holder":"[REDACTED]"},{"holder":"[REDACTED]"}]`,
+		},
+		{
+			name:     "path with root prefix",
+			paths:    []string{"$.customer.email"},
+			body:     `{"customer":{"email":"none"}}`,
+			expected: `{"customer":{"email":"[REDACTED]"}}`,
+		},
+		{
+			name:     "missing path",
+			paths:    []string{"customer.email"},
+			body:     `{"payment_data":{"id":"3549175"}}`,
+			expected: `{"payment_data":{"id":"3549175"}}`,
+		},
+		{
+			name:     "detectors applied to values out of paths",
+			paths:    []string{"customer.email"},
+			body:     `{"note":"card 4111111111111111","contact":"customer@unit.test","amount":30}`,
+			expected: `{"amount":30,"contact":"[REDACTED]","note":"card [REDACTED]"}`,
+		},
+		{
+			name:     "large numbers kept",
+			paths:    []string{"customer.email"},
+			body:     `{"id":12345678901234567890}`,
+			expected: `{"id":12345678901234567890}`,
+		},
+		{
+			name:     "form body",
+			body:     `pan=4111111111111111&email=customer@unit.test&order=1586868647054`,
+			expected: `pan=[REDACTED]&email=[REDACTED]&order=1586868647054`,
+		},
+		{
+			name:     "plain text body",
+			body:     `the card 400000******0077 is declined`,
+			expected: `the card [REDACTED] is declined`,
+		},
+		{
+			name:     "several json values",
+			body:     `{"customer":{"email":"customer@unit.test"}} {"holder":"TEST HOLDER"}`,
+			expected: `{"customer":{"email":"[REDACTED]"}} {"holder":"TEST HOLDER"}`,
+		},
+		{
+			name:     "empty body",
+			body:     ``,
+			expected: ``,
+		},
+	}
+
+	for _, c := range cases {
+		r, err := New(&Config{Paths: c.paths})
+		require.NoError(t, err, c.name)
+		assert.Equal(t, c.expected, r.Body([]byte(c.body)), c.name)
+	}
+}
+
+func Test_Redactor_RequestUri(t *testing.T) {
+	r, err := New(&Config{QueryParams: []string{"token", "secret"}})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		uri      string
+		expected string
+	}{
+		{name: "without query", uri: "/api/v1/report_file/download/file.pdf", expected: "/api/v1/report_file/download/file.pdf"},
+		{name: "token", uri: "/download/file.pdf?token=abc.def", expected: "/download/file.pdf?token=" + Mask},
+		{name: "order kept", uri: "/download?b=2&token=abc&a=1", expected: "/download?b=2&token=" + Mask + "&a=1"},
+		{name: "encoding kept", uri: "/download?name=a%20b&token=abc", expected: "/download?name=a%20b&token=" + Mask},
+		{name: "escaped name", uri: "/download?to%6Ben=abc", expected: "/download?to%6Ben=" + Mask},
+		{name: "without value", uri: "/download?token", expected: "/download?token=" + Mask},
+		{name: "several parameters", uri: "/download?token=abc&secret=def", expected: "/download?token=" + Mask + "&secret=" + Mask},
+		{name: "other parameters", uri: "/download?tokens=abc&limit=10", expected: "/download?tokens=abc&limit=10"},
+		{name: "empty query", uri: "/download?", expected: "/download?"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, r.RequestUri(c.uri), c.name)
+	}
+}
+
+func Test_Redactor_Headers(t *testing.T) {
+	r, err := New(&Config{})
+	require.NoError(t, err)
+
+	headers := http.Header{
+		"Authorization":   {"Bearer eyJhbGciOiJSUzI1NiJ9"},
+		"Signature":       {"5f0e3c9b1a7d"},
+		"X-Customer":      {"customer@unit.test"},
+		"Content-Type":    {"application/json"},
+		"x-api-signature": {"5f0e3c9b1a7d"},
+	}
+	out := r.Headers(headers)
+
+	assert.Equal(t, []string{Mask}, out["Authorization"])
+	assert.Equal(t, []string{Mask}, out["Signature"])
+	assert.Equal(t, []string{Mask}, out["x-api-signature"])
+	assert.Equal(t, []string{Mask}, out["X-Customer"])
+	assert.Equal(t, []string{"application/json"}, out["Content-Type"])
+	// the headers passed are kept
+	assert.Equal(t, []string{"Bearer eyJhbGciOiJSUzI1NiJ9"}, headers["Authorization"])
+}
+
+func Test_Redactor_Patterns(t *testing.T) {
+	r, err := New(&Config{Detectors: []string{DetectorEmail}, Patterns: []string{`secret_\w+`}})
+	require.NoError(t, err)
+
+	// the pan detector isn't enabled
+	assert.Equal(t, "4111111111111111 "+Mask+" "+Mask, r.String("4111111111111111 secret_key customer@unit.test"))
+}
+
+func Test_New_Error(t *testing.T) {
+	cases := map[string]*Config{
+		"unknown detector": {Detectors: []string{"phone"}},
+		"invalid pattern":  {Patterns: []string{"("}},
+	}
+
+	for name, cfg := range cases {
+		_, err := New(cfg)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,38 @@
+package reportfile
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps the owners in the process memory, it's used by the tests
+type MemoryStore struct {
+	mx     sync.Mutex
+	owners map[string]Owner
+}
+
+// NewMemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{owners: make(map[string]Owner)}
+}
+
+// Save
+func (s *MemoryStore) Save(_ context.Context, fileId string, owner *Owner) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.owners[fileId] = *owner
+	return nil
+}
+
+// Get
+func (s *MemoryStore) Get(_ context.Context, fileId string) (*Owner, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	owner, ok := s.owners[fileId]
+	if !ok {
+		return nil, nil
+	}
+	return &owner, nil
+}
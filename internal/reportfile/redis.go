@@ -0,0 +1,46 @@
+package reportfile
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"time"
+)
+
+const redisKeyPrefix = "report_file:"
+
+// RedisStore
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStore
+func NewRedisStore(client *redis.Client, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+// Save
+func (s *RedisStore) Save(ctx context.Context, fileId string, owner *Owner) error {
+	b, err := json.Marshal(owner)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, redisKeyPrefix+fileId, b, s.ttl)
+}
+
+// Get
+func (s *RedisStore) Get(ctx context.Context, fileId string) (*Owner, error) {
+	b, err := s.client.Get(ctx, redisKeyPrefix+fileId)
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	owner := &Owner{}
+	if err = json.Unmarshal(b, owner); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
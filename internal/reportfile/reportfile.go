@@ -0,0 +1,76 @@
+package reportfile
+
+import (
+	"context"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/paysuper/paysuper-management-api/pkg/redis"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// the reporter saves the owner of the file in the object metadata
+	MetadataUserId     = "user-id"
+	MetadataMerchantId = "merchant-id"
+)
+
+// Owner of the report file, the file is available to the user who requested it and to the users of its merchant
+type Owner struct {
+	UserId     string `json:"user_id"`
+	MerchantId string `json:"merchant_id"`
+}
+
+// Owns reports if the file is requested by the user or for the merchant of the user
+func (o *Owner) Owns(userId, merchantId string) bool {
+	return (o.UserId != "" && o.UserId == userId) || (o.MerchantId != "" && o.MerchantId == merchantId)
+}
+
+// OwnerFromMetadata returns the owner saved by the reporter in the metadata of the file, it returns nil if the metadata
+// has no owner
+func OwnerFromMetadata(metadata map[string]string) *Owner {
+	owner := &Owner{UserId: metadata[MetadataUserId], MerchantId: metadata[MetadataMerchantId]}
+
+	if owner.UserId == "" && owner.MerchantId == "" {
+		return nil
+	}
+	return owner
+}
+
+// Store keeps the owners of the report files requested through the api, the owners are looked up by the
+// identifier of the file returned by the reporter, the owners missing in the store are read from the file metadata
+type Store interface {
+	Save(ctx context.Context, fileId string, owner *Owner) error
+	// Get returns nil if the owner of the file is unknown
+	Get(ctx context.Context, fileId string) (*Owner, error)
+}
+
+// NewStore returns the redis store, the owners must survive the restarts and be shared by the replicas,
+// they are kept for the ttl after the file is requested. The owners are read from the file metadata only if redis
+// isn't configured
+func NewStore(client *redis.Client, ttl time.Duration, log logger.Logger) Store {
+	if client == nil {
+		log.Warning("report file owners are read from the file metadata only, redis address isn't configured")
+		return NopStore{}
+	}
+	return NewRedisStore(client, ttl)
+}
+
+// NopStore keeps nothing, the owners are read from the file metadata
+type NopStore struct{}
+
+// Save
+func (NopStore) Save(context.Context, string, *Owner) error {
+	return nil
+}
+
+// Get
+func (NopStore) Get(context.Context, string) (*Owner, error) {
+	return nil, nil
+}
+
+// FileId returns the identifier of the file stored under the name, the name is the identifier with the extension
+// of the file format
+func FileId(fileName string) string {
+	return strings.TrimSuffix(fileName, path.Ext(fileName))
+}
@@ -11,12 +11,17 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/ProtocolONE/go-core/v2/pkg/tracing"
 	"github.com/google/wire"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"github.com/paysuper/paysuper-management-api/internal/validators"
 	"github.com/paysuper/paysuper-management-api/pkg/micro"
 	"gopkg.in/go-playground/validator.v9"
 	"os"
+	"time"
 )
 
 type TestSet struct {
@@ -29,6 +34,18 @@ type TestSet struct {
 
 // ProviderTestSet
 func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv common.Services, configurator config.Configurator, globalConfig *common.Config, validate *validator.Validate) (*TestSet, func(), error) {
+	perms, err := apikey.LoadPermissions(
+		initial.WorkDir+common.CasbinPolicyFile,
+		common.AuthUserGroupPath,
+		common.AuthUserGroupPath+common.ApiKeysRoutePath,
+	)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	redactor, err := redact.New(&redact.Config{})
+	if err != nil {
+		return nil, func() {}, err
+	}
 	t := &TestSet{
 		AwareSet:     awareSet,
 		Configurator: configurator,
@@ -37,6 +54,11 @@ func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv com
 			AwareSet: awareSet,
 			Validate: validate,
 			Services: srv,
+			ApiKeys:  apikey.NewRegistry(apikey.NewMemoryStore(), perms, time.Minute),
+			Audit:    audit.NewMemoryStore(),
+
+			ReportFiles: reportfile.NewMemoryStore(),
+			Redactor:    redactor,
 		},
 		Initial: initial,
 	}
@@ -13,12 +13,17 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/metric"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/ProtocolONE/go-core/v2/pkg/tracing"
+	"github.com/paysuper/paysuper-management-api/internal/apikey"
+	"github.com/paysuper/paysuper-management-api/internal/audit"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher"
 	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-management-api/internal/redact"
+	"github.com/paysuper/paysuper-management-api/internal/reportfile"
 	"github.com/paysuper/paysuper-management-api/internal/validators"
 	"github.com/paysuper/paysuper-management-api/pkg/micro"
 	"gopkg.in/go-playground/validator.v9"
 	"os"
+	"time"
 )
 
 // Injectors from inject.go:
@@ -162,11 +167,7 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		return nil, nil, err
 	}
 	jwtVerifier := dispatcher.ProviderJwtVerifier(commonConfig)
-	appSet := dispatcher.AppSet{
-		Handlers:    handlers,
-		Services:    srv,
-		JwtVerifier: jwtVerifier,
-	}
+	authCache := dispatcher.ProviderAuthCache(commonConfig)
 	dispatcherConfig, cleanup7, err := dispatcher.ProviderCfg(configurator)
 	if err != nil {
 		cleanup6()
@@ -177,7 +178,7 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
-	microConfig, cleanup8, err := micro.CfgTest()
+	client, cleanup8, err := dispatcher.ProviderRedis(dispatcherConfig)
 	if err != nil {
 		cleanup7()
 		cleanup6()
@@ -188,7 +189,7 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
-	microMicro, cleanup9, err := micro.ProviderTest(ctx, awareSet, microConfig)
+	registry, cleanup9, err := dispatcher.ProviderApiKeysTest(dispatcherConfig)
 	if err != nil {
 		cleanup8()
 		cleanup7()
@@ -200,7 +201,15 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
-	dispatcherDispatcher, cleanup10, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, commonConfig, microMicro)
+	appSet := dispatcher.AppSet{
+		Handlers:    handlers,
+		Services:    srv,
+		JwtVerifier: jwtVerifier,
+		AuthCache:   authCache,
+		Redis:       client,
+		ApiKeys:     registry,
+	}
+	microConfig, cleanup10, err := micro.CfgTest()
 	if err != nil {
 		cleanup9()
 		cleanup8()
@@ -213,7 +222,38 @@ func BuildDispatcher(ctx context.Context, settings config.Settings, srv common.S
 		cleanup()
 		return nil, nil, err
 	}
+	microMicro, cleanup11, err := micro.ProviderTest(ctx, awareSet, microConfig)
+	if err != nil {
+		cleanup10()
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	dispatcherDispatcher, cleanup12, err := dispatcher.ProviderDispatcher(ctx, awareSet, appSet, dispatcherConfig, commonConfig, microMicro)
+	if err != nil {
+		cleanup11()
+		cleanup10()
+		cleanup9()
+		cleanup8()
+		cleanup7()
+		cleanup6()
+		cleanup5()
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	return dispatcherDispatcher, func() {
+		cleanup12()
+		cleanup11()
 		cleanup10()
 		cleanup9()
 		cleanup8()
@@ -239,6 +279,18 @@ type TestSet struct {
 
 // ProviderTestSet
 func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv common.Services, configurator config.Configurator, globalConfig *common.Config, validate *validator.Validate) (*TestSet, func(), error) {
+	perms, err := apikey.LoadPermissions(
+		initial.WorkDir+common.CasbinPolicyFile,
+		common.AuthUserGroupPath,
+		common.AuthUserGroupPath+common.ApiKeysRoutePath,
+	)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	redactor, err := redact.New(&redact.Config{})
+	if err != nil {
+		return nil, func() {}, err
+	}
 	t := &TestSet{
 		AwareSet:     awareSet,
 		Configurator: configurator,
@@ -247,6 +299,11 @@ func ProviderTestSet(initial config.Initial, awareSet provider.AwareSet, srv com
 			AwareSet: awareSet,
 			Validate: validate,
 			Services: srv,
+			ApiKeys:  apikey.NewRegistry(apikey.NewMemoryStore(), perms, time.Minute),
+			Audit:    audit.NewMemoryStore(),
+
+			ReportFiles: reportfile.NewMemoryStore(),
+			Redactor:    redactor,
 		},
 		Initial: initial,
 	}
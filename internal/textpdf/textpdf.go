@@ -0,0 +1,141 @@
+package textpdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 40
+	fontSize     = 9
+	titleSize    = 14
+	leading      = 12
+	lineWidthMax = 105
+)
+
+// linesPerPage is the number of the text lines fitted between the margins, the title takes two lines of the first page
+var linesPerPage = (pageHeight - 2*margin) / leading
+
+// Render returns the A4 PDF document with the title and the text lines in the Helvetica font, the long lines are
+// wrapped and the characters out of the printable ASCII range are replaced with the question mark
+func Render(title string, lines []string) []byte {
+	var wrapped []string
+
+	for _, line := range lines {
+		wrapped = append(wrapped, wrap(sanitize(line))...)
+	}
+
+	var pages [][]string
+	size := linesPerPage - 2
+
+	for len(wrapped) > size {
+		pages = append(pages, wrapped[:size])
+		wrapped = wrapped[size:]
+		size = linesPerPage
+	}
+
+	pages = append(pages, wrapped)
+
+	// the objects are the catalog, the pages tree, the font and the page with its content stream for each page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, len(pages))
+
+	for i, page := range pages {
+		pageId := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageId)
+		content := pageContent(title, page, i == 0)
+
+		objects = append(
+			objects,
+			fmt.Sprintf(
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, pageId+1,
+			),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+func pageContent(title string, lines []string, first bool) string {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "BT\n%d TL\n%d %d Td\n", leading, margin, pageHeight-margin)
+
+	if first {
+		fmt.Fprintf(buf, "/F1 %d Tf\n(%s) Tj\nT* T*\n", titleSize, escape(sanitize(title)))
+	}
+
+	fmt.Fprintf(buf, "/F1 %d Tf\n", fontSize)
+
+	for _, line := range lines {
+		fmt.Fprintf(buf, "(%s) Tj T*\n", escape(line))
+	}
+
+	buf.WriteString("ET")
+
+	return buf.String()
+}
+
+// wrap splits the line by the spaces into the lines not longer than lineWidthMax, the long words are cut
+func wrap(line string) []string {
+	var lines []string
+
+	for len(line) > lineWidthMax {
+		i := strings.LastIndex(line[:lineWidthMax], " ")
+
+		if i <= 0 {
+			i = lineWidthMax
+		}
+
+		lines = append(lines, line[:i])
+		line = strings.TrimLeft(line[i:], " ")
+	}
+
+	return append(lines, line)
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+
+		if r < ' ' || r > '~' {
+			return '?'
+		}
+
+		return r
+	}, s)
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
@@ -0,0 +1,84 @@
+package textpdf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_Render(t *testing.T) {
+	doc := Render("Webhook testing report", []string{"Passed: 3", `path\with (parens)`, "Привет"})
+
+	assert.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+	assert.Contains(t, string(doc), "(Webhook testing report) Tj")
+	assert.Contains(t, string(doc), "(Passed: 3) Tj")
+	assert.Contains(t, string(doc), `(path\\with \(parens\)) Tj`)
+	assert.Contains(t, string(doc), "(??????) Tj")
+	assert.Contains(t, string(doc), "/Count 1")
+	assertXref(t, doc)
+}
+
+func Test_Render_Pages(t *testing.T) {
+	lines := make([]string, linesPerPage*2)
+
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+
+	doc := Render("Report", lines)
+
+	// the title takes two lines of the first page
+	assert.Contains(t, string(doc), "/Count 3")
+	assert.Contains(t, string(doc), "/Kids [4 0 R 6 0 R 8 0 R]")
+	assertXref(t, doc)
+}
+
+func Test_wrap(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{name: "short", line: "short line", expected: []string{"short line"}},
+		{name: "empty", line: "", expected: []string{""}},
+		{
+			name:     "by the space",
+			line:     strings.Repeat("a", 100) + " " + strings.Repeat("b", 10),
+			expected: []string{strings.Repeat("a", 100), strings.Repeat("b", 10)},
+		},
+		{
+			name:     "long word",
+			line:     strings.Repeat("a", lineWidthMax+5),
+			expected: []string{strings.Repeat("a", lineWidthMax), "aaaaa"},
+		},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, wrap(c.line), c.name)
+	}
+}
+
+// assertXref checks the offsets of the cross-reference table point to the objects
+func assertXref(t *testing.T, doc []byte) {
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	require.NotNil(t, m)
+
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(doc[xref:], []byte("xref\n")))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	require.NotEmpty(t, offsets)
+
+	for i, offset := range offsets {
+		n, err := strconv.Atoi(string(offset[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(doc[n:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))))
+	}
+}
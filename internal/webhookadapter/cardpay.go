@@ -0,0 +1,214 @@
+package webhookadapter
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"net/http"
+	"strings"
+)
+
+const (
+	CardPayName = "cardpay"
+
+	cardPaySubscriptionStatusActive = "ACTIVE"
+)
+
+// CardPayRecurringCallback is the notification of the payment of the saved card or the subscription
+type CardPayRecurringCallback struct {
+	CallbackTime     string                        `json:"callback_time" validate:"required"`
+	PaymentMethod    string                        `json:"payment_method" validate:"required"`
+	MerchantOrder    *CardPayRecurringOrder        `json:"merchant_order" validate:"required"`
+	RecurringData    *CardPayRecurringData         `json:"recurring_data" validate:"required"`
+	SubscriptionData *CardPayRecurringSubscription `json:"subscription_data" validate:"omitempty"`
+	CardAccount      *CardPayRecurringCardAccount  `json:"card_account" validate:"omitempty"`
+	Customer         *CardPayRecurringCustomer     `json:"customer" validate:"required"`
+}
+
+// CardPayRecurringOrder
+type CardPayRecurringOrder struct {
+	Id          string `json:"id" validate:"required,hexadecimal,len=24"`
+	Description string `json:"description"`
+}
+
+// CardPayRecurringData is the state of the recurring payment, the filing is the saved card
+type CardPayRecurringData struct {
+	Id       string                  `json:"id" validate:"required"`
+	Status   string                  `json:"status" validate:"required"`
+	Amount   float64                 `json:"amount" validate:"required,gt=0"`
+	Currency string                  `json:"currency" validate:"required,len=3"`
+	Created  string                  `json:"created"`
+	Filing   *CardPayRecurringFiling `json:"filing" validate:"omitempty"`
+}
+
+// CardPayRecurringFiling
+type CardPayRecurringFiling struct {
+	Id string `json:"id" validate:"required"`
+}
+
+// CardPayRecurringSubscription
+type CardPayRecurringSubscription struct {
+	Id     string `json:"id" validate:"required"`
+	Status string `json:"status" validate:"required"`
+}
+
+// CardPayRecurringCardAccount
+type CardPayRecurringCardAccount struct {
+	Holder    string `json:"holder"`
+	MaskedPan string `json:"masked_pan"`
+	// Expiration is the month and the year of the card expiration in the MM/YYYY format
+	Expiration string `json:"expiration"`
+}
+
+// CardPayRecurringCustomer
+type CardPayRecurringCustomer struct {
+	Id    string `json:"id" validate:"required"`
+	Email string `json:"email"`
+}
+
+type cardPay struct{}
+
+// NewCardPay creates the adapter of the CardPay callbacks, the events are named by the upper or the lower case
+// callback types of the CardPay settings
+func NewCardPay() Adapter {
+	return &cardPay{}
+}
+
+// Name
+func (a *cardPay) Name() string {
+	return CardPayName
+}
+
+// Handler
+func (a *cardPay) Handler() string {
+	return billingpb.PaymentSystemHandlerCardPay
+}
+
+// SignatureHeader
+func (a *cardPay) SignatureHeader() string {
+	return common.CardPayPaymentResponseHeaderSignature
+}
+
+// Decode
+func (a *cardPay) Decode(event string, body []byte) (*Callback, error) {
+	switch event {
+	case "payment", "PAYMENT":
+		msg := &billingpb.CardPayPaymentCallback{}
+
+		if err := json.Unmarshal(body, msg); err != nil {
+			return nil, ErrInvalidBody
+		}
+
+		callback := &Callback{Kind: KindPayment, Message: msg}
+
+		if msg.MerchantOrder != nil {
+			callback.OrderId = msg.MerchantOrder.Id
+		}
+
+		return callback, nil
+	case "RECURRING":
+		msg := &CardPayRecurringCallback{}
+
+		if err := json.Unmarshal(body, msg); err != nil {
+			return nil, ErrInvalidBody
+		}
+
+		callback := &Callback{Kind: KindRecurring, Message: msg}
+
+		if msg.MerchantOrder == nil || msg.RecurringData == nil || msg.Customer == nil {
+			// the validation of the message rejects the callback
+			return callback, nil
+		}
+
+		callback.OrderId = msg.MerchantOrder.Id
+		callback.Recurring = &Recurring{
+			Completed:  msg.RecurringData.Status == billingpb.CardPayPaymentResponseStatusCompleted,
+			CustomerId: msg.Customer.Id,
+		}
+
+		if msg.RecurringData.Filing != nil {
+			callback.Recurring.FilingId = msg.RecurringData.Filing.Id
+		}
+
+		if msg.CardAccount != nil {
+			callback.Recurring.MaskedPan = msg.CardAccount.MaskedPan
+			callback.Recurring.CardHolder = msg.CardAccount.Holder
+
+			if expire := strings.Split(msg.CardAccount.Expiration, "/"); len(expire) == 2 {
+				callback.Recurring.ExpireMonth, callback.Recurring.ExpireYear = expire[0], expire[1]
+			}
+		}
+
+		if msg.SubscriptionData != nil {
+			callback.Recurring.SubscriptionId = msg.SubscriptionData.Id
+			callback.Recurring.SubscriptionActive = msg.SubscriptionData.Status == cardPaySubscriptionStatusActive
+		}
+
+		return callback, nil
+	case "refund", "REFUND":
+		msg := &billingpb.CardPayRefundCallback{}
+
+		if err := json.Unmarshal(body, msg); err != nil {
+			return nil, ErrInvalidBody
+		}
+
+		callback := &Callback{Kind: KindRefund, Message: msg}
+
+		if msg.MerchantOrder != nil {
+			callback.OrderId = msg.MerchantOrder.Id
+		}
+
+		if msg.RefundData != nil {
+			callback.RefundId = msg.RefundData.Id
+		}
+
+		return callback, nil
+	}
+
+	return nil, ErrUnknownEvent
+}
+
+// Respond
+func (a *cardPay) Respond(ctx echo.Context, callback *Callback, result *Result, err error) error {
+	if callback.Kind == KindRefund {
+		if err != nil {
+			return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+		}
+
+		if result.Status != billingpb.ResponseStatusOk {
+			return echo.NewHTTPError(int(result.Status), result.Error)
+		}
+
+		if result.Error != "" {
+			return ctx.JSON(http.StatusOK, map[string]string{"message": result.Error})
+		}
+
+		return ctx.NoContent(http.StatusOK)
+	}
+
+	if err != nil && callback.Kind == KindRecurring {
+		// CardPay retries the recurring callback to update the saved card and the subscription
+		return common.SrvCallError(err, http.StatusInternalServerError, common.ErrorUnknown)
+	}
+
+	if err != nil {
+		return common.SrvCallError(err, http.StatusBadRequest, common.ErrorUnknown)
+	}
+
+	httpStatus := http.StatusOK
+	message := map[string]string{"message": result.Error}
+
+	switch result.Status {
+	case billingpb.StatusErrorValidation:
+		httpStatus = http.StatusBadRequest
+	case billingpb.StatusErrorSystem:
+		httpStatus = http.StatusInternalServerError
+	case billingpb.StatusTemporary:
+		httpStatus = http.StatusGone
+	default:
+		message["message"] = "Payment successfully complete"
+	}
+
+	return ctx.JSON(httpStatus, message)
+}
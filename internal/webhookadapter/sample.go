@@ -0,0 +1,95 @@
+package webhookadapter
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/internal/dispatcher/common"
+	"github.com/paysuper/paysuper-proto/go/billingpb"
+	"net/http"
+)
+
+const (
+	SampleName            = "sample"
+	SampleSignatureHeader = "X-Signature"
+)
+
+// SampleCallback is the callback of the sample acquirer, the same body is sent for the payments and the refunds
+type SampleCallback struct {
+	// Id of the callback, the retries of the callback have the same id
+	Id       string `json:"id" validate:"required"`
+	OrderId  string `json:"order_id" validate:"required"`
+	RefundId string `json:"refund_id"`
+	Status   string `json:"status" validate:"required,oneof=completed declined"`
+	Amount   int64  `json:"amount" validate:"required,gt=0"`
+	Currency string `json:"currency" validate:"required,len=3"`
+}
+
+type sample struct{}
+
+// NewSample creates the adapter of the sample acquirer signing the callbacks with the hmac-sha256
+// of the body in the X-Signature header, it's the template of the new acquirers
+func NewSample() Adapter {
+	return &sample{}
+}
+
+// Name
+func (a *sample) Name() string {
+	return SampleName
+}
+
+// Handler
+func (a *sample) Handler() string {
+	return SampleName
+}
+
+// SignatureHeader
+func (a *sample) SignatureHeader() string {
+	return SampleSignatureHeader
+}
+
+// Decode
+func (a *sample) Decode(event string, body []byte) (*Callback, error) {
+	if event != KindPayment && event != KindRefund {
+		return nil, ErrUnknownEvent
+	}
+
+	msg := &SampleCallback{}
+
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, ErrInvalidBody
+	}
+
+	if event == KindRefund && msg.RefundId == "" {
+		return nil, ErrInvalidBody
+	}
+
+	return &Callback{Kind: event, OrderId: msg.OrderId, RefundId: msg.RefundId, Message: msg}, nil
+}
+
+// Respond
+func (a *sample) Respond(ctx echo.Context, callback *Callback, result *Result, err error) error {
+	// the sample acquirer retries the callbacks on 5xx responses only
+	if err != nil {
+		return common.SrvCallError(err, http.StatusServiceUnavailable, common.ErrorUnknown)
+	}
+
+	status := http.StatusOK
+
+	switch {
+	case callback.Kind == KindRefund && result.Status >= http.StatusInternalServerError:
+		status = http.StatusServiceUnavailable
+	case callback.Kind == KindRefund && result.Status != billingpb.ResponseStatusOk:
+		status = http.StatusUnprocessableEntity
+	case callback.Kind == KindRefund:
+	case result.Status == billingpb.StatusErrorSystem, result.Status == billingpb.StatusTemporary:
+		status = http.StatusServiceUnavailable
+	case result.Status == billingpb.StatusErrorValidation:
+		status = http.StatusUnprocessableEntity
+	}
+
+	if status != http.StatusOK {
+		return echo.NewHTTPError(status, result.Error)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{"status": "accepted"})
+}
@@ -0,0 +1,108 @@
+package webhookadapter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	KindPayment = "payment"
+	KindRefund  = "refund"
+	// KindRecurring is the payment of the saved card, the recurring state is updated after the billing processes it
+	KindRecurring = "recurring"
+)
+
+var (
+	// ErrUnknownEvent is returned by the adapter for the events of the route it doesn't process
+	ErrUnknownEvent = errors.New("unknown callback event")
+	// ErrInvalidBody is returned by the adapter if the callback can't be decoded
+	ErrInvalidBody = errors.New("invalid callback body")
+)
+
+// Adapter converts the callbacks of the payment system to the billing requests and the billing results
+// to the responses expected by the payment system
+type Adapter interface {
+	// Name is the provider segment of the callback route
+	Name() string
+	// Handler is the billing handler of the payment system
+	Handler() string
+	// SignatureHeader is the request header with the callback signature
+	SignatureHeader() string
+	// Decode parses the callback of the event segment of the route
+	Decode(event string, body []byte) (*Callback, error)
+	// Respond writes the response to the callback, err is the billing call error
+	Respond(ctx echo.Context, callback *Callback, result *Result, err error) error
+}
+
+// Callback decoded by the adapter
+type Callback struct {
+	// Kind is the billing method processing the callback: payment, refund or recurring
+	Kind     string
+	OrderId  string
+	RefundId string
+	// Message is the decoded body, it's validated before the billing call
+	Message interface{}
+	// Recurring is set for the recurring payments only
+	Recurring *Recurring
+}
+
+// Recurring is the state of the saved card and the subscription reported by the recurring payment callback
+type Recurring struct {
+	// FilingId is the identifier of the saved card in the payment system
+	FilingId string
+	// Completed is true if the recurring payment is successful
+	Completed  bool
+	CustomerId string
+	MaskedPan  string
+	CardHolder string
+	// ExpireMonth and ExpireYear of the saved card, they are empty if the payment system doesn't send them
+	ExpireMonth string
+	ExpireYear  string
+	// SubscriptionId is empty for the recurring payments without the subscription
+	SubscriptionId string
+	// SubscriptionActive is false if the subscription is cancelled or finished
+	SubscriptionActive bool
+}
+
+// Result of the billing processing of the callback
+type Result struct {
+	Status int32
+	Error  string
+}
+
+// Registry of the adapters by the provider name
+type Registry struct {
+	adapters map[string]Adapter
+}
+
+// NewRegistry
+func NewRegistry() *Registry {
+	return &Registry{adapters: make(map[string]Adapter)}
+}
+
+// Register
+func (r *Registry) Register(adapter Adapter) error {
+	if _, ok := r.adapters[adapter.Name()]; ok {
+		return fmt.Errorf("webhook adapter %q is already registered", adapter.Name())
+	}
+	r.adapters[adapter.Name()] = adapter
+	return nil
+}
+
+// Get
+func (r *Registry) Get(name string) (Adapter, bool) {
+	adapter, ok := r.adapters[name]
+	return adapter, ok
+}
+
+// New creates the builtin adapter of the provider
+func New(name string) (Adapter, error) {
+	switch name {
+	case CardPayName:
+		return NewCardPay(), nil
+	case SampleName:
+		return NewSample(), nil
+	}
+	return nil, fmt.Errorf("unknown webhook adapter %q", name)
+}
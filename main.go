@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/paysuper/paysuper-management-api/cmd/casbin"
 	"github.com/paysuper/paysuper-management-api/cmd/http"
+	"github.com/paysuper/paysuper-management-api/cmd/openapi"
 	"github.com/paysuper/paysuper-management-api/cmd/root"
 )
 
@@ -15,5 +16,5 @@ func main() {
 	args := []string{
 		"http", "-c", "configs/local.yaml", "-d",
 	}
-	root.ExecuteDefault(args, http.Cmd, casbin.Cmd)
+	root.ExecuteDefault(args, http.Cmd, casbin.Cmd, openapi.Cmd)
 }
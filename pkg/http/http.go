@@ -6,6 +6,7 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
 	"github.com/ProtocolONE/go-core/v2/pkg/provider"
 	"github.com/labstack/echo/v4"
+	"github.com/paysuper/paysuper-management-api/pkg/metrics"
 	"net/http"
 )
 
@@ -29,6 +30,11 @@ func (h *HTTP) ListenAndServe() (err error) {
 		return err
 	}
 
+	// the metrics are never served by the public listener, the empty bind address disables them
+	if h.cfg.MetricsBind != "" {
+		h.serveMetrics()
+	}
+
 	h.L().Info("start listen and serve http at %v", logger.Args(h.cfg.Bind))
 
 	go func() {
@@ -51,11 +57,34 @@ func (h *HTTP) ListenAndServe() (err error) {
 	return nil
 }
 
+// serveMetrics exposes the metrics on the separate address to keep them out of the public ingress
+func (h *HTTP) serveMetrics() {
+	mux := http.NewServeMux()
+	mux.Handle(h.cfg.MetricsPath, metrics.Handler())
+	server := &http.Server{Addr: h.cfg.MetricsBind, Handler: mux}
+
+	go func() {
+		<-h.ctx.Done()
+		if e := server.Shutdown(context.Background()); e != nil {
+			h.L().Error("metrics server graceful shutdown error, %v", logger.Args(e))
+		}
+	}()
+
+	go func() {
+		h.L().Info("start listen and serve metrics at %v", logger.Args(h.cfg.MetricsBind))
+		if e := server.ListenAndServe(); e != nil && e != http.ErrServerClosed {
+			h.L().Error("metrics server failed, %v", logger.Args(e))
+		}
+	}()
+}
+
 // Config
 type Config struct {
-	Debug   bool   `fallback:"shared.debug"`
-	Bind    string `required:"true"`
-	invoker *invoker.Invoker
+	Debug       bool   `fallback:"shared.debug"`
+	Bind        string `required:"true"`
+	MetricsBind string `default:":8082"`
+	MetricsPath string `default:"/metrics"`
+	invoker     *invoker.Invoker
 }
 
 // OnReload
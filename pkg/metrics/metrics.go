@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"github.com/micro/go-micro/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	namespace = "management_api"
+
+	StatusError = "error"
+	StatusOk    = "ok"
+
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+var (
+	// Registry keeps the collectors of the process, it's exposed by Handler
+	Registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of the handled http requests.",
+	}, []string{"group", "method", "route", "status"})
+
+	httpErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "errors_total",
+		Help:      "Number of the http requests completed with 4xx or 5xx status.",
+	}, []string{"group", "method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the http requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"group", "method", "route", "status"})
+
+	grpcCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "calls_total",
+		Help:      "Number of the downstream service calls by the response status.",
+	}, []string{"service", "method", "status"})
+
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "call_duration_seconds",
+		Help:      "Latency of the downstream service calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "status"})
+
+	callbacksRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "callbacks",
+		Name:      "rejected_total",
+		Help:      "Number of the payment system callbacks rejected before the processing.",
+	}, []string{"provider", "reason"})
+
+	authCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth_cache",
+		Name:      "lookups_total",
+		Help:      "Number of the authentication cache lookups by the result.",
+	}, []string{"cache", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		httpRequests,
+		httpErrors,
+		httpDuration,
+		grpcCalls,
+		grpcDuration,
+		callbacksRejected,
+		authCacheLookups,
+	)
+}
+
+// Handler
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records the http request, route is the route template to keep the labels cardinality low
+func ObserveRequest(group, method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(group, method, route, code).Inc()
+	httpDuration.WithLabelValues(group, method, route, code).Observe(duration.Seconds())
+
+	if status >= http.StatusBadRequest {
+		httpErrors.WithLabelValues(group, method, route, code).Inc()
+	}
+}
+
+// ObserveCall records the downstream service call
+func ObserveCall(service, method, status string, duration time.Duration) {
+	grpcCalls.WithLabelValues(service, method, status).Inc()
+	grpcDuration.WithLabelValues(service, method, status).Observe(duration.Seconds())
+}
+
+// ObserveRejectedCallback records the payment system callback rejected by the reason
+func ObserveRejectedCallback(provider, reason string) {
+	callbacksRejected.WithLabelValues(provider, reason).Inc()
+}
+
+// ObserveAuthCacheLookup records the hit or the miss of the authentication cache
+func ObserveAuthCacheLookup(cache string, hit bool) {
+	result := CacheMiss
+	if hit {
+		result = CacheHit
+	}
+	authCacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+type statusResponse interface {
+	GetStatus() int32
+}
+
+type clientWrapper struct {
+	client.Client
+}
+
+// Call labels the call with the Status field of the response because the services report
+// the most of the business errors with the successful transport response
+func (w *clientWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	start := time.Now()
+	err := w.Client.Call(ctx, req, rsp, opts...)
+	status := StatusOk
+
+	if err != nil {
+		status = StatusError
+	} else if r, ok := rsp.(statusResponse); ok {
+		status = strconv.Itoa(int(r.GetStatus()))
+	}
+
+	ObserveCall(req.Service(), req.Endpoint(), status, time.Since(start))
+	return err
+}
+
+// NewClientWrapper
+func NewClientWrapper() client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &clientWrapper{Client: c}
+	}
+}
@@ -9,12 +9,15 @@ import (
 	"github.com/micro/go-micro"
 	"github.com/micro/go-micro/client"
 	"github.com/micro/go-plugins/client/selector/static"
+	"github.com/opentracing/opentracing-go"
+	"github.com/paysuper/paysuper-management-api/pkg/metrics"
 )
 
 // Micro
 type Micro struct {
-	ctx context.Context
-	cfg Config
+	ctx    context.Context
+	cfg    Config
+	tracer opentracing.Tracer
 	provider.LMT
 }
 
@@ -23,6 +26,7 @@ func (m *Micro) Client(serviceVersion, fallback string) client.Client {
 	options := []micro.Option{
 		micro.Name(m.cfg.Name),
 		micro.Version(m.cfg.Version),
+		micro.WrapClient(metrics.NewClientWrapper(), NewTracingWrapper(m.tracer)),
 	}
 
 	if len(serviceVersion) > 0 {
@@ -47,6 +51,7 @@ type Config struct {
 	Version                string `default:"latest"`
 	BillingVersion         string `default:"latest"`
 	BillingFallbackVersion string `default:"latest"`
+	WebhookNotifierTopic   string `default:"notify-payment"`
 	Selector               string
 	Bind                   string
 	invoker                *invoker.Invoker
@@ -67,8 +72,9 @@ func New(ctx context.Context, set provider.AwareSet, cfg *Config) *Micro {
 	set.Logger = set.Logger.WithFields(logger.Fields{"service": Prefix, "service_name": cfg.Name})
 
 	return &Micro{
-		ctx: ctx,
-		cfg: *cfg,
-		LMT: &set,
+		ctx:    ctx,
+		cfg:    *cfg,
+		tracer: set.Tracer,
+		LMT:    &set,
 	}
 }
@@ -0,0 +1,59 @@
+package micro
+
+import (
+	"context"
+	"github.com/micro/go-micro/client"
+	"github.com/micro/go-micro/metadata"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+type tracingWrapper struct {
+	client.Client
+	tracer opentracing.Tracer
+}
+
+// Call starts the client span as a child of the span from the context and passes it to the service in the call metadata
+func (w *tracingWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	parent := opentracing.SpanFromContext(ctx)
+
+	if parent == nil {
+		return w.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	span := w.tracer.StartSpan(
+		req.Service()+"."+req.Endpoint(),
+		opentracing.ChildOf(parent.Context()),
+		ext.SpanKindRPCClient,
+	)
+	defer span.Finish()
+
+	ext.PeerService.Set(span, req.Service())
+
+	md := metadata.Metadata{}
+	if in, ok := metadata.FromContext(ctx); ok {
+		for k, v := range in {
+			md[k] = v
+		}
+	}
+
+	if err := w.tracer.Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier(md)); err != nil {
+		span.LogKV("event", "inject failed", "error", err.Error())
+	}
+
+	err := w.Client.Call(metadata.NewContext(opentracing.ContextWithSpan(ctx, span), md), req, rsp, opts...)
+
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogKV("error", err.Error())
+	}
+
+	return err
+}
+
+// NewTracingWrapper
+func NewTracingWrapper(tracer opentracing.Tracer) client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &tracingWrapper{Client: c, tracer: tracer}
+	}
+}
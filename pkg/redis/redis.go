@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"github.com/go-redis/redis"
+	"time"
+)
+
+var (
+	ErrNil = redis.Nil
+)
+
+// Config
+type Config struct {
+	Addr     string
+	Password string
+	Db       int
+	// PoolSize is the maximum number of the open connections, the commands wait for the free connection up to the
+	// timeout when the pool is exhausted
+	PoolSize int           `default:"10"`
+	Timeout  time.Duration `default:"1s"`
+}
+
+// Client covers the key/value primitives used by the dispatcher on top of the go-redis client
+type Client struct {
+	client *redis.Client
+}
+
+// New
+func New(cfg *Config) *Client {
+	return &Client{
+		client: redis.NewClient(&redis.Options{
+			Addr:         cfg.Addr,
+			Password:     cfg.Password,
+			DB:           cfg.Db,
+			PoolSize:     cfg.PoolSize,
+			PoolTimeout:  cfg.Timeout,
+			DialTimeout:  cfg.Timeout,
+			ReadTimeout:  cfg.Timeout,
+			WriteTimeout: cfg.Timeout,
+		}),
+	}
+}
+
+// Ping
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.WithContext(ctx).Ping().Err()
+}
+
+// Get returns ErrNil when the key does not exist
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	return c.client.WithContext(ctx).Get(key).Bytes()
+}
+
+// Set
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.WithContext(ctx).Set(key, value, ttl).Err()
+}
+
+// SetNX stores the value only if the key does not exist yet and reports whether it was stored
+func (c *Client) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return c.client.WithContext(ctx).SetNX(key, value, ttl).Result()
+}
+
+// Del
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	return c.client.WithContext(ctx).Del(keys...).Err()
+}
+
+// SAdd
+func (c *Client) SAdd(ctx context.Context, key string, members ...string) error {
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	return c.client.WithContext(ctx).SAdd(key, values...).Err()
+}
+
+// SMembers
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.client.WithContext(ctx).SMembers(key).Result()
+}
+
+// HSet
+func (c *Client) HSet(ctx context.Context, key, field string, value []byte) error {
+	return c.client.WithContext(ctx).HSet(key, field, value).Err()
+}
+
+// HSetNX stores the field only if it does not exist yet and reports whether it was stored
+func (c *Client) HSetNX(ctx context.Context, key, field string, value []byte) (bool, error) {
+	return c.client.WithContext(ctx).HSetNX(key, field, value).Result()
+}
+
+// HGetAll returns the empty map when the key does not exist
+func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.client.WithContext(ctx).HGetAll(key).Result()
+}
+
+// Eval returns the reply of the script, the bulk strings of the reply are decoded as strings
+func (c *Client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.client.WithContext(ctx).Eval(script, keys, args...).Result()
+}
+
+// Close
+func (c *Client) Close() error {
+	return c.client.Close()
+}